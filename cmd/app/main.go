@@ -8,6 +8,8 @@ import (
 	"e-commerce/internal/repository"
 	"e-commerce/internal/rest"
 	"e-commerce/internal/service"
+	"e-commerce/internal/storage"
+	"e-commerce/internal/worker"
 	"log"
 	"net/http"
 	"os"
@@ -34,12 +36,60 @@ func main() {
 	}
 	defer rdb.Close()
 
-	productRepo := repository.NewProductRepo(pool)
+	mediaStore, err := storage.NewLocalBlobStore(cfg.MediaDir, cfg.MediaURL)
+	if err != nil {
+		log.Fatal("media storage:", err)
+	}
+	// digital files are never served directly, so they have no public URL
+	fileStore, err := storage.NewLocalBlobStore(cfg.FilesDir, "")
+	if err != nil {
+		log.Fatal("file storage:", err)
+	}
+
+	productRepo := repository.NewProductRepo(pool, cfg.SearchLanguage)
 	userRepo := repository.NewUserRepo(pool)
+	catalogRepo := repository.NewCatalogRepo(pool, cfg.SearchLanguage)
+	categoryRepo := repository.NewCategoryRepo(pool)
+	variantRepo := repository.NewVariantRepo(pool)
+	inventoryRepo := repository.NewInventoryRepo(pool)
+	exchangeRateRepo := repository.NewExchangeRateRepo(pool)
+	imageRepo := repository.NewImageRepo(pool)
+	revisionRepo := repository.NewRevisionRepo(pool)
+	importRepo := repository.NewImportRepo(pool, cfg.SearchLanguage)
+	priceRepo := repository.NewPriceRepo(pool)
+	reviewRepo := repository.NewReviewRepo(pool)
+	recommendationRepo := repository.NewRecommendationRepo(pool)
+	bundleRepo := repository.NewBundleRepo(pool)
+	fileRepo := repository.NewFileRepo(pool)
+	downloadRepo := repository.NewDownloadRepo(pool)
 	blacklist := repository.NewTokenBlacklist(rdb)
 	userService := service.NewUserService(userRepo, cfg.JWTSecret)
-	productService := service.NewProductService(productRepo)
-	router := rest.SetupRouter(userRepo, userService, productService, blacklist, cfg)
+	productService := service.NewProductService(productRepo, mediaStore, fileStore, cfg.TrashRetention)
+	catalogService := service.NewCatalogService(catalogRepo)
+	categoryService := service.NewCategoryService(categoryRepo)
+	variantService := service.NewVariantService(variantRepo)
+	inventoryService := service.NewInventoryService(inventoryRepo, cfg.ReservationTTL)
+	exchangeRateService := service.NewExchangeRateService(exchangeRateRepo)
+	imageService := service.NewImageService(imageRepo, mediaStore, cfg.ImageMaxBytes)
+	revisionService := service.NewRevisionService(revisionRepo)
+	importService := service.NewImportService(importRepo, cfg.ImportMaxBytes)
+	priceService := service.NewPriceService(priceRepo)
+	reviewService := service.NewReviewService(reviewRepo)
+	recommendationService := service.NewRecommendationService(recommendationRepo)
+	bundleService := service.NewBundleService(bundleRepo)
+	fileService := service.NewFileService(fileRepo, fileStore, cfg.FileMaxBytes)
+	downloadService := service.NewDownloadService(downloadRepo, fileStore, cfg.JWTSecret, cfg.DownloadLinkTTL, cfg.DownloadLimit)
+	router := rest.SetupRouter(userRepo, userService, productService, catalogService, categoryService, variantService, inventoryService, exchangeRateService, imageService, revisionService, importService, priceService, reviewService, recommendationService, bundleService, fileService, downloadService, blacklist, cfg)
+
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	go worker.Run(workerCtx, "reservation-sweeper", cfg.ReservationSweepInterval, inventoryService.ReleaseExpiredReservations)
+	go worker.Run(workerCtx, "trash-purger", cfg.TrashPurgeInterval, productService.PurgeTrash)
+	go worker.Run(workerCtx, "product-importer", cfg.ImportPollInterval, importService.RunPending)
+	go worker.Run(workerCtx, "product-scheduler", cfg.PublishScheduleInterval, productService.RunSchedule)
+	go worker.Run(workerCtx, "price-scheduler", cfg.PriceScheduleInterval, priceService.RunSchedule)
+	go worker.Run(workerCtx, "product-affinity", cfg.AffinityInterval, recommendationService.RebuildAffinity)
+	go worker.Run(workerCtx, "download-link-sweeper", cfg.DownloadSweepInterval, downloadService.DeleteExpiredLinks)
 
 	srv := &http.Server{
 		Addr:         ":" + cfg.Port,
@@ -0,0 +1,80 @@
+// Command rates loads exchange rates into the database from a local file.
+//
+//	go run ./cmd/rates -file eurofxref-daily.xml
+//	go run ./cmd/rates -file rates.csv -source treasury
+//
+// CSV files need a header with base, quote, rate and optionally as_of.
+// XML files are read as ECB reference rates (all quoted against EUR).
+package main
+
+import (
+	"context"
+	"e-commerce/internal/config"
+	"e-commerce/internal/database"
+	"e-commerce/internal/domain/models"
+	"e-commerce/internal/repository"
+	"e-commerce/internal/service"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	file := flag.String("file", "", "path to a CSV or ECB XML rates file")
+	format := flag.String("format", "", "csv or ecb (default: guessed from the file extension)")
+	source := flag.String("source", "csv", "source label stored with CSV rates")
+	flag.Parse()
+
+	if *file == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *format == "" {
+		*format = "csv"
+		if strings.EqualFold(filepath.Ext(*file), ".xml") {
+			*format = "ecb"
+		}
+	}
+
+	rates, err := readRates(*file, *format, *source)
+	if err != nil {
+		log.Fatalf("Failed to read %s: %v", *file, err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("Failed to load configuration:", err)
+	}
+	pool, err := database.InitDB(cfg.DSN)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer pool.Close()
+
+	svc := service.NewExchangeRateService(repository.NewExchangeRateRepo(pool))
+	written, err := svc.Import(context.Background(), rates)
+	if err != nil {
+		log.Fatalf("Failed to import rates: %v", err)
+	}
+	log.Printf("Read %d rates, updated %d", len(rates), written)
+}
+
+func readRates(path, format, source string) ([]models.ExchangeRate, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch format {
+	case "csv":
+		return service.ParseRatesCSV(f, source)
+	case "ecb":
+		return service.ParseECBRates(f)
+	default:
+		return nil, fmt.Errorf("unknown format %q, expected csv or ecb", format)
+	}
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidDownloadToken = errors.New("invalid download token")
+var ErrDownloadTokenExpired = errors.New("download token has expired")
+
+// downloadKey derives the key download tokens are signed with from the
+// JWT secret, so that one kind of token can never pass for the other.
+func downloadKey(secret string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("download-token"))
+	return mac.Sum(nil)
+}
+
+func signDownload(secret string, payload []byte) []byte {
+	mac := hmac.New(sha256.New, downloadKey(secret))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// SignDownload returns a URL-safe token naming download link linkID until
+// expires, to the second.
+func SignDownload(secret string, linkID uuid.UUID, expires time.Time) string {
+	payload := make([]byte, 24)
+	copy(payload, linkID[:])
+	binary.BigEndian.PutUint64(payload[16:], uint64(expires.Unix()))
+
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(payload) + "." + enc.EncodeToString(signDownload(secret, payload))
+}
+
+// ParseDownload checks the token's signature and expiry and returns the
+// download link it names.
+func ParseDownload(secret, token string, now time.Time) (uuid.UUID, error) {
+	enc := base64.RawURLEncoding
+	payloadPart, sigPart, ok := strings.Cut(token, ".")
+	if !ok {
+		return uuid.Nil, ErrInvalidDownloadToken
+	}
+	payload, err := enc.DecodeString(payloadPart)
+	if err != nil || len(payload) != 24 {
+		return uuid.Nil, ErrInvalidDownloadToken
+	}
+	sig, err := enc.DecodeString(sigPart)
+	if err != nil || !hmac.Equal(sig, signDownload(secret, payload)) {
+		return uuid.Nil, ErrInvalidDownloadToken
+	}
+
+	if now.Unix() >= int64(binary.BigEndian.Uint64(payload[16:])) {
+		return uuid.Nil, ErrDownloadTokenExpired
+	}
+	linkID, err := uuid.FromBytes(payload[:16])
+	if err != nil {
+		return uuid.Nil, ErrInvalidDownloadToken
+	}
+	return linkID, nil
+}
@@ -5,15 +5,115 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+var searchLanguagePattern = regexp.MustCompile(`^[a-z_]+$`)
+
 type Config struct {
 	DSN       string
 	Port      string
 	JWTSecret string
 	RedisAddr string
+
+	SearchLanguage string
+
+	ReservationTTL           time.Duration
+	ReservationSweepInterval time.Duration
+
+	MediaDir      string
+	MediaURL      string
+	ImageMaxBytes int64
+
+	TrashRetention     time.Duration
+	TrashPurgeInterval time.Duration
+
+	ImportMaxBytes     int64
+	ImportPollInterval time.Duration
+
+	PublishScheduleInterval time.Duration
+	PriceScheduleInterval   time.Duration
+
+	AffinityInterval time.Duration
+
+	// FilesDir keeps digital product files. Unlike MediaDir it is never
+	// served; files only go out through signed download links.
+	FilesDir              string
+	FileMaxBytes          int64
+	DownloadLinkTTL       time.Duration
+	DownloadLimit         int
+	DownloadSweepInterval time.Duration
+
+	// RequireIfMatch makes product writes without If-Match fail with 428.
+	RequireIfMatch bool
+}
+
+func durationEnv(name string, def time.Duration) (time.Duration, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%s must be a positive duration like 15m, got %q", name, v)
+	}
+	return d, nil
+}
+
+func sizeEnv(name string, def int64) (int64, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%s must be a positive number of bytes, got %q", name, v)
+	}
+	return n, nil
+}
+
+func countEnv(name string, def int) (int, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%s must be a positive number, got %q", name, v)
+	}
+	return n, nil
+}
+
+// insideDir reports whether path is dir or somewhere below it.
+func insideDir(path, dir string) bool {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(absDir, absPath)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func daysEnv(name string, def int) (time.Duration, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return time.Duration(def) * 24 * time.Hour, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%s must be a positive number of days, got %q", name, v)
+	}
+	return time.Duration(n) * 24 * time.Hour, nil
 }
 
 func Load() (*Config, error) {
@@ -38,6 +138,102 @@ func Load() (*Config, error) {
 		port = "8080"
 	}
 
+	searchLanguage := os.Getenv("SEARCH_LANGUAGE")
+	if searchLanguage == "" {
+		searchLanguage = "english"
+	}
+	if !searchLanguagePattern.MatchString(searchLanguage) {
+		return nil, fmt.Errorf("SEARCH_LANGUAGE %q is not a valid text search configuration name", searchLanguage)
+	}
+
+	reservationTTL, err := durationEnv("RESERVATION_TTL", 15*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	reservationSweepInterval, err := durationEnv("RESERVATION_SWEEP_INTERVAL", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaDir := os.Getenv("MEDIA_DIR")
+	if mediaDir == "" {
+		mediaDir = "./data/media"
+	}
+	mediaURL := os.Getenv("MEDIA_URL")
+	if mediaURL == "" {
+		mediaURL = "/media"
+	}
+	imageMaxBytes, err := sizeEnv("IMAGE_MAX_BYTES", 10<<20)
+	if err != nil {
+		return nil, err
+	}
+
+	trashRetention, err := daysEnv("TRASH_RETENTION_DAYS", 30)
+	if err != nil {
+		return nil, err
+	}
+	trashPurgeInterval, err := durationEnv("TRASH_PURGE_INTERVAL", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	importMaxBytes, err := sizeEnv("IMPORT_MAX_BYTES", 20<<20)
+	if err != nil {
+		return nil, err
+	}
+	importPollInterval, err := durationEnv("IMPORT_POLL_INTERVAL", 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	publishScheduleInterval, err := durationEnv("PUBLISH_SCHEDULE_INTERVAL", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	priceScheduleInterval, err := durationEnv("PRICE_SCHEDULE_INTERVAL", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	affinityInterval, err := durationEnv("AFFINITY_INTERVAL", 6*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	filesDir := os.Getenv("FILES_DIR")
+	if filesDir == "" {
+		filesDir = "./data/files"
+	}
+	// the media directory is public, and so would be anything inside it
+	if strings.HasPrefix(mediaURL, "/") && insideDir(filesDir, mediaDir) {
+		return nil, fmt.Errorf("FILES_DIR %q must not be inside the publicly served MEDIA_DIR %q", filesDir, mediaDir)
+	}
+	fileMaxBytes, err := sizeEnv("FILE_MAX_BYTES", 200<<20)
+	if err != nil {
+		return nil, err
+	}
+	downloadLinkTTL, err := durationEnv("DOWNLOAD_LINK_TTL", 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	downloadLimit, err := countEnv("DOWNLOAD_LIMIT", 5)
+	if err != nil {
+		return nil, err
+	}
+	downloadSweepInterval, err := durationEnv("DOWNLOAD_SWEEP_INTERVAL", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	requireIfMatch := false
+	if v := os.Getenv("REQUIRE_IF_MATCH"); v != "" {
+		requireIfMatch, err = strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("REQUIRE_IF_MATCH must be true or false, got %q", v)
+		}
+	}
+
 	if os.Getenv("JWT_SECRET") == "" {
 		return nil, errors.New("JWT_SECRET environment variable is required")
 	}
@@ -47,5 +243,33 @@ func Load() (*Config, error) {
 		Port:      port,
 		JWTSecret: os.Getenv("JWT_SECRET"),
 		RedisAddr: redisAddr,
+
+		SearchLanguage: searchLanguage,
+
+		ReservationTTL:           reservationTTL,
+		ReservationSweepInterval: reservationSweepInterval,
+
+		MediaDir:      mediaDir,
+		MediaURL:      mediaURL,
+		ImageMaxBytes: imageMaxBytes,
+
+		TrashRetention:     trashRetention,
+		TrashPurgeInterval: trashPurgeInterval,
+
+		ImportMaxBytes:     importMaxBytes,
+		ImportPollInterval: importPollInterval,
+
+		PublishScheduleInterval: publishScheduleInterval,
+		PriceScheduleInterval:   priceScheduleInterval,
+
+		AffinityInterval: affinityInterval,
+
+		FilesDir:              filesDir,
+		FileMaxBytes:          fileMaxBytes,
+		DownloadLinkTTL:       downloadLinkTTL,
+		DownloadLimit:         downloadLimit,
+		DownloadSweepInterval: downloadSweepInterval,
+
+		RequireIfMatch: requireIfMatch,
 	}, nil
 }
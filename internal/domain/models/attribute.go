@@ -0,0 +1,157 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Attribute types a category can define.
+const (
+	AttributeString  = "string"
+	AttributeNumber  = "number"
+	AttributeInteger = "integer"
+	AttributeBoolean = "boolean"
+)
+
+const (
+	MaxProductAttributes    = 50
+	MaxCategoryAttributes   = 50
+	MaxAttributeValueLength = 500
+)
+
+// maxSafeInteger is the largest integer a JSON number holds exactly.
+const maxSafeInteger = 1<<53 - 1
+
+var attributeKeyRe = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+// ValidAttributeKey reports whether key can name an attribute: lowercase
+// letters, digits and underscores, starting with a letter.
+func ValidAttributeKey(key string) bool {
+	return attributeKeyRe.MatchString(key)
+}
+
+// ValidAttributeValue reports whether v can be stored as an attribute
+// value. Values are scalars as decoded from JSON; nested objects and
+// arrays are not supported.
+func ValidAttributeValue(v any) bool {
+	switch v := v.(type) {
+	case string:
+		return len(v) <= MaxAttributeValueLength
+	case float64, bool:
+		return true
+	}
+	return false
+}
+
+// AttributeDefinition describes a custom field of the products in a
+// category, such as "isbn" for books or "voltage" for electronics. It
+// also applies to products in the category's subcategories.
+type AttributeDefinition struct {
+	Key      string `json:"key"`
+	Type     string `json:"type"`
+	Required bool   `json:"required"`
+	Enum     []any  `json:"enum,omitempty"`
+	Unit     string `json:"unit,omitempty"`
+
+	// CategoryID names the category a definition comes from when it is
+	// listed together with inherited ones.
+	CategoryID *uuid.UUID `json:"category_id,omitempty"`
+}
+
+// AttributeViolation is a product attribute that breaks a definition.
+type AttributeViolation struct {
+	Key     string
+	Message string
+}
+
+func (d AttributeDefinition) typeError(v any) string {
+	switch d.Type {
+	case AttributeString:
+		if _, ok := v.(string); !ok {
+			return "must be a string"
+		}
+	case AttributeNumber:
+		if _, ok := v.(float64); !ok {
+			return "must be a number"
+		}
+	case AttributeInteger:
+		f, ok := v.(float64)
+		if !ok || f != math.Trunc(f) || math.Abs(f) > maxSafeInteger {
+			return "must be an integer"
+		}
+	case AttributeBoolean:
+		if _, ok := v.(bool); !ok {
+			return "must be a boolean"
+		}
+	default:
+		return "has an unknown type " + d.Type
+	}
+	return ""
+}
+
+// Check returns why v is not a valid value for the attribute, or "".
+func (d AttributeDefinition) Check(v any) string {
+	if msg := d.typeError(v); msg != "" {
+		return msg
+	}
+	if len(d.Enum) == 0 {
+		return ""
+	}
+	for _, allowed := range d.Enum {
+		if allowed == v {
+			return ""
+		}
+	}
+	values := make([]string, len(d.Enum))
+	for i, allowed := range d.Enum {
+		values[i] = fmt.Sprint(allowed)
+	}
+	return "must be one of: " + strings.Join(values, ", ")
+}
+
+// EnumError returns why the definition's enum doesn't fit its type, or "".
+func (d AttributeDefinition) EnumError() string {
+	seen := make(map[any]bool, len(d.Enum))
+	for _, v := range d.Enum {
+		if msg := d.typeError(v); msg != "" {
+			return "every value " + msg
+		}
+		if seen[v] {
+			return "must not contain duplicates"
+		}
+		seen[v] = true
+	}
+	return ""
+}
+
+// ValidateAttributes checks attrs against defs, reporting at most one
+// violation per key, in key order. Keys no definition mentions are free.
+func ValidateAttributes(defs []AttributeDefinition, attrs map[string]any) []AttributeViolation {
+	found := make(map[string]string)
+	for _, d := range defs {
+		if _, done := found[d.Key]; done {
+			continue
+		}
+		v, ok := attrs[d.Key]
+		switch {
+		case !ok && d.Required:
+			found[d.Key] = "is required"
+		case ok:
+			if msg := d.Check(v); msg != "" {
+				found[d.Key] = msg
+			}
+		}
+	}
+
+	violations := make([]AttributeViolation, 0, len(found))
+	for key, msg := range found {
+		violations = append(violations, AttributeViolation{Key: key, Message: msg})
+	}
+	sort.Slice(violations, func(i, j int) bool { return violations[i].Key < violations[j].Key })
+	return violations
+}
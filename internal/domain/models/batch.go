@@ -0,0 +1,23 @@
+package models
+
+// ProductOperation is one step of a batch: a create, update, patch or
+// delete, carrying the same inputs as the matching single endpoint.
+type ProductOperation struct {
+	Op    string
+	ID    string
+	Name  string
+	Price Money
+	// Attributes of a create or update; nil leaves an update's as they are.
+	Attributes map[string]any
+	// Tags of a create or update; nil leaves an update's as they are.
+	Tags    []string
+	Updates map[string]any
+	IfMatch []int
+}
+
+// ProductOperationResult is the outcome of one operation. Product is nil
+// for deletes and for operations that failed with Err.
+type ProductOperationResult struct {
+	Product *Product
+	Err     error
+}
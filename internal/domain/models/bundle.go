@@ -0,0 +1,29 @@
+package models
+
+import "github.com/google/uuid"
+
+const (
+	MaxBundleItems        = 20
+	MaxBundleItemQuantity = 100
+)
+
+// ProductBundle makes a product a set of the seller's other products.
+// With DiscountPercent set the product's price follows the components'
+// total less the discount; otherwise the price is fixed and must stay
+// below that total.
+type ProductBundle struct {
+	Items           []BundleItem `json:"items"`
+	DiscountPercent *int         `json:"discount_percent"`
+	// ComponentsTotal is what the items cost bought separately; nil if
+	// their currencies differ.
+	ComponentsTotal *Money `json:"components_total"`
+	// Available is how many whole bundles the components' stock makes up.
+	Available int `json:"available"`
+}
+
+type BundleItem struct {
+	ProductID uuid.UUID `json:"product_id"`
+	Name      string    `json:"name"`
+	Quantity  int       `json:"quantity"`
+	Price     Money     `json:"price"`
+}
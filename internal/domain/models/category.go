@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Category is a node in a seller's category tree. Path is the materialized
+// path of ancestor IDs, e.g. "/<root-id>/<child-id>/".
+type Category struct {
+	ID       uuid.UUID  `json:"id" db:"id"`
+	UserID   uuid.UUID  `json:"user_id" db:"user_id"`
+	ParentID *uuid.UUID `json:"parent_id" db:"parent_id"`
+	Name     string     `json:"name" db:"name"`
+	Path     string     `json:"-" db:"path"`
+	Depth    int        `json:"depth"`
+	// Attributes are the category's own definitions; subcategories
+	// inherit them.
+	Attributes []AttributeDefinition `json:"attributes" db:"attributes"`
+	CreatedAt  time.Time             `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time             `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ProductPhysical = "physical"
+	ProductDigital  = "digital"
+)
+
+const (
+	MaxProductFiles   = 10
+	MaxFileNameLength = 255
+)
+
+// ProductFile is a file a digital product delivers to its buyers. The
+// file itself is only reachable through a DownloadLink.
+type ProductFile struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	ProductID   uuid.UUID `json:"product_id" db:"product_id"`
+	Name        string    `json:"name" db:"name"`
+	ContentType string    `json:"content_type" db:"content_type"`
+	SizeBytes   int64     `json:"size_bytes" db:"size_bytes"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+
+	BlobKey string `json:"-" db:"blob_key"`
+}
+
+// DownloadLink lets a buyer download a file up to MaxDownloads times
+// until ExpiresAt. URL carries the signed token and needs no other
+// credentials.
+type DownloadLink struct {
+	ID           uuid.UUID `json:"-"`
+	FileID       uuid.UUID `json:"file_id"`
+	Name         string    `json:"name"`
+	SizeBytes    int64     `json:"size_bytes"`
+	URL          string    `json:"url"`
+	MaxDownloads int       `json:"max_downloads"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
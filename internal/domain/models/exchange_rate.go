@@ -0,0 +1,35 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ExchangeRate says that one unit of Base is worth Rate units of Quote.
+// Rate is kept as a decimal string so it round-trips without loss.
+type ExchangeRate struct {
+	Base      string    `json:"base"`
+	Quote     string    `json:"quote"`
+	Rate      string    `json:"rate"`
+	AsOf      time.Time `json:"as_of"`
+	Source    string    `json:"source"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ConvertedPrice is a price presented in the buyer's currency together
+// with the rate that produced it. RateAsOf is nil when no conversion was
+// needed.
+type ConvertedPrice struct {
+	Price    Money
+	Rate     string
+	RateAsOf *time.Time
+}
+
+func (p ConvertedPrice) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount   string     `json:"amount"`
+		Currency string     `json:"currency"`
+		Rate     string     `json:"rate"`
+		RateAsOf *time.Time `json:"rate_as_of,omitempty"`
+	}{p.Price.Decimal(), p.Price.Currency, p.Rate, p.RateAsOf})
+}
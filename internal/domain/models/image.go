@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const MaxProductImages = 20
+
+// ProductImage is an uploaded picture of a product. Images are shown in
+// ascending Position order; the first one is the main picture.
+type ProductImage struct {
+	ID           uuid.UUID `json:"id" db:"id"`
+	ProductID    uuid.UUID `json:"product_id" db:"product_id"`
+	Position     int       `json:"position" db:"position"`
+	URL          string    `json:"url" db:"url"`
+	ThumbnailURL string    `json:"thumbnail_url" db:"thumbnail_url"`
+	ContentType  string    `json:"content_type" db:"content_type"`
+	SizeBytes    int64     `json:"size_bytes" db:"size_bytes"`
+	Width        int       `json:"width" db:"width"`
+	Height       int       `json:"height" db:"height"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+
+	OriginalKey  string `json:"-" db:"original_key"`
+	ThumbnailKey string `json:"-" db:"thumbnail_key"`
+}
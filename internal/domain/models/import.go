@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ImportPending = "pending"
+	ImportRunning = "running"
+	ImportDone    = "done"
+	ImportFailed  = "failed"
+)
+
+const (
+	ImportRowCreated = "created"
+	ImportRowUpdated = "updated"
+	ImportRowFailed  = "failed"
+)
+
+// ProductImportRow is a validated spreadsheet row waiting to be applied.
+// A row with an ID updates that product; one without creates a product.
+type ProductImportRow struct {
+	Line  int        `json:"line"`
+	ID    *uuid.UUID `json:"id,omitempty"`
+	Name  string     `json:"name"`
+	Price Money      `json:"price"`
+}
+
+type ImportRowError struct {
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportRowResult reports what happened to one line of the file. Line
+// numbers count the header as line 1.
+type ImportRowResult struct {
+	Line      int              `json:"line"`
+	Status    string           `json:"status"`
+	ProductID *uuid.UUID       `json:"product_id,omitempty"`
+	Errors    []ImportRowError `json:"errors,omitempty"`
+}
+
+type ImportSummary struct {
+	Total   int `json:"total"`
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Failed  int `json:"failed"`
+}
+
+// ImportJob is a bulk product import. Rows is filled in once the job is
+// done; a dry run reports the same outcome without keeping any change.
+type ImportJob struct {
+	ID         uuid.UUID         `json:"id"`
+	UserID     uuid.UUID         `json:"-"`
+	Status     string            `json:"status"`
+	Format     string            `json:"format"`
+	DryRun     bool              `json:"dry_run"`
+	Summary    ImportSummary     `json:"summary"`
+	Rows       []ImportRowResult `json:"rows"`
+	Error      string            `json:"error,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+	FinishedAt *time.Time        `json:"finished_at,omitempty"`
+}
+
+// SummarizeImport counts the outcomes in rows. Lines not yet reported on
+// are included in total only.
+func SummarizeImport(total int, rows []ImportRowResult) ImportSummary {
+	summary := ImportSummary{Total: total}
+	for _, row := range rows {
+		switch row.Status {
+		case ImportRowCreated:
+			summary.Created++
+		case ImportRowUpdated:
+			summary.Updated++
+		case ImportRowFailed:
+			summary.Failed++
+		}
+	}
+	return summary
+}
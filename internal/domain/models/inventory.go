@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MovementReceipt    = "receipt"
+	MovementSale       = "sale"
+	MovementAdjustment = "adjustment"
+	MovementReturn     = "return"
+)
+
+const (
+	ReservationActive    = "active"
+	ReservationCommitted = "committed"
+	ReservationReleased  = "released"
+	ReservationExpired   = "expired"
+)
+
+// StockLevel is the on-hand quantity of a product, or of one of its
+// variants when VariantID is set.
+type StockLevel struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	ProductID uuid.UUID  `json:"product_id" db:"product_id"`
+	VariantID *uuid.UUID `json:"variant_id" db:"variant_id"`
+	OnHand    int        `json:"on_hand" db:"on_hand"`
+	Reserved  int        `json:"reserved" db:"reserved"`
+	Available int        `json:"available"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+type StockMovement struct {
+	ID            int64      `json:"id" db:"id"`
+	ProductID     uuid.UUID  `json:"product_id" db:"product_id"`
+	VariantID     *uuid.UUID `json:"variant_id" db:"variant_id"`
+	Quantity      int        `json:"quantity" db:"quantity"`
+	Reason        string     `json:"reason" db:"reason"`
+	Note          string     `json:"note" db:"note"`
+	ReservationID *uuid.UUID `json:"reservation_id" db:"reservation_id"`
+	CreatedBy     *uuid.UUID `json:"created_by" db:"created_by"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+type StockMovementPage struct {
+	Items      []StockMovement `json:"items"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+	HasMore    bool            `json:"has_more"`
+}
+
+type Reservation struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	ProductID uuid.UUID  `json:"product_id" db:"product_id"`
+	VariantID *uuid.UUID `json:"variant_id" db:"variant_id"`
+	UserID    uuid.UUID  `json:"user_id" db:"user_id"`
+	Quantity  int        `json:"quantity" db:"quantity"`
+	Status    string     `json:"status" db:"status"`
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
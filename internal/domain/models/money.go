@@ -0,0 +1,213 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var ErrUnknownCurrency = errors.New("unknown currency")
+var ErrInvalidAmount = errors.New("invalid amount")
+var ErrTooManyDecimals = errors.New("amount has more decimal places than the currency allows")
+
+// currencyExponents lists supported ISO 4217 codes and the number of
+// decimal places of their minor unit.
+var currencyExponents = map[string]int{
+	"AED": 2, "AUD": 2, "BGN": 2, "BHD": 3, "BRL": 2, "CAD": 2, "CHF": 2,
+	"CLP": 0, "CNY": 2, "CZK": 2, "DKK": 2, "EUR": 2, "GBP": 2, "HKD": 2,
+	"HUF": 2, "IDR": 2, "ILS": 2, "INR": 2, "ISK": 0, "JOD": 3, "JPY": 0,
+	"KRW": 0, "KWD": 3, "KZT": 2, "MXN": 2, "MYR": 2, "NOK": 2, "NZD": 2,
+	"OMR": 3, "PHP": 2, "PLN": 2, "RON": 2, "RUB": 2, "SEK": 2, "SGD": 2,
+	"THB": 2, "TND": 3, "TRY": 2, "UAH": 2, "USD": 2, "VND": 0, "ZAR": 2,
+}
+
+// CurrencyExponent reports the number of decimal places used by currency.
+func CurrencyExponent(currency string) (int, bool) {
+	exp, ok := currencyExponents[currency]
+	return exp, ok
+}
+
+// Currencies lists the supported currency codes in alphabetical order.
+func Currencies() []string {
+	codes := make([]string, 0, len(currencyExponents))
+	for code := range currencyExponents {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Money is an exact amount in the minor unit of an ISO 4217 currency,
+// e.g. {1999, "USD"} is $19.99. In JSON the amount is a decimal string
+// so clients never see binary floating point.
+type Money struct {
+	Amount   int64
+	Currency string
+
+	// err records why the JSON value could not be parsed; it is surfaced
+	// by Validate so that binding reports it as a field error.
+	err error
+}
+
+// ParseMoney parses a decimal amount such as "19.99" in currency.
+func ParseMoney(amount, currency string) (Money, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	exp, ok := currencyExponents[currency]
+	if !ok {
+		return Money{}, ErrUnknownCurrency
+	}
+
+	intPart, fracPart, hasFrac := strings.Cut(strings.TrimSpace(amount), ".")
+	if intPart == "" || !isDigits(intPart) || (hasFrac && (fracPart == "" || !isDigits(fracPart))) {
+		return Money{}, ErrInvalidAmount
+	}
+	// trailing zeros don't add precision: "19.990" is fine for USD
+	fracPart = strings.TrimRight(fracPart, "0")
+	if len(fracPart) > exp {
+		return Money{}, ErrTooManyDecimals
+	}
+	fracPart += strings.Repeat("0", exp-len(fracPart))
+
+	minor, err := strconv.ParseInt(intPart+fracPart, 10, 64)
+	if err != nil || minor > math.MaxInt64/1000 {
+		return Money{}, ErrInvalidAmount
+	}
+	return Money{Amount: minor, Currency: currency}, nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Decimal formats the amount without the currency, e.g. "19.99".
+func (m Money) Decimal() string {
+	exp := currencyExponents[m.Currency]
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	s := strconv.FormatInt(amount, 10)
+	if exp == 0 {
+		return sign + s
+	}
+	if len(s) <= exp {
+		s = strings.Repeat("0", exp-len(s)+1) + s
+	}
+	return sign + s[:len(s)-exp] + "." + s[len(s)-exp:]
+}
+
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+// Validate reports whether m is a usable price: a known currency and a
+// positive amount that was parsed without loss.
+func (m Money) Validate() error {
+	if m.err != nil {
+		return m.err
+	}
+	if _, ok := currencyExponents[m.Currency]; !ok {
+		return ErrUnknownCurrency
+	}
+	if m.Amount <= 0 {
+		return ErrInvalidAmount
+	}
+	return nil
+}
+
+// Convert multiplies m by rate, expressed in whole units of to per whole
+// unit of m's currency, and rounds half to even in to's minor unit.
+func (m Money) Convert(rate *big.Rat, to string) (Money, error) {
+	toExp, ok := currencyExponents[to]
+	if !ok {
+		return Money{}, ErrUnknownCurrency
+	}
+	fromExp := currencyExponents[m.Currency]
+
+	v := new(big.Rat).SetInt64(m.Amount)
+	v.Mul(v, rate)
+	shift := toExp - fromExp
+	if shift < 0 {
+		shift = -shift
+	}
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(shift)), nil))
+	if toExp > fromExp {
+		v.Mul(v, scale)
+	} else {
+		v.Quo(v, scale)
+	}
+
+	n := roundHalfEven(v)
+	if !n.IsInt64() {
+		return Money{}, ErrInvalidAmount
+	}
+	return Money{Amount: n.Int64(), Currency: to}, nil
+}
+
+// roundHalfEven rounds r to the nearest integer, ties going to the even
+// neighbour (banker's rounding).
+func roundHalfEven(r *big.Rat) *big.Int {
+	q, rem := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	twice := new(big.Int).Abs(rem)
+	twice.Lsh(twice, 1)
+
+	cmp := twice.Cmp(r.Denom())
+	if cmp > 0 || (cmp == 0 && q.Bit(0) == 1) {
+		if r.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q
+}
+
+type moneyJSON struct {
+	Amount   json.Number `json:"amount"`
+	Currency string      `json:"currency"`
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	}{m.Decimal(), m.Currency})
+}
+
+// UnmarshalJSON accepts the amount as a string or a JSON number. Values
+// that are well-formed JSON but not valid money are kept as a validation
+// error rather than failing the whole decode.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var raw moneyJSON
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil {
+		var s string
+		if json.Unmarshal(data, &s) == nil {
+			*m = Money{err: fmt.Errorf("%w: expected an object with amount and currency", ErrInvalidAmount)}
+			return nil
+		}
+		return err
+	}
+
+	parsed, err := ParseMoney(raw.Amount.String(), raw.Currency)
+	if err != nil {
+		*m = Money{Currency: strings.ToUpper(raw.Currency), err: err}
+		return nil
+	}
+	*m = parsed
+	return nil
+}
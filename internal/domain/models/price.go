@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ScheduledPricePending = "pending"
+	ScheduledPriceActive  = "active"
+	ScheduledPriceDone    = "done"
+	ScheduledPriceFailed  = "failed"
+)
+
+// LowestPriceWindow is how far back the lowest prior price looks, as the
+// EU Omnibus Directive requires for announced price reductions.
+const LowestPriceWindow = 30 * 24 * time.Hour
+
+// PricePeriod is a price the product had from EffectiveFrom until
+// EffectiveTo; the current price has no EffectiveTo.
+type PricePeriod struct {
+	Price         Money      `json:"price"`
+	EffectiveFrom time.Time  `json:"effective_from"`
+	EffectiveTo   *time.Time `json:"effective_to"`
+}
+
+// ScheduledPrice replaces the product's price from EffectiveFrom and, if
+// EffectiveTo is set, restores the previous price then. Status moves from
+// pending to active when it starts and to done when it ends; failed means
+// the price couldn't be applied and Error says why.
+type ScheduledPrice struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	ProductID     uuid.UUID  `json:"product_id" db:"product_id"`
+	Price         Money      `json:"price" db:"price"`
+	EffectiveFrom time.Time  `json:"effective_from" db:"effective_from"`
+	EffectiveTo   *time.Time `json:"effective_to" db:"effective_to"`
+	Status        string     `json:"status" db:"status"`
+	Error         *string    `json:"error,omitempty" db:"error"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+// PriceTimeline is a product's price history, oldest first, followed by
+// the prices scheduled for it. LowestPrice30d is the lowest price in the
+// LowestPriceWindow before the current price took effect, in the current
+// currency, or nil if there was none.
+type PriceTimeline struct {
+	Current        Money            `json:"current"`
+	LowestPrice30d *Money           `json:"lowest_price_30d"`
+	History        []PricePeriod    `json:"history"`
+	Scheduled      []ScheduledPrice `json:"scheduled"`
+}
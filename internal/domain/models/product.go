@@ -1,6 +1,8 @@
 package models
 
 import (
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,8 +11,151 @@ import (
 type Product struct {
 	ID        uuid.UUID `json:"id" db:"id"`
 	Name      string    `json:"name" db:"name"`
-	Price     float64   `json:"price" db:"price"`
+	Price     Money     `json:"price" db:"price"`
 	UserID    uuid.UUID `json:"user_id" db:"user_id"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	// Version increases on every write and is the product's ETag.
+	Version int `json:"version" db:"version"`
+	// DeletedAt is set while the product is in the trash.
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	// Attributes holds the custom fields defined by the product's
+	// categories, plus any free-form ones.
+	Attributes map[string]any `json:"attributes" db:"attributes"`
+	// Tags are lowercase free-form labels, kept sorted.
+	Tags []string `json:"tags" db:"tags"`
+	// Status is draft, published or archived. PublishAt and UnpublishAt
+	// schedule the next automatic change, if any.
+	Status      string     `json:"status" db:"status"`
+	PublishAt   *time.Time `json:"publish_at" db:"publish_at"`
+	UnpublishAt *time.Time `json:"unpublish_at" db:"unpublish_at"`
+	// Slug names the product in storefront URLs. It is generated from the
+	// name on create and only changes when the seller sets another one.
+	Slug string `json:"slug" db:"slug"`
+	// Type is physical or digital; digital products deliver files.
+	Type string `json:"type" db:"type"`
+	// Rating is kept up to date as reviews are approved, edited and
+	// removed.
+	Rating ProductRating `json:"rating" db:"-"`
+
+	ConvertedPrice *ConvertedPrice `json:"converted_price,omitempty" db:"-"`
+	// LowestPrice30d is only filled in for the storefront; see PriceTimeline.
+	LowestPrice30d *Money `json:"lowest_price_30d,omitempty" db:"-"`
+
+	Options  []ProductOption  `json:"options,omitempty"`
+	Variants []ProductVariant `json:"variants,omitempty"`
+	Images   []ProductImage   `json:"images,omitempty"`
+	// Bundle is set for bundle products.
+	Bundle *ProductBundle `json:"bundle,omitempty"`
+}
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+type ProductListParams struct {
+	Limit      int
+	Cursor     string
+	SortBy     string
+	Order      string
+	MinPrice   *Money
+	MaxPrice   *Money
+	NamePrefix string
+	// Attributes keeps products having each attribute equal to the value,
+	// which may match a string, number or boolean.
+	Attributes map[string]string
+	// Tags keeps products with any of the tags, or all of them with
+	// MatchAllTags.
+	Tags         []string
+	MatchAllTags bool
+	// Facets asks for counts over the whole filtered set, not just the page.
+	Facets bool
+	// Status keeps products in that status; empty means any.
+	Status string
+	// Trashed lists soft-deleted products instead of live ones.
+	Trashed bool
+}
+
+type ProductPage struct {
+	Items      []Product      `json:"items"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+	HasMore    bool           `json:"has_more"`
+	Facets     *ProductFacets `json:"facets,omitempty"`
+}
+
+const (
+	MaxProductTags = 20
+	MaxTagLength   = 50
+	// MaxTagFacets is how many of the most used tags a facet lists.
+	MaxTagFacets = 50
+)
+
+// PriceBucketBounds split prices into facet buckets, in whole units of
+// each currency.
+var PriceBucketBounds = []int64{10, 25, 50, 100, 250, 500, 1000, 2500, 5000}
+
+// ProductFacets counts the products matching a listing's filters by tag,
+// price range and category.
+type ProductFacets struct {
+	Tags       []TagFacet      `json:"tags"`
+	Prices     []PriceFacet    `json:"prices"`
+	Categories []CategoryFacet `json:"categories"`
+}
+
+type TagFacet struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// PriceFacet counts prices from Min inclusive to Max exclusive; the
+// lowest bucket has no Min and the highest no Max.
+type PriceFacet struct {
+	Currency string `json:"currency"`
+	Min      *Money `json:"min"`
+	Max      *Money `json:"max"`
+	Count    int    `json:"count"`
+}
+
+type CategoryFacet struct {
+	ID    uuid.UUID `json:"id"`
+	Name  string    `json:"name"`
+	Count int       `json:"count"`
+}
+
+// NormalizeTags lowercases and trims tags, dropping blanks and duplicates,
+// and sorts them. A nil slice stays nil.
+func NormalizeTags(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+type ProductSearchParams struct {
+	Query  string
+	Limit  int
+	Offset int
+}
+
+type ProductSearchResult struct {
+	Product
+	Rank      float32 `json:"rank"`
+	Highlight string  `json:"highlight"`
+}
+
+type ProductSearchPage struct {
+	Items   []ProductSearchResult `json:"items"`
+	HasMore bool                  `json:"has_more"`
 }
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+const (
+	RelatedBoughtTogether = "bought_together"
+	RelatedSimilar        = "similar"
+)
+
+const (
+	DefaultRelatedLimit = 10
+	MaxRelatedLimit     = 20
+)
+
+const (
+	// AffinityLookback is how far back the affinity job looks at sales.
+	AffinityLookback = 180 * 24 * time.Hour
+	// CoPurchaseWindow groups one buyer's purchases into a basket: there
+	// are no orders yet, so items whose reservations were committed this
+	// close together count as bought together.
+	CoPurchaseWindow = time.Hour
+	// MinAffinityBuyers is how many buyers must have bought two products
+	// together before one is recommended with the other.
+	MinAffinityBuyers = 2
+)
+
+// RelatedProduct is a product recommended alongside another. Reason is
+// RelatedBoughtTogether for co-purchase recommendations and RelatedSimilar
+// for products sharing a category or tags.
+type RelatedProduct struct {
+	Product
+	Reason string `json:"reason"`
+}
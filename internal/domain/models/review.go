@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ReviewPending  = "pending"
+	ReviewApproved = "approved"
+	ReviewRejected = "rejected"
+)
+
+const (
+	ReviewSortRecent  = "recent"
+	ReviewSortHelpful = "helpful"
+)
+
+// Review is a customer's rating of a product. A new or edited review is
+// pending until the product's seller approves it; only approved reviews
+// are public and count towards the product's Rating.
+type Review struct {
+	ID           uuid.UUID    `json:"id" db:"id"`
+	ProductID    uuid.UUID    `json:"product_id" db:"product_id"`
+	UserID       uuid.UUID    `json:"user_id" db:"user_id"`
+	Rating       int          `json:"rating" db:"rating"`
+	Title        string       `json:"title" db:"title"`
+	Body         string       `json:"body" db:"body"`
+	Status       string       `json:"status" db:"status"`
+	HelpfulCount int          `json:"helpful_count" db:"helpful_count"`
+	Reply        *ReviewReply `json:"reply" db:"-"`
+	CreatedAt    time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at" db:"updated_at"`
+}
+
+// ReviewReply is the seller's public answer to a review.
+type ReviewReply struct {
+	Body      string    `json:"body"`
+	RepliedAt time.Time `json:"replied_at"`
+}
+
+// ProductRating sums up a product's approved reviews; Average is rounded
+// to two decimals and is zero while Count is.
+type ProductRating struct {
+	Average float64 `json:"average"`
+	Count   int     `json:"count"`
+}
+
+type ReviewListParams struct {
+	Limit  int
+	Cursor string
+	// Sort is ReviewSortRecent, the default, or ReviewSortHelpful.
+	Sort string
+	// Status keeps reviews in that status; empty means any. The
+	// storefront always lists approved reviews only.
+	Status string
+}
+
+type ReviewPage struct {
+	Items      []Review `json:"items"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+	HasMore    bool     `json:"has_more"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FieldChange is one field that differs between two product revisions.
+type FieldChange struct {
+	Field  string `json:"field"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
+
+// ProductRevision is a recorded change to a product. Before is nil for the
+// revision that created it; ActorID is nil for changes not made through
+// the API, such as background jobs.
+type ProductRevision struct {
+	ProductID uuid.UUID     `json:"product_id"`
+	Revision  int           `json:"revision"`
+	Action    string        `json:"action"`
+	ActorID   *uuid.UUID    `json:"actor_id"`
+	Changes   []FieldChange `json:"changes"`
+	CreatedAt time.Time     `json:"created_at"`
+
+	Before map[string]any `json:"-"`
+	After  map[string]any `json:"-"`
+}
+
+type ProductRevisionPage struct {
+	Items      []ProductRevision `json:"items"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+	HasMore    bool              `json:"has_more"`
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	MaxSlugLength = 100
+	// MaxSlugBaseLength leaves room for the -N suffix that makes a
+	// generated slug unique.
+	MaxSlugBaseLength = 80
+	// fallbackSlug stands in for names with nothing to transliterate.
+	fallbackSlug = "product"
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// slugLetters transliterates letters that don't decompose into a Latin
+// base letter and a diacritic.
+var slugLetters = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "h", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "sch", 'ъ': "",
+	'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	'є': "ye", 'і': "i", 'ї': "yi", 'ґ': "g",
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'ł': "l", 'đ': "d", 'ð': "d", 'þ': "th",
+}
+
+func ValidSlug(s string) bool {
+	return len(s) <= MaxSlugLength && slugPattern.MatchString(s)
+}
+
+// Slugify turns a product name into a URL slug: lowercase Latin letters
+// and digits separated by single hyphens. Cyrillic is transliterated and
+// diacritics are dropped. The result is only a base; the database makes
+// it unique.
+func Slugify(name string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(norm.NFC.String(name)) {
+		s, known := slugLetters[r]
+		if !known {
+			s = latinBase(r)
+		}
+		if s == "" {
+			// hard and soft signs vanish, anything else separates words
+			if !known {
+				hyphen = b.Len() > 0
+			}
+			continue
+		}
+		if hyphen {
+			b.WriteByte('-')
+			hyphen = false
+		}
+		b.WriteString(s)
+	}
+
+	slug := b.String()
+	if len(slug) > MaxSlugBaseLength {
+		slug = strings.TrimRight(slug[:MaxSlugBaseLength], "-")
+	}
+	if slug == "" {
+		return fallbackSlug
+	}
+	return slug
+}
+
+// latinBase strips diacritics from r, leaving "" if it isn't an ASCII
+// letter or digit underneath.
+func latinBase(r rune) string {
+	var b strings.Builder
+	for _, d := range norm.NFD.String(string(r)) {
+		if d < unicode.MaxASCII && (unicode.IsLetter(d) || unicode.IsDigit(d)) {
+			b.WriteRune(d)
+		}
+	}
+	return b.String()
+}
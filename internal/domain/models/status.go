@@ -0,0 +1,33 @@
+package models
+
+const (
+	ProductDraft     = "draft"
+	ProductPublished = "published"
+	ProductArchived  = "archived"
+)
+
+// productTransitions lists the statuses each status may move to. Only
+// published products are visible in the catalog.
+var productTransitions = map[string][]string{
+	ProductDraft:     {ProductPublished, ProductArchived},
+	ProductPublished: {ProductDraft, ProductArchived},
+	ProductArchived:  {ProductDraft},
+}
+
+func ValidProductStatus(status string) bool {
+	_, ok := productTransitions[status]
+	return ok
+}
+
+// StatusSources returns the statuses a product may move to status from.
+func StatusSources(status string) []string {
+	var sources []string
+	for _, from := range []string{ProductDraft, ProductPublished, ProductArchived} {
+		for _, to := range productTransitions[from] {
+			if to == status {
+				sources = append(sources, from)
+			}
+		}
+	}
+	return sources
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const MaxProductVariants = 100
+
+// ProductOption is a dimension a product varies by, such as size or colour.
+type ProductOption struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	Name     string    `json:"name" db:"name"`
+	Position int       `json:"position" db:"position"`
+	Values   []string  `json:"values" db:"option_values"`
+}
+
+// ProductVariant is one sellable combination of option values. A nil Price
+// means the product's own price applies.
+type ProductVariant struct {
+	ID        uuid.UUID         `json:"id" db:"id"`
+	ProductID uuid.UUID         `json:"product_id" db:"product_id"`
+	SKU       string            `json:"sku" db:"sku"`
+	Price     *Money            `json:"price" db:"price"`
+	Options   map[string]string `json:"options" db:"options"`
+	CreatedAt time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at" db:"updated_at"`
+
+	ConvertedPrice *ConvertedPrice `json:"converted_price,omitempty" db:"-"`
+}
+
+type ProductVariants struct {
+	Options  []ProductOption  `json:"options"`
+	Variants []ProductVariant `json:"variants"`
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"e-commerce/internal/config"
+	"e-commerce/internal/utils/xgin"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireIfMatch rejects writes without an If-Match header when strict
+// mode is on, so clients can't overwrite changes they haven't seen.
+func RequireIfMatch(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !cfg.RequireIfMatch || c.GetHeader("If-Match") != "" {
+			c.Next()
+			return
+		}
+		xgin.ErrorResponse(c, http.StatusPreconditionRequired, "Precondition required", "Send the product's ETag in an If-Match header")
+		c.Abort()
+	}
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"e-commerce/internal/domain/models"
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrInvalidAttributes = errors.New("product attributes do not match the category definitions")
+
+// AttributesError lists the attributes that break the definitions of the
+// product's categories.
+type AttributesError struct {
+	Violations []models.AttributeViolation
+}
+
+func (e *AttributesError) Error() string {
+	parts := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		parts[i] = v.Key + " " + v.Message
+	}
+	return ErrInvalidAttributes.Error() + ": " + strings.Join(parts, "; ")
+}
+
+func (e *AttributesError) Is(target error) bool {
+	return target == ErrInvalidAttributes
+}
+
+// attributeDefinitions returns the definitions that apply to products in
+// the given categories: each category's own plus those inherited from its
+// ancestors, where a definition closer to the category wins.
+func attributeDefinitions(ctx context.Context, q querier, categoryIDs []string) ([]models.AttributeDefinition, error) {
+	if len(categoryIDs) == 0 {
+		return nil, nil
+	}
+
+	query := `
+	SELECT c.id, a.id, a.attributes
+	FROM categories c
+	JOIN categories a ON a.id = ANY(string_to_array(trim(BOTH '/' FROM c.path), '/')::uuid[])
+	WHERE c.id = ANY($1::uuid[])
+	ORDER BY c.id, length(a.path)`
+
+	rows, err := q.Query(ctx, query, categoryIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var defs []models.AttributeDefinition
+	var current uuid.UUID
+	var effective []models.AttributeDefinition
+	flush := func() {
+		defs = append(defs, effective...)
+		effective = nil
+	}
+	for rows.Next() {
+		var categoryID, sourceID uuid.UUID
+		var own []models.AttributeDefinition
+		if err := rows.Scan(&categoryID, &sourceID, &own); err != nil {
+			return nil, err
+		}
+		if categoryID != current {
+			flush()
+			current = categoryID
+		}
+		for _, d := range own {
+			d.CategoryID = &sourceID
+			if i := indexOfAttribute(effective, d.Key); i >= 0 {
+				effective[i] = d
+				continue
+			}
+			effective = append(effective, d)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return defs, nil
+}
+
+func indexOfAttribute(defs []models.AttributeDefinition, key string) int {
+	for i, d := range defs {
+		if d.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func validateAttributes(defs []models.AttributeDefinition, attrs map[string]any) error {
+	if violations := models.ValidateAttributes(defs, attrs); len(violations) > 0 {
+		return &AttributesError{Violations: violations}
+	}
+	return nil
+}
+
+// checkProductAttributes locks the product and validates attrs against
+// its categories, so that neither can change before the write that
+// follows. A product that is gone or past ifMatch yields pgx.ErrNoRows,
+// like the write itself would.
+func checkProductAttributes(ctx context.Context, tx pgx.Tx, productID, userID string, attrs map[string]any, ifMatch []int) error {
+	query := `SELECT id FROM products
+	WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL AND ` + versionCond(3) + `
+	FOR NO KEY UPDATE`
+	var id uuid.UUID
+	if err := tx.QueryRow(ctx, query, productID, userID, ifMatch).Scan(&id); err != nil {
+		return err
+	}
+
+	var categoryIDs []string
+	err := tx.QueryRow(ctx, `SELECT ARRAY(SELECT category_id::text FROM product_categories WHERE product_id = $1)`, productID).Scan(&categoryIDs)
+	if err != nil {
+		return err
+	}
+	defs, err := attributeDefinitions(ctx, tx, categoryIDs)
+	if err != nil {
+		return err
+	}
+	return validateAttributes(defs, attrs)
+}
+
+// attributeFilterConds matches products whose attribute equals the query
+// value. A value such as "220" or "true" may be stored as a number or a
+// boolean, so each reading is tried; every alternative is a containment
+// test the GIN index on attributes can answer.
+func attributeFilterConds(args *queryArgs, filters map[string]string) []string {
+	keys := make([]string, 0, len(filters))
+	for key := range filters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	conds := make([]string, 0, len(keys))
+	for _, key := range keys {
+		value := filters[key]
+		candidates := []any{value}
+		if f, err := strconv.ParseFloat(value, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+			candidates = append(candidates, f)
+		}
+		if value == "true" || value == "false" {
+			candidates = append(candidates, value == "true")
+		}
+
+		alts := make([]string, len(candidates))
+		for i, v := range candidates {
+			alts[i] = "attributes @> " + args.add(map[string]any{key: v}) + "::jsonb"
+		}
+		conds = append(conds, "("+strings.Join(alts, " OR ")+")")
+	}
+	return conds
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"e-commerce/internal/domain/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// errBatchAborted rolls back an all-or-nothing batch after a failed step.
+var errBatchAborted = errors.New("batch aborted")
+
+// operationErrors are the failures that belong to a single operation and
+// are reported with it; anything else aborts the whole batch.
+var operationErrors = []error{ErrAlreadyExists, ErrDoesNotExist, ErrVersionMismatch, ErrUnknownField, ErrInvalidAttributes, ErrProductInBundle}
+
+func isOperationError(err error) bool {
+	for _, target := range operationErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Batch applies ops in order in one transaction. Each operation runs
+// behind a savepoint; with continueOnError a failed one is undone and the
+// rest carry on, otherwise the first failure rolls everything back. It
+// reports whether the transaction was committed.
+func (r *PgProductRepo) Batch(ctx context.Context, userID string, ops []models.ProductOperation, continueOnError bool) ([]models.ProductOperationResult, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	results := make([]models.ProductOperationResult, len(ops))
+	err := withActor(ctx, r.pool, userID, "batch", func(tx pgx.Tx) error {
+		var products []*models.Product
+		for i, op := range ops {
+			sp, err := tx.Begin(ctx)
+			if err != nil {
+				return err
+			}
+			product, err := r.applyOperation(ctx, sp, userID, op)
+			if err != nil {
+				if rbErr := sp.Rollback(ctx); rbErr != nil {
+					return rbErr
+				}
+				if !isOperationError(err) {
+					return fmt.Errorf("operation %d: %w", i, err)
+				}
+				results[i].Err = err
+				if !continueOnError {
+					return errBatchAborted
+				}
+				continue
+			}
+			if err := sp.Commit(ctx); err != nil {
+				return err
+			}
+			results[i].Product = product
+			if product != nil {
+				products = append(products, product)
+			}
+		}
+		return attachProductDetails(ctx, tx, products...)
+	})
+	if errors.Is(err, errBatchAborted) {
+		return results, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("BatchProducts: %w", err)
+	}
+	return results, true, nil
+}
+
+// applyOperation runs one batch step in tx, tagging its revision with the
+// operation's own action.
+func (r *PgProductRepo) applyOperation(ctx context.Context, tx pgx.Tx, userID string, op models.ProductOperation) (*models.Product, error) {
+	if _, err := tx.Exec(ctx, `SELECT set_config('app.revision_action', $1, true)`, op.Op); err != nil {
+		return nil, err
+	}
+
+	var query string
+	var args []any
+	var attrs map[string]any
+	switch op.Op {
+	case "create":
+		query, args = r.createStatement(op.Name, op.Price, op.Attributes, op.Tags, userID)
+	case "update":
+		query, args = updateStatement(op.ID, userID, op.Name, op.Price, op.Attributes, op.Tags, op.IfMatch)
+		attrs = op.Attributes
+	case "patch":
+		var err error
+		if query, args, err = patchStatement(op.ID, userID, op.Updates, op.IfMatch); err != nil {
+			return nil, err
+		}
+		attrs = patchedAttributes(op.Updates)
+	case "delete":
+		query, args = deleteStatement(op.ID, userID, op.IfMatch)
+	default:
+		return nil, fmt.Errorf("unknown operation %q", op.Op)
+	}
+
+	var err error
+	if attrs != nil {
+		err = checkProductAttributes(ctx, tx, op.ID, userID, attrs, op.IfMatch)
+	}
+	var product *models.Product
+	if err == nil {
+		product, err = scanProduct(tx.QueryRow(ctx, query, args...))
+	}
+	if err != nil {
+		var pgErr *pgconn.PgError
+		switch {
+		case errors.As(err, &pgErr) && pgErr.Code == "23505":
+			return nil, ErrAlreadyExists
+		case errors.Is(err, pgx.ErrNoRows):
+			return nil, missError(ctx, tx, op.ID, userID, op.IfMatch)
+		case isBundleComponentGuard(err):
+			return nil, ErrProductInBundle
+		}
+		return nil, err
+	}
+	if op.Op == "delete" {
+		return nil, nil
+	}
+	return product, nil
+}
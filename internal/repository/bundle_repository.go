@@ -0,0 +1,317 @@
+package repository
+
+import (
+	"context"
+	"e-commerce/internal/domain/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrNotBundle = errors.New("product is not a bundle")
+var ErrBundleContainsItself = errors.New("bundle can't contain itself")
+var ErrBundleComponentNotFound = errors.New("bundle component not found")
+var ErrBundleNesting = errors.New("bundles can't be nested")
+var ErrBundleVariants = errors.New("bundles and their components can't have variants")
+var ErrBundleCurrency = errors.New("bundle components must share the bundle currency")
+var ErrBundlePriceTooHigh = errors.New("bundle price must be below its components' total")
+var ErrProductInBundle = errors.New("product is a component of a bundle")
+var ErrBundleComponentTrashed = errors.New("bundle has components in the trash")
+
+// isBundleComponentGuard reports whether err is guard_bundle_component
+// refusing to trash a bundle component.
+func isBundleComponentGuard(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.ConstraintName == "product_bundle_items_component_live"
+}
+
+// isBundleRestoreGuard reports whether err is guard_bundle_component
+// refusing to restore a bundle whose components are in the trash.
+func isBundleRestoreGuard(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.ConstraintName == "product_bundle_items_bundle_live"
+}
+
+type PgBundleRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewBundleRepo(pool *pgxpool.Pool) *PgBundleRepo {
+	return &PgBundleRepo{pool: pool}
+}
+
+// queryBundles loads the bundles among productIDs with their items and
+// the availability their components' stock allows. A deleted component
+// makes a bundle unavailable.
+func queryBundles(ctx context.Context, q querier, productIDs []uuid.UUID) (map[uuid.UUID]*models.ProductBundle, error) {
+	rows, err := q.Query(ctx, `
+	SELECT b.product_id, b.discount_percent, c.id, c.name, c.price, c.currency, i.quantity,
+		CASE WHEN c.deleted_at IS NULL THEN COALESCE(s.on_hand - s.reserved, 0) ELSE 0 END
+	FROM product_bundles b
+	JOIN product_bundle_items i ON i.bundle_id = b.product_id
+	JOIN products c ON c.id = i.component_id
+	LEFT JOIN stock_levels s ON s.product_id = c.id AND s.variant_id IS NULL
+	WHERE b.product_id = ANY($1)
+	ORDER BY b.product_id, i.position`, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bundles := make(map[uuid.UUID]*models.ProductBundle)
+	for rows.Next() {
+		var id uuid.UUID
+		var discount *int
+		var item models.BundleItem
+		var available int
+		err := rows.Scan(&id, &discount, &item.ProductID, &item.Name, &item.Price.Amount, &item.Price.Currency, &item.Quantity, &available)
+		if err != nil {
+			return nil, err
+		}
+
+		fits := max(available, 0) / item.Quantity
+		bundle, ok := bundles[id]
+		if !ok {
+			bundle = &models.ProductBundle{
+				DiscountPercent: discount,
+				ComponentsTotal: &models.Money{Currency: item.Price.Currency},
+				Available:       fits,
+			}
+			bundles[id] = bundle
+		}
+		bundle.Items = append(bundle.Items, item)
+		bundle.Available = min(bundle.Available, fits)
+		if bundle.ComponentsTotal != nil {
+			if bundle.ComponentsTotal.Currency == item.Price.Currency {
+				bundle.ComponentsTotal.Amount += item.Price.Amount * int64(item.Quantity)
+			} else {
+				bundle.ComponentsTotal = nil
+			}
+		}
+	}
+	return bundles, rows.Err()
+}
+
+// attachBundles loads the bundle composition of all products in one query.
+func attachBundles(ctx context.Context, q querier, products ...*models.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, len(products))
+	for i, p := range products {
+		ids[i] = p.ID
+	}
+
+	bundles, err := queryBundles(ctx, q, ids)
+	if err != nil {
+		return fmt.Errorf("attachBundles: %w", err)
+	}
+	for _, p := range products {
+		p.Bundle = bundles[p.ID]
+	}
+	return nil
+}
+
+func (r *PgBundleRepo) Get(ctx context.Context, productID, userID string) (*models.ProductBundle, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var id uuid.UUID
+	err := r.pool.QueryRow(ctx, `SELECT id FROM products WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`, productID, userID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDoesNotExist
+		}
+		return nil, fmt.Errorf("GetProductBundle: %w", err)
+	}
+
+	bundles, err := queryBundles(ctx, r.pool, []uuid.UUID{id})
+	if err != nil {
+		return nil, fmt.Errorf("GetProductBundle: %w", err)
+	}
+	if bundles[id] == nil {
+		return nil, ErrNotBundle
+	}
+	return bundles[id], nil
+}
+
+// Set makes the product a bundle of items, replacing any earlier
+// composition. Exactly one of price and discountPercent is set: a fixed
+// price, or a discount off the components' total.
+func (r *PgBundleRepo) Set(ctx context.Context, productID, userID string, items []models.BundleItem, price *models.Money, discountPercent *int, ifMatch []int) (*models.Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	ids := make([]uuid.UUID, len(items))
+	quantities := make([]int, len(items))
+	for i, item := range items {
+		if item.ProductID.String() == productID {
+			return nil, ErrBundleContainsItself
+		}
+		ids[i] = item.ProductID
+		quantities[i] = item.Quantity
+	}
+
+	var product *models.Product
+	err := withActor(ctx, r.pool, userID, "bundle", func(tx pgx.Tx) error {
+		if _, err := lockOwnedProduct(ctx, tx, productID, userID); err != nil {
+			return err
+		}
+
+		var isComponent, hasVariants bool
+		err := tx.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM product_bundle_items WHERE component_id = $1),
+			EXISTS (SELECT 1 FROM product_variants WHERE product_id = $1)`, productID).Scan(&isComponent, &hasVariants)
+		if err != nil {
+			return err
+		}
+		if isComponent {
+			return ErrBundleNesting
+		}
+		if hasVariants {
+			return ErrBundleVariants
+		}
+
+		if err := checkBundleComponents(ctx, tx, userID, ids, quantities, price); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `
+		INSERT INTO product_bundles (product_id, discount_percent) VALUES ($1, $2)
+		ON CONFLICT (product_id) DO UPDATE SET discount_percent = EXCLUDED.discount_percent`, productID, discountPercent)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM product_bundle_items WHERE bundle_id = $1`, productID); err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `
+		INSERT INTO product_bundle_items (bundle_id, component_id, quantity, position)
+		SELECT $1, id, quantity, position - 1
+		FROM unnest($2::uuid[], $3::int[]) WITH ORDINALITY AS i(id, quantity, position)`, productID, ids, quantities)
+		if err != nil {
+			return err
+		}
+
+		// a discounted bundle gets its price from the price_discounted_bundle
+		// trigger, which setting the price to itself sets off
+		var amount *int64
+		var currency *string
+		if price != nil {
+			amount, currency = &price.Amount, &price.Currency
+		}
+		query := `
+		UPDATE products SET price = COALESCE($1, price), currency = COALESCE($2, currency)
+		WHERE id = $3 AND ` + versionCond(4) + `
+		RETURNING ` + productColumns
+		product, err = scanProduct(tx.QueryRow(ctx, query, amount, currency, productID, ifMatch))
+		return err
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		switch {
+		case errors.Is(err, ErrDoesNotExist), errors.Is(err, ErrBundleNesting), errors.Is(err, ErrBundleVariants),
+			errors.Is(err, ErrBundleComponentNotFound), errors.Is(err, ErrBundleCurrency), errors.Is(err, ErrBundlePriceTooHigh):
+			return nil, err
+		case errors.As(err, &pgErr) && pgErr.Code == "23505":
+			return nil, ErrAlreadyExists
+		case errors.Is(err, pgx.ErrNoRows):
+			return nil, missError(ctx, r.pool, productID, userID, ifMatch)
+		}
+		return nil, fmt.Errorf("SetProductBundle: %w", err)
+	}
+	if err := attachProductDetails(ctx, r.pool, product); err != nil {
+		return nil, fmt.Errorf("SetProductBundle: %w", err)
+	}
+	return product, nil
+}
+
+// checkBundleComponents checks that the components are the seller's live
+// products, neither bundles nor with variants, and priced in one currency
+// - price's, if the bundle has a fixed price, which must then be below
+// their total. It locks them against going to the trash.
+func checkBundleComponents(ctx context.Context, tx pgx.Tx, userID string, ids []uuid.UUID, quantities []int, price *models.Money) error {
+	rows, err := tx.Query(ctx, `
+	SELECT c.currency, c.price * i.quantity,
+		EXISTS (SELECT 1 FROM product_bundles WHERE product_id = c.id),
+		EXISTS (SELECT 1 FROM product_variants WHERE product_id = c.id)
+	FROM products c
+	JOIN unnest($1::uuid[], $2::int[]) AS i(id, quantity) ON i.id = c.id
+	WHERE c.user_id = $3 AND c.deleted_at IS NULL
+	FOR SHARE OF c`, ids, quantities, userID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	found := 0
+	var total models.Money
+	if price != nil {
+		total.Currency = price.Currency
+	}
+	for rows.Next() {
+		var currency string
+		var lineTotal int64
+		var isBundle, hasVariants bool
+		if err := rows.Scan(&currency, &lineTotal, &isBundle, &hasVariants); err != nil {
+			return err
+		}
+		switch {
+		case isBundle:
+			return ErrBundleNesting
+		case hasVariants:
+			return ErrBundleVariants
+		}
+		if total.Currency == "" {
+			total.Currency = currency
+		}
+		if currency != total.Currency {
+			return ErrBundleCurrency
+		}
+		total.Amount += lineTotal
+		found++
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if found < len(ids) {
+		return ErrBundleComponentNotFound
+	}
+	if price != nil && price.Amount >= total.Amount {
+		return ErrBundlePriceTooHigh
+	}
+	return nil
+}
+
+// Remove turns a bundle back into a plain product, keeping its current
+// price.
+func (r *PgBundleRepo) Remove(ctx context.Context, productID, userID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tag, err := r.pool.Exec(ctx, `
+	DELETE FROM product_bundles
+	WHERE product_id = (SELECT id FROM products WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL)`, productID, userID)
+	if err != nil {
+		return fmt.Errorf("RemoveProductBundle: %w", err)
+	}
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+
+	var exists bool
+	err = r.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM products WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL)`,
+		productID, userID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("RemoveProductBundle: %w", err)
+	}
+	if !exists {
+		return ErrDoesNotExist
+	}
+	return ErrNotBundle
+}
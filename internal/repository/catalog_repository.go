@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"e-commerce/internal/domain/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PgCatalogRepo struct {
+	pool       *pgxpool.Pool
+	searchLang string
+}
+
+func NewCatalogRepo(pool *pgxpool.Pool, searchLang string) *PgCatalogRepo {
+	return &PgCatalogRepo{pool: pool, searchLang: searchLang}
+}
+
+func (r *PgCatalogRepo) GetByID(ctx context.Context, id string) (*models.Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + productColumns + ` FROM products WHERE id = $1 AND deleted_at IS NULL AND status = 'published'`
+
+	product, err := scanProduct(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDoesNotExist
+		}
+		return nil, fmt.Errorf("GetCatalogProduct: %w", err)
+	}
+	if err := attachProductDetails(ctx, r.pool, product); err != nil {
+		return nil, fmt.Errorf("GetCatalogProduct: %w", err)
+	}
+	if err := attachLowestPrices(ctx, r.pool, product); err != nil {
+		return nil, fmt.Errorf("GetCatalogProduct: %w", err)
+	}
+
+	return product, nil
+}
+
+func (r *PgCatalogRepo) GetBySlug(ctx context.Context, slug string) (*models.Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	product, err := productBySlug(ctx, r.pool, []string{"status = 'published'"}, nil, slug)
+	if err != nil {
+		if errors.Is(err, ErrDoesNotExist) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("GetCatalogProductBySlug: %w", err)
+	}
+	if err := attachLowestPrices(ctx, r.pool, product); err != nil {
+		return nil, fmt.Errorf("GetCatalogProductBySlug: %w", err)
+	}
+	return product, nil
+}
+
+func (r *PgCatalogRepo) List(ctx context.Context, sellerID string, params models.ProductListParams) (*models.ProductPage, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var args queryArgs
+	var conds []string
+	if sellerID != "" {
+		conds = append(conds, "user_id = "+args.add(sellerID))
+	}
+	params.Status = models.ProductPublished
+
+	page, err := listProducts(ctx, r.pool, conds, args, params)
+	if err != nil {
+		return nil, fmt.Errorf("ListCatalogProducts: %w", err)
+	}
+	if err := attachPageLowestPrices(ctx, r.pool, page); err != nil {
+		return nil, fmt.Errorf("ListCatalogProducts: %w", err)
+	}
+	return page, nil
+}
+
+func (r *PgCatalogRepo) Search(ctx context.Context, params models.ProductSearchParams) (*models.ProductSearchPage, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	page, err := searchProducts(ctx, r.pool, r.searchLang, []string{"status = 'published'"}, nil, params)
+	if err != nil {
+		return nil, fmt.Errorf("SearchCatalogProducts: %w", err)
+	}
+	items := make([]*models.Product, len(page.Items))
+	for i := range page.Items {
+		items[i] = &page.Items[i].Product
+	}
+	if err := attachLowestPrices(ctx, r.pool, items...); err != nil {
+		return nil, fmt.Errorf("SearchCatalogProducts: %w", err)
+	}
+	return page, nil
+}
+
+func (r *PgCatalogRepo) ListByCategory(ctx context.Context, categoryID string, params models.ProductListParams) (*models.ProductPage, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var path string
+	err := r.pool.QueryRow(ctx, `SELECT path FROM categories WHERE id = $1`, categoryID).Scan(&path)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, fmt.Errorf("ListCatalogCategoryProducts: %w", err)
+	}
+
+	var args queryArgs
+	conds := []string{categorySubtreeCond(&args, path)}
+	params.Status = models.ProductPublished
+
+	page, err := listProducts(ctx, r.pool, conds, args, params)
+	if err != nil {
+		return nil, fmt.Errorf("ListCatalogCategoryProducts: %w", err)
+	}
+	if err := attachPageLowestPrices(ctx, r.pool, page); err != nil {
+		return nil, fmt.Errorf("ListCatalogCategoryProducts: %w", err)
+	}
+	return page, nil
+}
+
+func attachPageLowestPrices(ctx context.Context, q querier, page *models.ProductPage) error {
+	items := make([]*models.Product, len(page.Items))
+	for i := range page.Items {
+		items[i] = &page.Items[i]
+	}
+	return attachLowestPrices(ctx, q, items...)
+}
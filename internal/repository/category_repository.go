@@ -0,0 +1,455 @@
+package repository
+
+import (
+	"context"
+	"e-commerce/internal/domain/models"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrCategoryNotFound = errors.New("category not found")
+var ErrCategoryAlreadyExists = errors.New("category with this name already exists under the same parent")
+var ErrCategoryCycle = errors.New("category cannot be moved under itself or its descendants")
+var ErrCategoryHasChildren = errors.New("category has subcategories")
+
+const categoryColumns = "id, user_id, parent_id, name, path, created_at, updated_at, attributes"
+
+type PgCategoryRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewCategoryRepo(pool *pgxpool.Pool) *PgCategoryRepo {
+	return &PgCategoryRepo{pool: pool}
+}
+
+func scanCategory(row pgx.Row) (*models.Category, error) {
+	var category models.Category
+	err := row.Scan(
+		&category.ID,
+		&category.UserID,
+		&category.ParentID,
+		&category.Name,
+		&category.Path,
+		&category.CreatedAt,
+		&category.UpdatedAt,
+		&category.Attributes,
+	)
+	if err != nil {
+		return nil, err
+	}
+	category.Depth = strings.Count(category.Path, "/") - 2
+	return &category, nil
+}
+
+func collectCategories(rows pgx.Rows) ([]models.Category, error) {
+	defer rows.Close()
+
+	categories := make([]models.Category, 0)
+	for rows.Next() {
+		category, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, *category)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// lockCategoryTree serializes structural changes to one seller's tree so
+// that concurrent moves can't combine into a cycle.
+func lockCategoryTree(ctx context.Context, tx pgx.Tx, userID string) error {
+	_, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('categories:' || $1))`, userID)
+	return err
+}
+
+func categoryPath(ctx context.Context, tx pgx.Tx, id, userID string) (string, error) {
+	var path string
+	err := tx.QueryRow(ctx, `SELECT path FROM categories WHERE id = $1 AND user_id = $2`, id, userID).Scan(&path)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrCategoryNotFound
+	}
+	return path, err
+}
+
+func (r *PgCategoryRepo) Create(ctx context.Context, userID, name string, parentID *string) (*models.Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("CreateCategory: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockCategoryTree(ctx, tx, userID); err != nil {
+		return nil, fmt.Errorf("CreateCategory: %w", err)
+	}
+
+	id := uuid.New().String()
+	path := "/" + id + "/"
+	if parentID != nil {
+		parentPath, err := categoryPath(ctx, tx, *parentID, userID)
+		if err != nil {
+			if errors.Is(err, ErrCategoryNotFound) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("CreateCategory: %w", err)
+		}
+		path = parentPath + id + "/"
+	}
+
+	query := `
+		INSERT INTO categories (id, user_id, parent_id, name, path)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + categoryColumns
+
+	category, err := scanCategory(tx.QueryRow(ctx, query, id, userID, parentID, name, path))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, ErrCategoryAlreadyExists
+		}
+		return nil, fmt.Errorf("CreateCategory: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("CreateCategory: %w", err)
+	}
+	return category, nil
+}
+
+func (r *PgCategoryRepo) GetByID(ctx context.Context, id, userID string) (*models.Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + categoryColumns + ` FROM categories WHERE id = $1 AND user_id = $2`
+
+	category, err := scanCategory(r.pool.QueryRow(ctx, query, id, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, fmt.Errorf("GetCategoryById: %w", err)
+	}
+	return category, nil
+}
+
+// GetAll returns the seller's categories in depth-first order.
+func (r *PgCategoryRepo) GetAll(ctx context.Context, userID string) ([]models.Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + categoryColumns + ` FROM categories WHERE user_id = $1 ORDER BY path`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("GetAllCategories: %w", err)
+	}
+	categories, err := collectCategories(rows)
+	if err != nil {
+		return nil, fmt.Errorf("GetAllCategories: %w", err)
+	}
+	return categories, nil
+}
+
+// Update renames a category and, if parentID changed, moves it together
+// with its whole subtree. A nil parentID makes the category a root.
+func (r *PgCategoryRepo) Update(ctx context.Context, id, userID, name string, parentID *string) (*models.Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("UpdateCategory: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockCategoryTree(ctx, tx, userID); err != nil {
+		return nil, fmt.Errorf("UpdateCategory: %w", err)
+	}
+
+	oldPath, err := categoryPath(ctx, tx, id, userID)
+	if err != nil {
+		if errors.Is(err, ErrCategoryNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("UpdateCategory: %w", err)
+	}
+
+	newPath := "/" + id + "/"
+	if parentID != nil {
+		parentPath, err := categoryPath(ctx, tx, *parentID, userID)
+		if err != nil {
+			if errors.Is(err, ErrCategoryNotFound) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("UpdateCategory: %w", err)
+		}
+		if strings.HasPrefix(parentPath, oldPath) {
+			return nil, ErrCategoryCycle
+		}
+		newPath = parentPath + id + "/"
+	}
+
+	query := `
+	UPDATE categories
+	SET name = $1, parent_id = $2, path = $3
+	WHERE id = $4 AND user_id = $5
+	RETURNING ` + categoryColumns
+
+	category, err := scanCategory(tx.QueryRow(ctx, query, name, parentID, newPath, id, userID))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, ErrCategoryAlreadyExists
+		}
+		return nil, fmt.Errorf("UpdateCategory: %w", err)
+	}
+
+	if newPath != oldPath {
+		_, err = tx.Exec(ctx, `
+		UPDATE categories
+		SET path = $1 || substr(path, length($2) + 1)
+		WHERE user_id = $3 AND path LIKE $4 AND id <> $5`,
+			newPath, oldPath, userID, prefixPattern(oldPath), id)
+		if err != nil {
+			return nil, fmt.Errorf("UpdateCategory: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("UpdateCategory: %w", err)
+	}
+	return category, nil
+}
+
+// Delete removes a category. Categories with children are rejected unless
+// cascade is set, in which case the whole subtree goes.
+func (r *PgCategoryRepo) Delete(ctx context.Context, id, userID string, cascade bool) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("DeleteCategory: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockCategoryTree(ctx, tx, userID); err != nil {
+		return fmt.Errorf("DeleteCategory: %w", err)
+	}
+
+	path, err := categoryPath(ctx, tx, id, userID)
+	if err != nil {
+		if errors.Is(err, ErrCategoryNotFound) {
+			return err
+		}
+		return fmt.Errorf("DeleteCategory: %w", err)
+	}
+
+	if !cascade {
+		var hasChildren bool
+		err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM categories WHERE parent_id = $1)`, id).Scan(&hasChildren)
+		if err != nil {
+			return fmt.Errorf("DeleteCategory: %w", err)
+		}
+		if hasChildren {
+			return ErrCategoryHasChildren
+		}
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM categories WHERE user_id = $1 AND path LIKE $2`, userID, prefixPattern(path))
+	if err != nil {
+		return fmt.Errorf("DeleteCategory: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("DeleteCategory: %w", err)
+	}
+	return nil
+}
+
+// SetAttributes replaces the category's own attribute definitions. They
+// apply to products written afterwards; existing products are not
+// rechecked.
+func (r *PgCategoryRepo) SetAttributes(ctx context.Context, id, userID string, defs []models.AttributeDefinition) (*models.Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// the column is NOT NULL, and a nil slice would encode as null
+	if defs == nil {
+		defs = []models.AttributeDefinition{}
+	}
+
+	query := `UPDATE categories SET attributes = $1 WHERE id = $2 AND user_id = $3 RETURNING ` + categoryColumns
+
+	category, err := scanCategory(r.pool.QueryRow(ctx, query, defs, id, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, fmt.Errorf("SetCategoryAttributes: %w", err)
+	}
+	return category, nil
+}
+
+// GetAttributes returns the definitions that apply to products in the
+// category, inherited ones included, each naming the category it comes from.
+func (r *PgCategoryRepo) GetAttributes(ctx context.Context, id, userID string) ([]models.AttributeDefinition, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if _, err := r.GetByID(ctx, id, userID); err != nil {
+		return nil, err
+	}
+	defs, err := attributeDefinitions(ctx, r.pool, []string{id})
+	if err != nil {
+		return nil, fmt.Errorf("GetCategoryAttributes: %w", err)
+	}
+	if defs == nil {
+		defs = []models.AttributeDefinition{}
+	}
+	return defs, nil
+}
+
+// SetProductCategories replaces the product's category assignments.
+func (r *PgCategoryRepo) SetProductCategories(ctx context.Context, productID, userID string, categoryIDs []string) ([]models.Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("SetProductCategories: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// the lock keeps the attributes from changing until we commit
+	var attrs map[string]any
+	err = tx.QueryRow(ctx, `SELECT attributes FROM products WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL FOR NO KEY UPDATE`, productID, userID).Scan(&attrs)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDoesNotExist
+		}
+		return nil, fmt.Errorf("SetProductCategories: %w", err)
+	}
+
+	// FOR SHARE keeps the categories from being deleted until we commit
+	var found int
+	err = tx.QueryRow(ctx, `
+	SELECT count(*) FROM (
+		SELECT id FROM categories WHERE id = ANY($1::uuid[]) AND user_id = $2 FOR SHARE
+	) c`, categoryIDs, userID).Scan(&found)
+	if err != nil {
+		return nil, fmt.Errorf("SetProductCategories: %w", err)
+	}
+	if found != len(categoryIDs) {
+		return nil, ErrCategoryNotFound
+	}
+
+	defs, err := attributeDefinitions(ctx, tx, categoryIDs)
+	if err != nil {
+		return nil, fmt.Errorf("SetProductCategories: %w", err)
+	}
+	if err := validateAttributes(defs, attrs); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM product_categories WHERE product_id = $1`, productID); err != nil {
+		return nil, fmt.Errorf("SetProductCategories: %w", err)
+	}
+	_, err = tx.Exec(ctx, `
+	INSERT INTO product_categories (product_id, category_id)
+	SELECT $1, unnest($2::uuid[])`, productID, categoryIDs)
+	if err != nil {
+		return nil, fmt.Errorf("SetProductCategories: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, `SELECT `+categoryColumns+` FROM categories WHERE id = ANY($1::uuid[]) ORDER BY path`, categoryIDs)
+	if err != nil {
+		return nil, fmt.Errorf("SetProductCategories: %w", err)
+	}
+	categories, err := collectCategories(rows)
+	if err != nil {
+		return nil, fmt.Errorf("SetProductCategories: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("SetProductCategories: %w", err)
+	}
+	return categories, nil
+}
+
+func (r *PgCategoryRepo) GetProductCategories(ctx context.Context, productID, userID string) ([]models.Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var exists bool
+	err := r.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM products WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL)`, productID, userID).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("GetProductCategories: %w", err)
+	}
+	if !exists {
+		return nil, ErrDoesNotExist
+	}
+
+	query := `
+	SELECT ` + categoryColumns + `
+	FROM categories
+	WHERE id IN (SELECT category_id FROM product_categories WHERE product_id = $1)
+	ORDER BY path`
+
+	rows, err := r.pool.Query(ctx, query, productID)
+	if err != nil {
+		return nil, fmt.Errorf("GetProductCategories: %w", err)
+	}
+	categories, err := collectCategories(rows)
+	if err != nil {
+		return nil, fmt.Errorf("GetProductCategories: %w", err)
+	}
+	return categories, nil
+}
+
+// ListProducts lists the seller's products assigned to the category or any
+// of its descendants.
+func (r *PgCategoryRepo) ListProducts(ctx context.Context, categoryID, userID string, params models.ProductListParams) (*models.ProductPage, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var path string
+	err := r.pool.QueryRow(ctx, `SELECT path FROM categories WHERE id = $1 AND user_id = $2`, categoryID, userID).Scan(&path)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, fmt.Errorf("ListCategoryProducts: %w", err)
+	}
+
+	var args queryArgs
+	conds := []string{
+		"user_id = " + args.add(userID),
+		categorySubtreeCond(&args, path),
+	}
+
+	page, err := listProducts(ctx, r.pool, conds, args, params)
+	if err != nil {
+		return nil, fmt.Errorf("ListCategoryProducts: %w", err)
+	}
+	return page, nil
+}
+
+func categorySubtreeCond(args *queryArgs, path string) string {
+	return `id IN (
+		SELECT pc.product_id FROM product_categories pc
+		JOIN categories c ON c.id = pc.category_id
+		WHERE c.path LIKE ` + args.add(prefixPattern(path)) + `)`
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"e-commerce/internal/domain/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrNotPurchased = errors.New("product has not been purchased")
+var ErrDownloadNotFound = errors.New("download link not found")
+var ErrDownloadExpired = errors.New("download link has expired")
+var ErrDownloadLimitReached = errors.New("download link has been used up")
+
+type PgDownloadRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewDownloadRepo(pool *pgxpool.Pool) *PgDownloadRepo {
+	return &PgDownloadRepo{pool: pool}
+}
+
+// CreateLinks issues the user a link to each file of a digital product
+// they bought or sell. Until there are orders, a purchase is a committed
+// reservation of the product or of a bundle containing it. A bought product stays downloadable after
+// it is unpublished or trashed, up until it is purged.
+func (r *PgDownloadRepo) CreateLinks(ctx context.Context, productID, userID string, maxDownloads int, expiresAt time.Time) ([]models.DownloadLink, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var productType string
+	var owner, purchased, listed bool
+	err := r.pool.QueryRow(ctx, `
+	SELECT p.type, p.user_id = $2, p.status = 'published' AND p.deleted_at IS NULL,
+		EXISTS (
+			SELECT 1 FROM stock_reservations r
+			JOIN stock_reservation_items i ON i.reservation_id = r.id
+			JOIN stock_levels s ON s.id IN (r.stock_id, i.stock_id)
+			WHERE s.product_id = p.id AND r.user_id = $2 AND r.status = 'committed'
+		)
+	FROM products p WHERE p.id = $1`, productID, userID).Scan(&productType, &owner, &listed, &purchased)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDoesNotExist
+		}
+		return nil, fmt.Errorf("CreateDownloadLinks: %w", err)
+	}
+	switch {
+	case !owner && !purchased && !listed:
+		return nil, ErrDoesNotExist
+	case productType != models.ProductDigital:
+		return nil, ErrNotDigital
+	case !owner && !purchased:
+		return nil, ErrNotPurchased
+	}
+
+	rows, err := r.pool.Query(ctx, `
+	WITH l AS (
+		INSERT INTO download_links (file_id, user_id, max_downloads, expires_at)
+		SELECT id, $2, $3, $4 FROM product_files WHERE product_id = $1
+		RETURNING id, file_id, max_downloads, expires_at
+	)
+	SELECT l.id, l.file_id, f.name, f.size_bytes, l.max_downloads, l.expires_at
+	FROM l JOIN product_files f ON f.id = l.file_id
+	ORDER BY f.created_at, f.id`, productID, userID, maxDownloads, expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("CreateDownloadLinks: %w", err)
+	}
+	defer rows.Close()
+
+	links := []models.DownloadLink{}
+	for rows.Next() {
+		var link models.DownloadLink
+		err := rows.Scan(&link.ID, &link.FileID, &link.Name, &link.SizeBytes, &link.MaxDownloads, &link.ExpiresAt)
+		if err != nil {
+			return nil, fmt.Errorf("CreateDownloadLinks: %w", err)
+		}
+		links = append(links, link)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("CreateDownloadLinks: %w", err)
+	}
+	return links, nil
+}
+
+// linkMissError explains why a link matched no usable row: it doesn't
+// exist, has expired or has been used up.
+func linkMissError(ctx context.Context, q querier, linkID uuid.UUID) error {
+	var expired bool
+	err := q.QueryRow(ctx, `SELECT expires_at <= NOW() FROM download_links WHERE id = $1`, linkID).Scan(&expired)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return ErrDownloadNotFound
+	case err != nil:
+		return fmt.Errorf("check download link: %w", err)
+	case expired:
+		return ErrDownloadExpired
+	}
+	return ErrDownloadLimitReached
+}
+
+// File returns the file a usable link points to, without counting a
+// download.
+func (r *PgDownloadRepo) File(ctx context.Context, linkID uuid.UUID) (*models.ProductFile, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	file, err := scanFile(r.pool.QueryRow(ctx, `
+	SELECT `+fileColumns+` FROM product_files
+	WHERE id = (
+		SELECT file_id FROM download_links
+		WHERE id = $1 AND downloads < max_downloads AND expires_at > NOW()
+	)`, linkID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, linkMissError(ctx, r.pool, linkID)
+		}
+		return nil, fmt.Errorf("GetDownloadFile: %w", err)
+	}
+	return file, nil
+}
+
+// Use counts a download through the link. The conditional update keeps
+// concurrent downloads within the limit.
+func (r *PgDownloadRepo) Use(ctx context.Context, linkID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tag, err := r.pool.Exec(ctx, `
+	UPDATE download_links SET downloads = downloads + 1
+	WHERE id = $1 AND downloads < max_downloads AND expires_at > NOW()`, linkID)
+	if err != nil {
+		return fmt.Errorf("UseDownloadLink: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return linkMissError(ctx, r.pool, linkID)
+	}
+	return nil
+}
+
+// DeleteExpired removes links past their expiry and returns how many.
+func (r *PgDownloadRepo) DeleteExpired(ctx context.Context) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	tag, err := r.pool.Exec(ctx, `DELETE FROM download_links WHERE expires_at <= NOW()`)
+	if err != nil {
+		return 0, fmt.Errorf("DeleteExpiredDownloadLinks: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"e-commerce/internal/domain/models"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PgExchangeRateRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewExchangeRateRepo(pool *pgxpool.Pool) *PgExchangeRateRepo {
+	return &PgExchangeRateRepo{pool: pool}
+}
+
+func (r *PgExchangeRateRepo) GetAll(ctx context.Context) ([]models.ExchangeRate, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+	SELECT base_currency, quote_currency, rate::text, as_of, source, updated_at
+	FROM exchange_rates
+	ORDER BY base_currency, quote_currency`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("GetAll exchange rates: %w", err)
+	}
+	defer rows.Close()
+
+	rates := []models.ExchangeRate{}
+	for rows.Next() {
+		var rate models.ExchangeRate
+		err := rows.Scan(&rate.Base, &rate.Quote, &rate.Rate, &rate.AsOf, &rate.Source, &rate.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("GetAll exchange rates scan: %w", err)
+		}
+		rates = append(rates, rate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("GetAll exchange rates rows: %w", err)
+	}
+	return rates, nil
+}
+
+// Upsert stores rates in one transaction. A stored rate is only replaced
+// by one that is at least as recent, so re-importing an old file is a
+// no-op. It returns the number of rows written.
+func (r *PgExchangeRateRepo) Upsert(ctx context.Context, rates []models.ExchangeRate) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("Upsert exchange rates begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+	INSERT INTO exchange_rates (base_currency, quote_currency, rate, as_of, source)
+	VALUES ($1, $2, $3::numeric, $4, $5)
+	ON CONFLICT (base_currency, quote_currency) DO UPDATE
+	SET rate = EXCLUDED.rate, as_of = EXCLUDED.as_of, source = EXCLUDED.source
+	WHERE exchange_rates.as_of <= EXCLUDED.as_of`
+
+	batch := &pgx.Batch{}
+	for _, rate := range rates {
+		batch.Queue(query, rate.Base, rate.Quote, rate.Rate, rate.AsOf, rate.Source)
+	}
+	results := tx.SendBatch(ctx, batch)
+	written := 0
+	for range rates {
+		tag, err := results.Exec()
+		if err != nil {
+			results.Close()
+			return 0, fmt.Errorf("Upsert exchange rates: %w", err)
+		}
+		written += int(tag.RowsAffected())
+	}
+	if err := results.Close(); err != nil {
+		return 0, fmt.Errorf("Upsert exchange rates: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("Upsert exchange rates commit: %w", err)
+	}
+	return written, nil
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"e-commerce/internal/domain/models"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// productFacets counts the products matching conds by tag, price bucket
+// and category in one round trip. Prices are bucketed per currency, since
+// amounts in different currencies don't compare.
+func productFacets(ctx context.Context, q querier, conds []string, args queryArgs) (*models.ProductFacets, error) {
+	args = slices.Clone(args)
+
+	currencies := models.Currencies()
+	scales := make([]int64, len(currencies))
+	for i, code := range currencies {
+		exp, _ := models.CurrencyExponent(code)
+		scales[i] = int64(math.Pow10(exp))
+	}
+	boundsArg := args.add(models.PriceBucketBounds)
+	currenciesArg := args.add(currencies)
+	scalesArg := args.add(scales)
+
+	query := fmt.Sprintf(`
+	WITH matched AS MATERIALIZED (
+		SELECT id, price, currency, tags FROM products %s
+	), tag_counts AS (
+		SELECT t, count(*) AS n FROM matched, unnest(tags) AS t
+		GROUP BY t
+		ORDER BY n DESC, t
+		LIMIT %d
+	)
+	SELECT 'tag', t, NULL, n FROM tag_counts
+	UNION ALL
+	SELECT 'price', m.currency, width_bucket(m.price::numeric / s.scale, %s::numeric[])::text, count(*)
+	FROM matched m
+	JOIN unnest(%s::text[], %s::bigint[]) AS s(currency, scale) ON s.currency = m.currency
+	GROUP BY 2, 3
+	UNION ALL
+	SELECT 'category', c.id::text, c.name, count(DISTINCT m.id)
+	FROM matched m
+	JOIN product_categories pc ON pc.product_id = m.id
+	JOIN categories c ON c.id = pc.category_id
+	GROUP BY c.id, c.name`,
+		whereClause(conds), models.MaxTagFacets, boundsArg, currenciesArg, scalesArg)
+
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	facets := &models.ProductFacets{
+		Tags:       []models.TagFacet{},
+		Prices:     []models.PriceFacet{},
+		Categories: []models.CategoryFacet{},
+	}
+	scaleOf := make(map[string]int64, len(currencies))
+	for i, code := range currencies {
+		scaleOf[code] = scales[i]
+	}
+
+	for rows.Next() {
+		var kind, key string
+		var label *string
+		var count int
+		if err := rows.Scan(&kind, &key, &label, &count); err != nil {
+			return nil, err
+		}
+		switch kind {
+		case "tag":
+			facets.Tags = append(facets.Tags, models.TagFacet{Tag: key, Count: count})
+		case "price":
+			bucket, err := strconv.Atoi(*label)
+			if err != nil {
+				return nil, err
+			}
+			facets.Prices = append(facets.Prices, priceFacet(key, scaleOf[key], bucket, count))
+		case "category":
+			id, err := uuid.Parse(key)
+			if err != nil {
+				return nil, err
+			}
+			facets.Categories = append(facets.Categories, models.CategoryFacet{ID: id, Name: *label, Count: count})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(facets.Tags, func(i, j int) bool {
+		a, b := facets.Tags[i], facets.Tags[j]
+		return a.Count > b.Count || a.Count == b.Count && a.Tag < b.Tag
+	})
+	sort.Slice(facets.Prices, func(i, j int) bool {
+		a, b := facets.Prices[i], facets.Prices[j]
+		if a.Currency != b.Currency {
+			return a.Currency < b.Currency
+		}
+		return a.Max != nil && (b.Max == nil || a.Max.Amount < b.Max.Amount)
+	})
+	sort.Slice(facets.Categories, func(i, j int) bool {
+		a, b := facets.Categories[i], facets.Categories[j]
+		return a.Count > b.Count || a.Count == b.Count && a.Name < b.Name
+	})
+	return facets, nil
+}
+
+// priceFacet describes bucket i of width_bucket over PriceBucketBounds:
+// 0 is below the first bound and len(bounds) is at or above the last.
+func priceFacet(currency string, scale int64, i, count int) models.PriceFacet {
+	bounds := models.PriceBucketBounds
+	facet := models.PriceFacet{Currency: currency, Count: count}
+	if i > 0 {
+		facet.Min = &models.Money{Amount: bounds[i-1] * scale, Currency: currency}
+	}
+	if i < len(bounds) {
+		facet.Max = &models.Money{Amount: bounds[i] * scale, Currency: currency}
+	}
+	return facet
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"e-commerce/internal/domain/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrFileNotFound = errors.New("file not found")
+var ErrTooManyFiles = errors.New("product has too many files")
+var ErrNotDigital = errors.New("product is not digital")
+
+const fileColumns = "id, product_id, name, blob_key, content_type, size_bytes, created_at"
+
+type PgFileRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewFileRepo(pool *pgxpool.Pool) *PgFileRepo {
+	return &PgFileRepo{pool: pool}
+}
+
+func scanFile(row pgx.Row) (*models.ProductFile, error) {
+	var file models.ProductFile
+	err := row.Scan(
+		&file.ID,
+		&file.ProductID,
+		&file.Name,
+		&file.BlobKey,
+		&file.ContentType,
+		&file.SizeBytes,
+		&file.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+func (r *PgFileRepo) GetByProduct(ctx context.Context, productID, userID string) ([]models.ProductFile, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var id uuid.UUID
+	err := r.pool.QueryRow(ctx, `SELECT id FROM products WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`, productID, userID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDoesNotExist
+		}
+		return nil, fmt.Errorf("GetProductFiles: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, `SELECT `+fileColumns+` FROM product_files WHERE product_id = $1 ORDER BY created_at, id`, id)
+	if err != nil {
+		return nil, fmt.Errorf("GetProductFiles: %w", err)
+	}
+	defer rows.Close()
+
+	files := []models.ProductFile{}
+	for rows.Next() {
+		file, err := scanFile(rows)
+		if err != nil {
+			return nil, fmt.Errorf("GetProductFiles: %w", err)
+		}
+		files = append(files, *file)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("GetProductFiles: %w", err)
+	}
+	return files, nil
+}
+
+// Create adds file to a digital product.
+func (r *PgFileRepo) Create(ctx context.Context, userID string, file *models.ProductFile) (*models.ProductFile, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("CreateFile begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := lockOwnedProduct(ctx, tx, file.ProductID.String(), userID); err != nil {
+		return nil, err
+	}
+
+	var productType string
+	var count int
+	err = tx.QueryRow(ctx, `
+	SELECT type, (SELECT count(*) FROM product_files WHERE product_id = $1)
+	FROM products WHERE id = $1`, file.ProductID).Scan(&productType, &count)
+	if err != nil {
+		return nil, fmt.Errorf("CreateFile: %w", err)
+	}
+	if productType != models.ProductDigital {
+		return nil, ErrNotDigital
+	}
+	if count >= models.MaxProductFiles {
+		return nil, ErrTooManyFiles
+	}
+
+	query := `
+	INSERT INTO product_files (id, product_id, name, blob_key, content_type, size_bytes)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	RETURNING ` + fileColumns
+
+	created, err := scanFile(tx.QueryRow(ctx, query,
+		file.ID,
+		file.ProductID,
+		file.Name,
+		file.BlobKey,
+		file.ContentType,
+		file.SizeBytes,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("CreateFile: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("CreateFile commit: %w", err)
+	}
+	return created, nil
+}
+
+// Delete removes the file row, and with it the file's download links, and
+// returns it so the caller can delete its blob.
+func (r *PgFileRepo) Delete(ctx context.Context, productID, fileID, userID string) (*models.ProductFile, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+	DELETE FROM product_files f
+	USING products p
+	WHERE f.id = $1 AND f.product_id = $2 AND p.id = f.product_id AND p.user_id = $3 AND p.deleted_at IS NULL
+	RETURNING f.id, f.product_id, f.name, f.blob_key, f.content_type, f.size_bytes, f.created_at`
+
+	file, err := scanFile(r.pool.QueryRow(ctx, query, fileID, productID, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrFileNotFound
+		}
+		return nil, fmt.Errorf("DeleteFile: %w", err)
+	}
+	return file, nil
+}
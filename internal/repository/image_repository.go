@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"context"
+	"e-commerce/internal/domain/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrImageNotFound = errors.New("image not found")
+var ErrTooManyImages = errors.New("product has too many images")
+var ErrInvalidImageOrder = errors.New("image order must list every image of the product exactly once")
+
+const imageColumns = "id, product_id, position, original_key, thumbnail_key, url, thumbnail_url, content_type, size_bytes, width, height, created_at"
+
+type PgImageRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewImageRepo(pool *pgxpool.Pool) *PgImageRepo {
+	return &PgImageRepo{pool: pool}
+}
+
+func scanImage(row pgx.Row) (*models.ProductImage, error) {
+	var image models.ProductImage
+	err := row.Scan(
+		&image.ID,
+		&image.ProductID,
+		&image.Position,
+		&image.OriginalKey,
+		&image.ThumbnailKey,
+		&image.URL,
+		&image.ThumbnailURL,
+		&image.ContentType,
+		&image.SizeBytes,
+		&image.Width,
+		&image.Height,
+		&image.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &image, nil
+}
+
+func queryImages(ctx context.Context, q querier, productIDs []uuid.UUID) (map[uuid.UUID][]models.ProductImage, error) {
+	rows, err := q.Query(ctx, `
+	SELECT `+imageColumns+`
+	FROM product_images
+	WHERE product_id = ANY($1)
+	ORDER BY product_id, position, created_at`, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	images := make(map[uuid.UUID][]models.ProductImage)
+	for rows.Next() {
+		image, err := scanImage(rows)
+		if err != nil {
+			return nil, err
+		}
+		images[image.ProductID] = append(images[image.ProductID], *image)
+	}
+	return images, rows.Err()
+}
+
+// attachImages loads the images of all products in one query.
+func attachImages(ctx context.Context, q querier, products ...*models.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, len(products))
+	for i, p := range products {
+		ids[i] = p.ID
+	}
+
+	images, err := queryImages(ctx, q, ids)
+	if err != nil {
+		return fmt.Errorf("attachImages: %w", err)
+	}
+	for _, p := range products {
+		p.Images = images[p.ID]
+	}
+	return nil
+}
+
+func (r *PgImageRepo) GetByProduct(ctx context.Context, productID, userID string) ([]models.ProductImage, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var id uuid.UUID
+	err := r.pool.QueryRow(ctx, `SELECT id FROM products WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`, productID, userID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDoesNotExist
+		}
+		return nil, fmt.Errorf("GetProductImages: %w", err)
+	}
+
+	images, err := queryImages(ctx, r.pool, []uuid.UUID{id})
+	if err != nil {
+		return nil, fmt.Errorf("GetProductImages: %w", err)
+	}
+	if images[id] == nil {
+		return []models.ProductImage{}, nil
+	}
+	return images[id], nil
+}
+
+// Create appends image to the end of the product's gallery.
+func (r *PgImageRepo) Create(ctx context.Context, userID string, image *models.ProductImage) (*models.ProductImage, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("CreateImage begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := lockOwnedProduct(ctx, tx, image.ProductID.String(), userID); err != nil {
+		return nil, err
+	}
+
+	var count, lastPosition int
+	err = tx.QueryRow(ctx, `
+	SELECT count(*), COALESCE(max(position), 0)
+	FROM product_images
+	WHERE product_id = $1`, image.ProductID).Scan(&count, &lastPosition)
+	if err != nil {
+		return nil, fmt.Errorf("CreateImage: %w", err)
+	}
+	if count >= models.MaxProductImages {
+		return nil, ErrTooManyImages
+	}
+
+	query := `
+	INSERT INTO product_images (id, product_id, position, original_key, thumbnail_key, url, thumbnail_url, content_type, size_bytes, width, height)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	RETURNING ` + imageColumns
+
+	created, err := scanImage(tx.QueryRow(ctx, query,
+		image.ID,
+		image.ProductID,
+		lastPosition+1,
+		image.OriginalKey,
+		image.ThumbnailKey,
+		image.URL,
+		image.ThumbnailURL,
+		image.ContentType,
+		image.SizeBytes,
+		image.Width,
+		image.Height,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("CreateImage: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("CreateImage commit: %w", err)
+	}
+	return created, nil
+}
+
+// Delete removes the image row and returns it so the caller can delete
+// its blobs.
+func (r *PgImageRepo) Delete(ctx context.Context, productID, imageID, userID string) (*models.ProductImage, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+	DELETE FROM product_images i
+	USING products p
+	WHERE i.id = $1 AND i.product_id = $2 AND p.id = i.product_id AND p.user_id = $3 AND p.deleted_at IS NULL
+	RETURNING i.id, i.product_id, i.position, i.original_key, i.thumbnail_key, i.url, i.thumbnail_url,
+		i.content_type, i.size_bytes, i.width, i.height, i.created_at`
+
+	image, err := scanImage(r.pool.QueryRow(ctx, query, imageID, productID, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrImageNotFound
+		}
+		return nil, fmt.Errorf("DeleteImage: %w", err)
+	}
+	return image, nil
+}
+
+// Reorder sets image positions to follow imageIDs, which must name every
+// image of the product.
+func (r *PgImageRepo) Reorder(ctx context.Context, productID, userID string, imageIDs []string) ([]models.ProductImage, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ReorderImages begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := lockOwnedProduct(ctx, tx, productID, userID); err != nil {
+		return nil, err
+	}
+
+	query := `
+	UPDATE product_images i
+	SET position = o.position
+	FROM unnest($2::uuid[]) WITH ORDINALITY AS o(id, position)
+	WHERE i.product_id = $1 AND i.id = o.id`
+
+	tag, err := tx.Exec(ctx, query, productID, imageIDs)
+	if err != nil {
+		return nil, fmt.Errorf("ReorderImages: %w", err)
+	}
+
+	var total int
+	err = tx.QueryRow(ctx, `SELECT count(*) FROM product_images WHERE product_id = $1`, productID).Scan(&total)
+	if err != nil {
+		return nil, fmt.Errorf("ReorderImages: %w", err)
+	}
+	if int(tag.RowsAffected()) != total || len(imageIDs) != total {
+		return nil, ErrInvalidImageOrder
+	}
+
+	id, err := uuid.Parse(productID)
+	if err != nil {
+		return nil, ErrDoesNotExist
+	}
+	images, err := queryImages(ctx, tx, []uuid.UUID{id})
+	if err != nil {
+		return nil, fmt.Errorf("ReorderImages: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("ReorderImages commit: %w", err)
+	}
+	if images[id] == nil {
+		return []models.ProductImage{}, nil
+	}
+	return images[id], nil
+}
@@ -0,0 +1,280 @@
+package repository
+
+import (
+	"context"
+	"e-commerce/internal/domain/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrImportJobNotFound = errors.New("import job not found")
+
+// errDryRun rolls back a dry-run import once every row has been tried.
+var errDryRun = errors.New("dry run")
+
+const importJobColumns = "id, user_id, status, format, dry_run, total, results, error, created_at, finished_at"
+
+// importBatchSize is how many new products go into one INSERT.
+const importBatchSize = 500
+
+type PgImportRepo struct {
+	pool       *pgxpool.Pool
+	searchLang string
+}
+
+func NewImportRepo(pool *pgxpool.Pool, searchLang string) *PgImportRepo {
+	return &PgImportRepo{pool: pool, searchLang: searchLang}
+}
+
+func scanImportJob(row pgx.Row, extra ...any) (*models.ImportJob, error) {
+	var job models.ImportJob
+	var errMsg *string
+	dest := append([]any{
+		&job.ID, &job.UserID, &job.Status, &job.Format, &job.DryRun, &job.Summary.Total,
+		&job.Rows, &errMsg, &job.CreatedAt, &job.FinishedAt,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	if errMsg != nil {
+		job.Error = *errMsg
+	}
+	job.Summary = models.SummarizeImport(job.Summary.Total, job.Rows)
+	return &job, nil
+}
+
+// CreateJob queues rows for the import worker. rejected holds the lines
+// that already failed validation, so they show up in the report.
+func (r *PgImportRepo) CreateJob(ctx context.Context, userID, format string, dryRun bool, total int, rows []models.ProductImportRow, rejected []models.ImportRowResult) (*models.ImportJob, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	// JSONB columns are NOT NULL, and a nil slice would encode as null
+	if rows == nil {
+		rows = []models.ProductImportRow{}
+	}
+	if rejected == nil {
+		rejected = []models.ImportRowResult{}
+	}
+
+	query := `INSERT INTO product_import_jobs (user_id, format, dry_run, total, pending_rows, results)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	RETURNING ` + importJobColumns
+
+	job, err := scanImportJob(r.pool.QueryRow(ctx, query, userID, format, dryRun, total, rows, rejected))
+	if err != nil {
+		return nil, fmt.Errorf("CreateImportJob: %w", err)
+	}
+	return job, nil
+}
+
+func (r *PgImportRepo) GetJob(ctx context.Context, id, userID string) (*models.ImportJob, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + importJobColumns + ` FROM product_import_jobs WHERE id = $1 AND user_id = $2`
+
+	job, err := scanImportJob(r.pool.QueryRow(ctx, query, id, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrImportJobNotFound
+		}
+		return nil, fmt.Errorf("GetImportJob: %w", err)
+	}
+	return job, nil
+}
+
+// ClaimJob marks the oldest queued job as running and returns it with its
+// rows, or nil when the queue is empty. A job left running since before
+// staleBefore is taken to belong to a crashed worker and is claimed again;
+// its rows were applied in a single transaction, so nothing was kept.
+func (r *PgImportRepo) ClaimJob(ctx context.Context, staleBefore time.Time) (*models.ImportJob, []models.ProductImportRow, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `UPDATE product_import_jobs SET status = 'running', started_at = NOW()
+	WHERE id = (
+		SELECT id FROM product_import_jobs
+		WHERE status = 'pending' OR (status = 'running' AND started_at < $1)
+		ORDER BY created_at
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING ` + importJobColumns + `, pending_rows`
+
+	var rows []models.ProductImportRow
+	job, err := scanImportJob(r.pool.QueryRow(ctx, query, staleBefore), &rows)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("ClaimImportJob: %w", err)
+	}
+	return job, rows, nil
+}
+
+func (r *PgImportRepo) FinishJob(ctx context.Context, id, status string, results []models.ImportRowResult, errMsg string) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if results == nil {
+		results = []models.ImportRowResult{}
+	}
+
+	query := `UPDATE product_import_jobs
+	SET status = $2, results = $3, error = NULLIF($4, ''), pending_rows = '[]', finished_at = NOW()
+	WHERE id = $1`
+
+	if _, err := r.pool.Exec(ctx, query, id, status, results, errMsg); err != nil {
+		return fmt.Errorf("FinishImportJob: %w", err)
+	}
+	return nil
+}
+
+// ApplyRows writes the rows on behalf of userID in one transaction:
+// updates one at a time, each behind a savepoint so a failing row doesn't
+// abort the rest, and creates in batches that skip duplicates. A dry run
+// rolls everything back but reports the same outcome.
+func (r *PgImportRepo) ApplyRows(ctx context.Context, userID string, rows []models.ProductImportRow, dryRun bool) ([]models.ImportRowResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	var results []models.ImportRowResult
+	err := withActor(ctx, r.pool, userID, "import", func(tx pgx.Tx) error {
+		results = make([]models.ImportRowResult, 0, len(rows))
+
+		var creates []models.ProductImportRow
+		for _, row := range rows {
+			if row.ID == nil {
+				creates = append(creates, row)
+				continue
+			}
+			result, err := updateImportRow(ctx, tx, userID, row)
+			if err != nil {
+				return err
+			}
+			results = append(results, result)
+		}
+
+		for start := 0; start < len(creates); start += importBatchSize {
+			batch := creates[start:min(start+importBatchSize, len(creates))]
+			created, err := insertImportRows(ctx, tx, userID, r.searchLang, batch)
+			if err != nil {
+				return err
+			}
+			results = append(results, created...)
+		}
+
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, fmt.Errorf("ApplyImportRows: %w", err)
+	}
+	return results, nil
+}
+
+func updateImportRow(ctx context.Context, tx pgx.Tx, userID string, row models.ProductImportRow) (models.ImportRowResult, error) {
+	result := models.ImportRowResult{Line: row.Line, Status: models.ImportRowUpdated, ProductID: row.ID}
+
+	sp, err := tx.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	var id uuid.UUID
+	err = sp.QueryRow(ctx, `UPDATE products SET name = $1, price = $2, currency = $3
+	WHERE id = $4 AND user_id = $5 AND deleted_at IS NULL RETURNING id`,
+		row.Name, row.Price.Amount, row.Price.Currency, row.ID, userID).Scan(&id)
+	if err == nil {
+		return result, sp.Commit(ctx)
+	}
+	if rbErr := sp.Rollback(ctx); rbErr != nil {
+		return result, rbErr
+	}
+
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return failedImportRow(row, "id", ErrDoesNotExist.Error()), nil
+	case errors.As(err, &pgErr) && pgErr.Code == "23505":
+		return failedImportRow(row, "", ErrAlreadyExists.Error()), nil
+	default:
+		return result, err
+	}
+}
+
+// insertImportRows creates products for rows, skipping any whose name and
+// price are already taken, including by an earlier row of the batch.
+func insertImportRows(ctx context.Context, tx pgx.Tx, userID, searchLang string, rows []models.ProductImportRow) ([]models.ImportRowResult, error) {
+	names := make([]string, len(rows))
+	amounts := make([]int64, len(rows))
+	currencies := make([]string, len(rows))
+	slugs := make([]string, len(rows))
+	for i, row := range rows {
+		names[i] = row.Name
+		amounts[i] = row.Price.Amount
+		currencies[i] = row.Price.Currency
+		slugs[i] = models.Slugify(row.Name)
+	}
+
+	query := `INSERT INTO products (name, price, currency, user_id, search_language, slug)
+	SELECT t.name, t.price, t.currency, $4, $5::regconfig, t.slug
+	FROM unnest($1::text[], $2::bigint[], $3::text[], $6::text[]) WITH ORDINALITY AS t(name, price, currency, slug, ord)
+	ORDER BY t.ord
+	ON CONFLICT (name, price, currency) WHERE deleted_at IS NULL DO NOTHING
+	RETURNING id, name, price, currency`
+
+	dbRows, err := tx.Query(ctx, query, names, amounts, currencies, userID, searchLang, slugs)
+	if err != nil {
+		return nil, err
+	}
+	defer dbRows.Close()
+
+	type key struct {
+		name     string
+		amount   int64
+		currency string
+	}
+	created := make(map[key]uuid.UUID, len(rows))
+	for dbRows.Next() {
+		var id uuid.UUID
+		var k key
+		if err := dbRows.Scan(&id, &k.name, &k.amount, &k.currency); err != nil {
+			return nil, err
+		}
+		created[k] = id
+	}
+	if err := dbRows.Err(); err != nil {
+		return nil, err
+	}
+
+	results := make([]models.ImportRowResult, len(rows))
+	for i, row := range rows {
+		k := key{row.Name, row.Price.Amount, row.Price.Currency}
+		id, ok := created[k]
+		if !ok {
+			results[i] = failedImportRow(row, "", ErrAlreadyExists.Error())
+			continue
+		}
+		// the first row with a given key is the one that was inserted
+		delete(created, k)
+		results[i] = models.ImportRowResult{Line: row.Line, Status: models.ImportRowCreated, ProductID: &id}
+	}
+	return results, nil
+}
+
+func failedImportRow(row models.ProductImportRow, field, message string) models.ImportRowResult {
+	return models.ImportRowResult{
+		Line:   row.Line,
+		Status: models.ImportRowFailed,
+		Errors: []models.ImportRowError{{Field: field, Message: message}},
+	}
+}
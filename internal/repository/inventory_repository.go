@@ -0,0 +1,533 @@
+package repository
+
+import (
+	"context"
+	"e-commerce/internal/domain/models"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrInsufficientStock = errors.New("insufficient stock")
+var ErrVariantRequired = errors.New("product has variants; stock is tracked per variant")
+var ErrReservationNotFound = errors.New("reservation not found")
+var ErrReservationNotActive = errors.New("reservation is no longer active")
+var ErrBundleStock = errors.New("bundle stock is its components' stock")
+
+const stockColumns = "id, product_id, variant_id, on_hand, reserved, updated_at"
+
+const reservationColumns = `r.id, s.product_id, s.variant_id, r.user_id, r.quantity, r.status, r.expires_at, r.created_at`
+
+type PgInventoryRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewInventoryRepo(pool *pgxpool.Pool) *PgInventoryRepo {
+	return &PgInventoryRepo{pool: pool}
+}
+
+func scanStockLevel(row pgx.Row) (*models.StockLevel, error) {
+	var level models.StockLevel
+	err := row.Scan(
+		&level.ID,
+		&level.ProductID,
+		&level.VariantID,
+		&level.OnHand,
+		&level.Reserved,
+		&level.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	level.Available = level.OnHand - level.Reserved
+	return &level, nil
+}
+
+func scanReservation(row pgx.Row) (*models.Reservation, error) {
+	var res models.Reservation
+	err := row.Scan(
+		&res.ID,
+		&res.ProductID,
+		&res.VariantID,
+		&res.UserID,
+		&res.Quantity,
+		&res.Status,
+		&res.ExpiresAt,
+		&res.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// checkStockProduct checks that the product, or its variant, can have a
+// stock row and reports whether it is a bundle. ownerID, when not empty,
+// restricts the lookup to the seller's own products; otherwise only
+// published ones are found.
+func checkStockProduct(ctx context.Context, tx pgx.Tx, productID string, variantID *string, ownerID string) (bool, error) {
+	var args queryArgs
+	query := `SELECT EXISTS (SELECT 1 FROM product_variants WHERE product_id = p.id),
+		EXISTS (SELECT 1 FROM product_bundles WHERE product_id = p.id)
+	FROM products p WHERE p.deleted_at IS NULL AND p.id = ` + args.add(productID)
+	if ownerID != "" {
+		query += ` AND p.user_id = ` + args.add(ownerID)
+	} else {
+		query += ` AND p.status = 'published'` // buyers only see the catalog
+	}
+
+	var hasVariants, isBundle bool
+	if err := tx.QueryRow(ctx, query, args...).Scan(&hasVariants, &isBundle); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, ErrDoesNotExist
+		}
+		return false, err
+	}
+
+	if variantID == nil {
+		if hasVariants {
+			return false, ErrVariantRequired
+		}
+		return isBundle, nil
+	}
+	var found bool
+	err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM product_variants WHERE id = $1 AND product_id = $2)`,
+		*variantID, productID).Scan(&found)
+	if err != nil {
+		return false, err
+	}
+	if !found {
+		return false, ErrVariantNotFound
+	}
+	return isBundle, nil
+}
+
+// ensureStockLevel returns the stock row for the product or variant,
+// creating an empty one on first use.
+func ensureStockLevel(ctx context.Context, tx pgx.Tx, productID string, variantID *string) (uuid.UUID, error) {
+	_, err := tx.Exec(ctx, `
+	INSERT INTO stock_levels (product_id, variant_id) VALUES ($1, $2)
+	ON CONFLICT (product_id, variant_id) DO NOTHING`, productID, variantID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	var id uuid.UUID
+	err = tx.QueryRow(ctx, `
+	SELECT id FROM stock_levels
+	WHERE product_id = $1 AND variant_id IS NOT DISTINCT FROM $2::uuid`, productID, variantID).Scan(&id)
+	return id, err
+}
+
+type stockHold struct {
+	stockID  uuid.UUID
+	quantity int
+}
+
+// bundleHolds returns what reserving quantity bundles holds on each
+// component's stock row. The rows are locked in id order, so concurrent
+// reservations of bundles sharing components queue up instead of
+// deadlocking.
+func bundleHolds(ctx context.Context, tx pgx.Tx, bundleID string, quantity int) ([]stockHold, error) {
+	_, err := tx.Exec(ctx, `
+	INSERT INTO stock_levels (product_id, variant_id)
+	SELECT component_id, NULL FROM product_bundle_items WHERE bundle_id = $1
+	ON CONFLICT (product_id, variant_id) DO NOTHING`, bundleID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, `
+	SELECT s.id, i.quantity * $2
+	FROM product_bundle_items i
+	JOIN stock_levels s ON s.product_id = i.component_id AND s.variant_id IS NULL
+	WHERE i.bundle_id = $1
+	ORDER BY s.id
+	FOR UPDATE OF s`, bundleID, quantity)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var holds []stockHold
+	for rows.Next() {
+		var h stockHold
+		if err := rows.Scan(&h.stockID, &h.quantity); err != nil {
+			return nil, err
+		}
+		holds = append(holds, h)
+	}
+	return holds, rows.Err()
+}
+
+// settleHolds gives the units the reservations hold back to their stock
+// rows, locking the rows in id order as bundleHolds does. A non-empty
+// buyerID means the units were sold to the buyer: they also leave on-hand
+// stock and each row records the sale in the ledger.
+func settleHolds(ctx context.Context, tx pgx.Tx, reservationIDs []uuid.UUID, buyerID string) error {
+	_, err := tx.Exec(ctx, `
+	SELECT id FROM stock_levels
+	WHERE id IN (SELECT stock_id FROM stock_reservation_items WHERE reservation_id = ANY($1))
+	ORDER BY id
+	FOR UPDATE`, reservationIDs)
+	if err != nil {
+		return err
+	}
+
+	sold := buyerID != ""
+	_, err = tx.Exec(ctx, `
+	UPDATE stock_levels s
+	SET on_hand = s.on_hand - CASE WHEN $2 THEN t.quantity ELSE 0 END, reserved = s.reserved - t.quantity
+	FROM (
+		SELECT stock_id, sum(quantity) AS quantity FROM stock_reservation_items
+		WHERE reservation_id = ANY($1)
+		GROUP BY stock_id
+	) t
+	WHERE s.id = t.stock_id`, reservationIDs, sold)
+	if err != nil || !sold {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+	INSERT INTO stock_movements (stock_id, quantity, reason, reservation_id, created_by)
+	SELECT stock_id, -quantity, 'sale', reservation_id, $2 FROM stock_reservation_items
+	WHERE reservation_id = ANY($1)`, reservationIDs, buyerID)
+	return err
+}
+
+func (r *PgInventoryRepo) GetStock(ctx context.Context, productID, userID string) ([]models.StockLevel, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var exists bool
+	err := r.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM products WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL)`, productID, userID).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("GetStock: %w", err)
+	}
+	if !exists {
+		return nil, ErrDoesNotExist
+	}
+
+	rows, err := r.pool.Query(ctx, `SELECT `+stockColumns+` FROM stock_levels WHERE product_id = $1 ORDER BY variant_id NULLS FIRST`, productID)
+	if err != nil {
+		return nil, fmt.Errorf("GetStock: %w", err)
+	}
+	defer rows.Close()
+
+	levels := make([]models.StockLevel, 0)
+	for rows.Next() {
+		level, err := scanStockLevel(rows)
+		if err != nil {
+			return nil, fmt.Errorf("GetStock: %w", err)
+		}
+		levels = append(levels, *level)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("GetStock: %w", err)
+	}
+	return levels, nil
+}
+
+// RecordMovement applies a signed quantity change to on-hand stock and
+// appends it to the ledger. The update is rejected if it would push
+// on-hand below what is currently reserved.
+func (r *PgInventoryRepo) RecordMovement(ctx context.Context, productID string, variantID *string, userID string, quantity int, reason, note string) (*models.StockLevel, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("RecordMovement: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	isBundle, err := checkStockProduct(ctx, tx, productID, variantID, userID)
+	if err != nil {
+		if errors.Is(err, ErrDoesNotExist) || errors.Is(err, ErrVariantNotFound) || errors.Is(err, ErrVariantRequired) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("RecordMovement: %w", err)
+	}
+	if isBundle {
+		return nil, ErrBundleStock
+	}
+
+	stockID, err := ensureStockLevel(ctx, tx, productID, variantID)
+	if err != nil {
+		return nil, fmt.Errorf("RecordMovement: %w", err)
+	}
+
+	level, err := scanStockLevel(tx.QueryRow(ctx, `
+	UPDATE stock_levels SET on_hand = on_hand + $1
+	WHERE id = $2 AND on_hand + $1 >= reserved
+	RETURNING `+stockColumns, quantity, stockID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrInsufficientStock
+		}
+		return nil, fmt.Errorf("RecordMovement: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+	INSERT INTO stock_movements (stock_id, quantity, reason, note, created_by)
+	VALUES ($1, $2, $3, $4, $5)`, stockID, quantity, reason, note, userID)
+	if err != nil {
+		return nil, fmt.Errorf("RecordMovement: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("RecordMovement: %w", err)
+	}
+	return level, nil
+}
+
+// ListMovements returns the product's ledger newest first. The cursor is
+// the id of the last movement on the previous page.
+func (r *PgInventoryRepo) ListMovements(ctx context.Context, productID, userID string, limit int, cursor string) (*models.StockMovementPage, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var exists bool
+	err := r.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM products WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL)`, productID, userID).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("ListMovements: %w", err)
+	}
+	if !exists {
+		return nil, ErrDoesNotExist
+	}
+
+	if limit <= 0 {
+		limit = models.DefaultPageLimit
+	}
+	if limit > models.MaxPageLimit {
+		limit = models.MaxPageLimit
+	}
+
+	var args queryArgs
+	conds := []string{"s.product_id = " + args.add(productID)}
+	if cursor != "" {
+		before, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		conds = append(conds, "m.id < "+args.add(before))
+	}
+
+	query := fmt.Sprintf(`
+	SELECT m.id, s.product_id, s.variant_id, m.quantity, m.reason, m.note, m.reservation_id, m.created_by, m.created_at
+	FROM stock_movements m
+	JOIN stock_levels s ON s.id = m.stock_id
+	%s
+	ORDER BY m.id DESC
+	LIMIT %d`, whereClause(conds), limit+1)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ListMovements: %w", err)
+	}
+	defer rows.Close()
+
+	movements := make([]models.StockMovement, 0, limit)
+	for rows.Next() {
+		var m models.StockMovement
+		err := rows.Scan(&m.ID, &m.ProductID, &m.VariantID, &m.Quantity, &m.Reason, &m.Note, &m.ReservationID, &m.CreatedBy, &m.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("ListMovements: %w", err)
+		}
+		movements = append(movements, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ListMovements: %w", err)
+	}
+
+	page := &models.StockMovementPage{Items: movements}
+	if len(movements) > limit {
+		page.Items = movements[:limit]
+		page.HasMore = true
+		page.NextCursor = strconv.FormatInt(page.Items[limit-1].ID, 10)
+	}
+	return page, nil
+}
+
+// Reserve holds quantity units for the buyer until ttl elapses. A bundle
+// holds quantity sets of its components on their stock rows, all or
+// nothing. The conditional update makes concurrent reservations for the
+// last units safe without explicit locking.
+func (r *PgInventoryRepo) Reserve(ctx context.Context, productID string, variantID *string, buyerID string, quantity int, ttl time.Duration) (*models.Reservation, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Reserve: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	isBundle, err := checkStockProduct(ctx, tx, productID, variantID, "")
+	if err != nil {
+		if errors.Is(err, ErrDoesNotExist) || errors.Is(err, ErrVariantNotFound) || errors.Is(err, ErrVariantRequired) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("Reserve: %w", err)
+	}
+
+	// a bundle's own row holds nothing; it only records what was reserved
+	stockID, err := ensureStockLevel(ctx, tx, productID, variantID)
+	if err != nil {
+		return nil, fmt.Errorf("Reserve: %w", err)
+	}
+
+	holds := []stockHold{{stockID: stockID, quantity: quantity}}
+	if isBundle {
+		holds, err = bundleHolds(ctx, tx, productID, quantity)
+		if err != nil {
+			return nil, fmt.Errorf("Reserve: %w", err)
+		}
+	}
+
+	for _, h := range holds {
+		result, err := tx.Exec(ctx, `
+		UPDATE stock_levels SET reserved = reserved + $1
+		WHERE id = $2 AND on_hand - reserved >= $1`, h.quantity, h.stockID)
+		if err != nil {
+			return nil, fmt.Errorf("Reserve: %w", err)
+		}
+		if result.RowsAffected() == 0 {
+			return nil, ErrInsufficientStock
+		}
+	}
+
+	res, err := scanReservation(tx.QueryRow(ctx, `
+	WITH r AS (
+		INSERT INTO stock_reservations (stock_id, user_id, quantity, expires_at)
+		VALUES ($1, $2, $3, NOW() + $4::interval)
+		RETURNING *
+	)
+	SELECT `+reservationColumns+` FROM r JOIN stock_levels s ON s.id = r.stock_id`,
+		stockID, buyerID, quantity, ttl))
+	if err != nil {
+		return nil, fmt.Errorf("Reserve: %w", err)
+	}
+
+	for _, h := range holds {
+		_, err := tx.Exec(ctx, `
+		INSERT INTO stock_reservation_items (reservation_id, stock_id, quantity) VALUES ($1, $2, $3)`,
+			res.ID, h.stockID, h.quantity)
+		if err != nil {
+			return nil, fmt.Errorf("Reserve: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("Reserve: %w", err)
+	}
+	return res, nil
+}
+
+// finishReservation moves an active reservation to status and returns the
+// held units. Committing additionally deducts them from on-hand stock and
+// records the sales in the ledger.
+func (r *PgInventoryRepo) finishReservation(ctx context.Context, op, reservationID, buyerID, status string) (*models.Reservation, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	res, err := scanReservation(tx.QueryRow(ctx, `
+	WITH r AS (
+		UPDATE stock_reservations SET status = $1
+		WHERE id = $2 AND user_id = $3 AND status = 'active' AND expires_at > NOW()
+		RETURNING *
+	)
+	SELECT `+reservationColumns+` FROM r JOIN stock_levels s ON s.id = r.stock_id`,
+		status, reservationID, buyerID))
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		var exists bool
+		err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM stock_reservations WHERE id = $1 AND user_id = $2)`,
+			reservationID, buyerID).Scan(&exists)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if !exists {
+			return nil, ErrReservationNotFound
+		}
+		return nil, ErrReservationNotActive
+	}
+
+	soldTo := ""
+	if status == models.ReservationCommitted {
+		soldTo = buyerID
+	}
+	if err := settleHolds(ctx, tx, []uuid.UUID{res.ID}, soldTo); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return res, nil
+}
+
+func (r *PgInventoryRepo) CommitReservation(ctx context.Context, reservationID, buyerID string) (*models.Reservation, error) {
+	return r.finishReservation(ctx, "CommitReservation", reservationID, buyerID, models.ReservationCommitted)
+}
+
+func (r *PgInventoryRepo) ReleaseReservation(ctx context.Context, reservationID, buyerID string) (*models.Reservation, error) {
+	return r.finishReservation(ctx, "ReleaseReservation", reservationID, buyerID, models.ReservationReleased)
+}
+
+// ReleaseExpired expires up to batch overdue reservations and returns their
+// units to available stock. Rows locked by an in-flight commit or release
+// are skipped and picked up on the next run if still active.
+func (r *PgInventoryRepo) ReleaseExpired(ctx context.Context, batch int) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("ReleaseExpired: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+	UPDATE stock_reservations SET status = 'expired'
+	WHERE id IN (
+		SELECT id FROM stock_reservations
+		WHERE status = 'active' AND expires_at <= NOW()
+		ORDER BY expires_at
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING id`, batch)
+	if err != nil {
+		return 0, fmt.Errorf("ReleaseExpired: %w", err)
+	}
+	expired, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return 0, fmt.Errorf("ReleaseExpired: %w", err)
+	}
+	if len(expired) == 0 {
+		return 0, nil
+	}
+
+	if err := settleHolds(ctx, tx, expired, ""); err != nil {
+		return 0, fmt.Errorf("ReleaseExpired: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("ReleaseExpired: %w", err)
+	}
+	return len(expired), nil
+}
@@ -0,0 +1,359 @@
+package repository
+
+import (
+	"context"
+	"e-commerce/internal/domain/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrScheduledPriceNotFound = errors.New("scheduled price not found")
+var ErrScheduledPriceStarted = errors.New("scheduled price has already started")
+var ErrPriceScheduleOverlap = errors.New("scheduled price overlaps another one")
+
+const scheduledPriceColumns = "id, product_id, price, currency, effective_from, effective_to, status, error, created_at"
+
+type PgPriceRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewPriceRepo(pool *pgxpool.Pool) *PgPriceRepo {
+	return &PgPriceRepo{pool: pool}
+}
+
+func scanScheduledPrice(row pgx.Row) (*models.ScheduledPrice, error) {
+	var s models.ScheduledPrice
+	err := row.Scan(
+		&s.ID,
+		&s.ProductID,
+		&s.Price.Amount,
+		&s.Price.Currency,
+		&s.EffectiveFrom,
+		&s.EffectiveTo,
+		&s.Status,
+		&s.Error,
+		&s.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// lowestPrices returns, per product, the lowest price it had in the
+// LowestPriceWindow before its current price took effect, counting only
+// prices in the current currency.
+func lowestPrices(ctx context.Context, q querier, productIDs []uuid.UUID) (map[uuid.UUID]models.Money, error) {
+	rows, err := q.Query(ctx, `
+	SELECT cur.product_id, min(h.price), cur.currency
+	FROM product_prices cur
+	JOIN product_prices h ON h.product_id = cur.product_id
+		AND h.currency = cur.currency
+		AND h.effective_to > h.effective_from
+		AND h.effective_to > cur.effective_from - $2::interval
+		AND h.effective_from < cur.effective_from
+	WHERE cur.product_id = ANY($1) AND cur.effective_to IS NULL
+	GROUP BY cur.product_id, cur.currency`, productIDs, models.LowestPriceWindow)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lowest := make(map[uuid.UUID]models.Money)
+	for rows.Next() {
+		var id uuid.UUID
+		var m models.Money
+		if err := rows.Scan(&id, &m.Amount, &m.Currency); err != nil {
+			return nil, err
+		}
+		lowest[id] = m
+	}
+	return lowest, rows.Err()
+}
+
+// attachLowestPrices fills in LowestPrice30d, which only the storefront
+// shows.
+func attachLowestPrices(ctx context.Context, q querier, products ...*models.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, len(products))
+	for i, p := range products {
+		ids[i] = p.ID
+	}
+	lowest, err := lowestPrices(ctx, q, ids)
+	if err != nil {
+		return err
+	}
+	for _, p := range products {
+		if m, ok := lowest[p.ID]; ok {
+			p.LowestPrice30d = &m
+		}
+	}
+	return nil
+}
+
+func (r *PgPriceRepo) Timeline(ctx context.Context, productID, userID string) (*models.PriceTimeline, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var timeline models.PriceTimeline
+	err := r.pool.QueryRow(ctx, `SELECT price, currency FROM products WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`,
+		productID, userID).Scan(&timeline.Current.Amount, &timeline.Current.Currency)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDoesNotExist
+		}
+		return nil, fmt.Errorf("GetPriceTimeline: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, `
+	SELECT price, currency, effective_from, effective_to
+	FROM product_prices
+	WHERE product_id = $1
+	ORDER BY effective_from, id`, productID)
+	if err != nil {
+		return nil, fmt.Errorf("GetPriceTimeline: %w", err)
+	}
+	timeline.History, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.PricePeriod, error) {
+		var p models.PricePeriod
+		err := row.Scan(&p.Price.Amount, &p.Price.Currency, &p.EffectiveFrom, &p.EffectiveTo)
+		return p, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("GetPriceTimeline: %w", err)
+	}
+
+	rows, err = r.pool.Query(ctx, `SELECT `+scheduledPriceColumns+` FROM product_price_schedules
+	WHERE product_id = $1
+	ORDER BY effective_from, created_at`, productID)
+	if err != nil {
+		return nil, fmt.Errorf("GetPriceTimeline: %w", err)
+	}
+	timeline.Scheduled, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.ScheduledPrice, error) {
+		s, err := scanScheduledPrice(row)
+		if err != nil {
+			return models.ScheduledPrice{}, err
+		}
+		return *s, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("GetPriceTimeline: %w", err)
+	}
+
+	id, _ := uuid.Parse(productID)
+	lowest, err := lowestPrices(ctx, r.pool, []uuid.UUID{id})
+	if err != nil {
+		return nil, fmt.Errorf("GetPriceTimeline: %w", err)
+	}
+	if m, ok := lowest[id]; ok {
+		timeline.LowestPrice30d = &m
+	}
+	return &timeline, nil
+}
+
+// Schedule plans price for the product from effectiveFrom until
+// effectiveTo, or for good if effectiveTo is nil. Schedules of one product
+// that haven't finished may not overlap.
+func (r *PgPriceRepo) Schedule(ctx context.Context, productID, userID string, price models.Money, effectiveFrom time.Time, effectiveTo *time.Time) (*models.ScheduledPrice, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("SchedulePrice: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := lockOwnedProduct(ctx, tx, productID, userID); err != nil {
+		if errors.Is(err, ErrDoesNotExist) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("SchedulePrice: %w", err)
+	}
+
+	var overlaps bool
+	err = tx.QueryRow(ctx, `
+	SELECT EXISTS (
+		SELECT 1 FROM product_price_schedules
+		WHERE product_id = $1 AND status IN ('pending', 'active')
+		AND tstzrange(effective_from, effective_to) && tstzrange($2, $3)
+	)`, productID, effectiveFrom, effectiveTo).Scan(&overlaps)
+	if err != nil {
+		return nil, fmt.Errorf("SchedulePrice: %w", err)
+	}
+	if overlaps {
+		return nil, ErrPriceScheduleOverlap
+	}
+
+	scheduled, err := scanScheduledPrice(tx.QueryRow(ctx, `
+	INSERT INTO product_price_schedules (product_id, price, currency, effective_from, effective_to)
+	VALUES ($1, $2, $3, $4, $5)
+	RETURNING `+scheduledPriceColumns, productID, price.Amount, price.Currency, effectiveFrom, effectiveTo))
+	if err != nil {
+		return nil, fmt.Errorf("SchedulePrice: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("SchedulePrice: %w", err)
+	}
+	return scheduled, nil
+}
+
+// Cancel deletes a scheduled price that hasn't started yet.
+func (r *PgPriceRepo) Cancel(ctx context.Context, productID, scheduleID, userID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var status string
+	err := r.pool.QueryRow(ctx, `
+	WITH target AS (
+		SELECT s.id, s.status FROM product_price_schedules s
+		JOIN products p ON p.id = s.product_id
+		WHERE s.id = $1 AND s.product_id = $2 AND p.user_id = $3 AND p.deleted_at IS NULL
+		FOR UPDATE OF s
+	), deleted AS (
+		DELETE FROM product_price_schedules s
+		USING target t
+		WHERE s.id = t.id AND t.status = 'pending'
+	)
+	SELECT status FROM target`, scheduleID, productID, userID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrScheduledPriceNotFound
+		}
+		return fmt.Errorf("CancelScheduledPrice: %w", err)
+	}
+	if status != models.ScheduledPricePending {
+		return ErrScheduledPriceStarted
+	}
+	return nil
+}
+
+// dueSchedule is a scheduled price the scheduler is about to start or end.
+type dueSchedule struct {
+	id        uuid.UUID
+	productID uuid.UUID
+	price     models.Money
+	previous  models.Money
+}
+
+// applyDue locks up to limit schedules picked by query and hands each to
+// apply in a savepoint of its own, so that one that can't be applied is
+// marked failed without holding up the rest. It runs with no actor, so
+// revisions show the price changes as automatic.
+func (r *PgPriceRepo) applyDue(ctx context.Context, query string, now time.Time, limit int, apply func(tx pgx.Tx, s dueSchedule) error) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	n := 0
+	err := withActor(ctx, r.pool, "", "scheduled_price", func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, query, now, limit)
+		if err != nil {
+			return err
+		}
+		due, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (dueSchedule, error) {
+			var s dueSchedule
+			err := row.Scan(&s.id, &s.productID, &s.price.Amount, &s.price.Currency, &s.previous.Amount, &s.previous.Currency)
+			return s, err
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, s := range due {
+			sp, err := tx.Begin(ctx)
+			if err != nil {
+				return err
+			}
+			err = apply(sp, s)
+			if err == nil {
+				if err := sp.Commit(ctx); err != nil {
+					return err
+				}
+				n++
+				continue
+			}
+			if rbErr := sp.Rollback(ctx); rbErr != nil {
+				return rbErr
+			}
+			var pgErr *pgconn.PgError
+			if !errors.As(err, &pgErr) || pgErr.Code != "23505" {
+				return err
+			}
+			_, err = tx.Exec(ctx, `UPDATE product_price_schedules SET status = 'failed', error = $2 WHERE id = $1`,
+				s.id, ErrAlreadyExists.Error())
+			if err != nil {
+				return err
+			}
+			n++
+		}
+		return nil
+	})
+	return n, err
+}
+
+// StartDue applies up to limit scheduled prices whose time has come,
+// remembering the price each replaces, and returns how many it handled.
+func (r *PgPriceRepo) StartDue(ctx context.Context, now time.Time, limit int) (int, error) {
+	query := `
+	SELECT s.id, s.product_id, s.price, s.currency, p.price, p.currency
+	FROM product_price_schedules s
+	JOIN products p ON p.id = s.product_id
+	WHERE s.status = 'pending' AND s.effective_from <= $1
+	ORDER BY s.effective_from
+	LIMIT $2
+	FOR UPDATE SKIP LOCKED`
+
+	n, err := r.applyDue(ctx, query, now, limit, func(tx pgx.Tx, s dueSchedule) error {
+		_, err := tx.Exec(ctx, `UPDATE products SET price = $2, currency = $3 WHERE id = $1`,
+			s.productID, s.price.Amount, s.price.Currency)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `
+		UPDATE product_price_schedules
+		SET status = CASE WHEN effective_to IS NULL THEN 'done' ELSE 'active' END,
+			previous_price = $2, previous_currency = $3
+		WHERE id = $1`, s.id, s.previous.Amount, s.previous.Currency)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("StartDuePrices: %w", err)
+	}
+	return n, nil
+}
+
+// EndDue gives products whose scheduled price has run out their previous
+// price back, unless the seller has changed the price meanwhile, and
+// returns how many schedules it finished.
+func (r *PgPriceRepo) EndDue(ctx context.Context, now time.Time, limit int) (int, error) {
+	query := `
+	SELECT s.id, s.product_id, s.price, s.currency, s.previous_price, s.previous_currency
+	FROM product_price_schedules s
+	JOIN products p ON p.id = s.product_id
+	WHERE s.status = 'active' AND s.effective_to <= $1
+	ORDER BY s.effective_to
+	LIMIT $2
+	FOR UPDATE SKIP LOCKED`
+
+	n, err := r.applyDue(ctx, query, now, limit, func(tx pgx.Tx, s dueSchedule) error {
+		_, err := tx.Exec(ctx, `UPDATE products SET price = $4, currency = $5 WHERE id = $1 AND price = $2 AND currency = $3`,
+			s.productID, s.price.Amount, s.price.Currency, s.previous.Amount, s.previous.Currency)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `UPDATE product_price_schedules SET status = 'done' WHERE id = $1`, s.id)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("EndDuePrices: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"e-commerce/internal/domain/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrProductHasFiles = errors.New("product still has files")
+
+// SetType makes the product physical or digital. A digital product must
+// lose its files before it can become physical.
+func (r *PgProductRepo) SetType(ctx context.Context, productID, userID, productType string, ifMatch []int) (*models.Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+	UPDATE products SET type = $1
+	WHERE id = $2 AND user_id = $3 AND deleted_at IS NULL AND ` + versionCond(4) + `
+	AND ($1 = 'digital' OR NOT EXISTS (SELECT 1 FROM product_files WHERE product_id = products.id))
+	RETURNING ` + productColumns
+
+	product, err := mutateProduct(ctx, r.pool, userID, "type", query, productType, productID, userID, ifMatch)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// the files are the only other thing that stops the update
+			if err := statusMissError(ctx, r.pool, productID, userID, ifMatch); !errors.Is(err, ErrStatusConflict) {
+				return nil, err
+			}
+			return nil, ErrProductHasFiles
+		}
+		return nil, fmt.Errorf("SetProductType: %w", err)
+	}
+	if err := attachProductDetails(ctx, r.pool, product); err != nil {
+		return nil, fmt.Errorf("SetProductType: %w", err)
+	}
+	return product, nil
+}
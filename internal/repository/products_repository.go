@@ -5,6 +5,9 @@ import (
 	"e-commerce/internal/domain/models"
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -14,57 +17,210 @@ import (
 
 var ErrAlreadyExists = errors.New("product with this name and price already exists")
 var ErrDoesNotExist = errors.New("product with this id does not exist")
+var ErrVersionMismatch = errors.New("product was modified since the given version")
+var ErrUnknownField = errors.New("unknown product field")
+
+const productColumns = "id, name, price, currency, user_id, created_at, updated_at, deleted_at, version, attributes, tags, status, publish_at, unpublish_at, slug, type"
 
 type PgProductRepo struct {
-	pool *pgxpool.Pool
+	pool       *pgxpool.Pool
+	searchLang string
 }
 
-func NewProductRepo(pool *pgxpool.Pool) *PgProductRepo {
-	return &PgProductRepo{pool: pool}
+func NewProductRepo(pool *pgxpool.Pool, searchLang string) *PgProductRepo {
+	return &PgProductRepo{pool: pool, searchLang: searchLang}
 }
 
-func (r *PgProductRepo) Delete(ctx context.Context, productID string, userID string) error {
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
+// productScanTargets returns scan destinations matching productColumns.
+func productScanTargets(product *models.Product) []any {
+	return []any{
+		&product.ID,
+		&product.Name,
+		&product.Price.Amount,
+		&product.Price.Currency,
+		&product.UserID,
+		&product.CreatedAt,
+		&product.UpdatedAt,
+		&product.DeletedAt,
+		&product.Version,
+		&product.Attributes,
+		&product.Tags,
+		&product.Status,
+		&product.PublishAt,
+		&product.UnpublishAt,
+		&product.Slug,
+		&product.Type,
+	}
+}
+
+func scanProduct(row pgx.Row) (*models.Product, error) {
+	var product models.Product
+	err := row.Scan(productScanTargets(&product)...)
+	if err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
 
+func deleteStatement(productID, userID string, ifMatch []int) (string, []any) {
 	query := `
-	DELETE FROM products
-	WHERE id = $1 AND user_id = $2
-	`
+	UPDATE products SET deleted_at = NOW()
+	WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL AND ` + versionCond(3) + `
+	RETURNING ` + productColumns
+	return query, []any{productID, userID, ifMatch}
+}
+
+// Delete moves the product to the trash. It stays restorable until Purge
+// removes it for good.
+func (r *PgProductRepo) Delete(ctx context.Context, productID string, userID string, ifMatch []int) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
 
-	result, err := r.pool.Exec(ctx, query, productID, userID)
+	query, args := deleteStatement(productID, userID, ifMatch)
+	_, err := mutateProduct(ctx, r.pool, userID, "delete", query, args...)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return missError(ctx, r.pool, productID, userID, ifMatch) // проверка была ли удалена строка
+		}
+		if isBundleComponentGuard(err) {
+			return ErrProductInBundle
+		}
 		return fmt.Errorf("DeleteProductById: %w", err)
 	}
 
-	if result.RowsAffected() == 0 {
-		return ErrDoesNotExist // проверка была ли удалена строка
-	}
-
 	return nil
 }
 
-func (r *PgProductRepo) Create(ctx context.Context, name string, price float64, userID string) (*models.Product, error) {
+func (r *PgProductRepo) Restore(ctx context.Context, productID string, userID string) (*models.Product, error) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	query := `
-		INSERT INTO products (name, price, user_id)
-		VALUES ($1, $2, $3)
-		RETURNING id, name, price, user_id, created_at, updated_at
-	`
+	UPDATE products SET deleted_at = NULL
+	WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL
+	RETURNING ` + productColumns
 
-	var product models.Product
+	product, err := mutateProduct(ctx, r.pool, userID, "restore", query, productID, userID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, ErrAlreadyExists // a live product took its name and price meanwhile
+		}
+		if isBundleRestoreGuard(err) {
+			return nil, ErrBundleComponentTrashed
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDoesNotExist
+		}
+		return nil, fmt.Errorf("RestoreProduct: %w", err)
+	}
+	if err := attachProductDetails(ctx, r.pool, product); err != nil {
+		return nil, fmt.Errorf("RestoreProduct: %w", err)
+	}
+	return product, nil
+}
 
-	err := r.pool.QueryRow(ctx, query, name, price, userID).Scan(
-		&product.ID,
-		&product.Name,
-		&product.Price,
-		&product.UserID,
-		&product.CreatedAt,
-		&product.UpdatedAt,
+// Purge permanently deletes up to limit products trashed before cutoff and
+// returns how many went, plus the blob keys of their images and of their
+// files, which the database no longer references and the caller should
+// delete. Products still in a bundle wait until the bundle is purged or
+// changed.
+func (r *PgProductRepo) Purge(ctx context.Context, cutoff time.Time, limit int) (int, []string, []string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	query := `
+	WITH doomed AS (
+		SELECT id FROM products
+		WHERE deleted_at < $1
+		AND NOT EXISTS (SELECT 1 FROM product_bundle_items WHERE component_id = products.id)
+		ORDER BY deleted_at
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	), deleted AS (
+		DELETE FROM products p
+		USING doomed d
+		WHERE p.id = d.id
+		RETURNING p.id
 	)
+	SELECT (SELECT count(*) FROM deleted),
+		COALESCE((
+			SELECT array_agg(k)
+			FROM product_images i
+			JOIN doomed d ON d.id = i.product_id,
+			unnest(ARRAY[i.original_key, i.thumbnail_key]) AS k
+		), '{}'),
+		COALESCE((
+			SELECT array_agg(f.blob_key)
+			FROM product_files f
+			JOIN doomed d ON d.id = f.product_id
+		), '{}')`
+
+	var purged int
+	var imageKeys, fileKeys []string
+	if err := r.pool.QueryRow(ctx, query, cutoff, limit).Scan(&purged, &imageKeys, &fileKeys); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.ConstraintName == "product_bundle_items_component_id_fkey" {
+			err = ErrProductInBundle // put in a bundle since it was picked
+		}
+		return 0, nil, nil, fmt.Errorf("PurgeProducts: %w", err)
+	}
+	return purged, imageKeys, fileKeys, nil
+}
+
+// versionCond matches any of the versions in parameter n, an int[] that is
+// NULL when the write is unconditional.
+func versionCond(n int) string {
+	return fmt.Sprintf("($%d::int[] IS NULL OR version = ANY($%d::int[]))", n, n)
+}
+
+// missError explains why a conditional write matched no row: either the
+// product is gone or it has moved past the versions in ifMatch.
+func missError(ctx context.Context, q querier, productID, userID string, ifMatch []int) error {
+	if ifMatch == nil {
+		return ErrDoesNotExist
+	}
+	var exists bool
+	err := q.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM products WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL)`, productID, userID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("check product version: %w", err)
+	}
+	if exists {
+		return ErrVersionMismatch
+	}
+	return ErrDoesNotExist
+}
+
+// attachProductDetails loads everything embedded in a product response.
+func attachProductDetails(ctx context.Context, q querier, products ...*models.Product) error {
+	if err := attachVariants(ctx, q, products...); err != nil {
+		return err
+	}
+	if err := attachImages(ctx, q, products...); err != nil {
+		return err
+	}
+	if err := attachBundles(ctx, q, products...); err != nil {
+		return err
+	}
+	return attachRatings(ctx, q, products...)
+}
+
+func (r *PgProductRepo) createStatement(name string, price models.Money, attrs map[string]any, tags []string, userID string) (string, []any) {
+	query := `
+		INSERT INTO products (name, price, currency, user_id, search_language, attributes, tags, slug)
+		VALUES ($1, $2, $3, $4, $5::regconfig, COALESCE($6::jsonb, '{}'), COALESCE($7::text[], '{}'), $8)
+		RETURNING ` + productColumns
+	return query, []any{name, price.Amount, price.Currency, userID, r.searchLang, attrs, tags, models.Slugify(name)}
+}
+
+// Create doesn't check attributes against category definitions: a new
+// product has no categories yet.
+func (r *PgProductRepo) Create(ctx context.Context, name string, price models.Money, attrs map[string]any, tags []string, userID string) (*models.Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
 
+	query, args := r.createStatement(name, price, attrs, tags, userID)
+	product, err := mutateProduct(ctx, r.pool, userID, "create", query, args...)
 	if err != nil {
 		var pgErr *pgconn.PgError
 
@@ -74,146 +230,433 @@ func (r *PgProductRepo) Create(ctx context.Context, name string, price float64,
 		return nil, fmt.Errorf("CreateProduct: %w", err)
 	}
 
-	return &product, nil
+	return product, nil
+}
+
+// updateStatement keeps the product's attributes and tags when attrs or
+// tags is nil.
+func updateStatement(productID, userID, name string, price models.Money, attrs map[string]any, tags []string, ifMatch []int) (string, []any) {
+	query := `
+	UPDATE products 
+	SET name = $1, price = $2, currency = $3, attributes = COALESCE($7::jsonb, attributes), tags = COALESCE($8::text[], tags)
+	WHERE id = $4 AND user_id = $5 AND deleted_at IS NULL AND ` + versionCond(6) + `
+	RETURNING ` + productColumns
+	return query, []any{name, price.Amount, price.Currency, productID, userID, ifMatch, attrs, tags}
 }
 
-func (r *PgProductRepo) Update(ctx context.Context, productID string, userID string, name string, price float64) (*models.Product, error) {
+// writeProduct is mutateProduct for an existing product whose attributes
+// may change: new attributes are checked against the product's categories
+// in the same transaction as the write.
+func writeProduct(ctx context.Context, pool *pgxpool.Pool, userID, action, productID string, attrs map[string]any, ifMatch []int, query string, args ...any) (*models.Product, error) {
+	var product *models.Product
+	err := withActor(ctx, pool, userID, action, func(tx pgx.Tx) error {
+		if attrs != nil {
+			if err := checkProductAttributes(ctx, tx, productID, userID, attrs, ifMatch); err != nil {
+				return err
+			}
+		}
+		var err error
+		product, err = scanProduct(tx.QueryRow(ctx, query, args...))
+		return err
+	})
+	return product, err
+}
+
+func (r *PgProductRepo) Update(ctx context.Context, productID string, userID string, name string, price models.Money, attrs map[string]any, tags []string, ifMatch []int) (*models.Product, error) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	query := `
-	UPDATE products 
-	SET name = $1, price = $2
-	WHERE id = $3 AND user_id = $4
-	RETURNING id, name, price, user_id, created_at, updated_at
-	`
-	var product models.Product
-	err := r.pool.QueryRow(ctx, query, name, price, productID, userID).Scan(
-		&product.ID,
-		&product.Name,
-		&product.Price,
-		&product.UserID,
-		&product.CreatedAt,
-		&product.UpdatedAt,
-	)
+	query, args := updateStatement(productID, userID, name, price, attrs, tags, ifMatch)
+	product, err := writeProduct(ctx, r.pool, userID, "update", productID, attrs, ifMatch, query, args...)
 	if err != nil {
+		if errors.Is(err, ErrInvalidAttributes) {
+			return nil, err
+		}
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, ErrDoesNotExist
+			return nil, missError(ctx, r.pool, productID, userID, ifMatch)
 		}
 		return nil, fmt.Errorf("UpdateProduct: %w", err)
 	}
-	return &product, nil
+	if err := attachProductDetails(ctx, r.pool, product); err != nil {
+		return nil, fmt.Errorf("UpdateProduct: %w", err)
+	}
+	return product, nil
 }
 
-func (r *PgProductRepo) Patch(ctx context.Context, productID string, userID string, updates map[string]any) (*models.Product, error) {
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
+// patchSetters turns a patchable product field into column assignments.
+// Only the column names written here ever reach the SQL text; values are
+// always bound as parameters.
+var patchSetters = map[string]func(args *queryArgs, v any) ([]string, error){
+	"name": func(args *queryArgs, v any) ([]string, error) {
+		name, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("name: unexpected type %T", v)
+		}
+		return []string{"name = " + args.add(name)}, nil
+	},
+	"price": func(args *queryArgs, v any) ([]string, error) {
+		price, ok := v.(models.Money)
+		if !ok {
+			return nil, fmt.Errorf("price: unexpected type %T", v)
+		}
+		return []string{"price = " + args.add(price.Amount), "currency = " + args.add(price.Currency)}, nil
+	},
+	"attributes": func(args *queryArgs, v any) ([]string, error) {
+		attrs, ok := v.(map[string]any)
+		if !ok || attrs == nil {
+			return nil, fmt.Errorf("attributes: unexpected type %T", v)
+		}
+		return []string{"attributes = " + args.add(attrs)}, nil
+	},
+	"tags": func(args *queryArgs, v any) ([]string, error) {
+		tags, ok := v.([]string)
+		if !ok || tags == nil {
+			return nil, fmt.Errorf("tags: unexpected type %T", v)
+		}
+		return []string{"tags = " + args.add(tags) + "::text[]"}, nil
+	},
+}
 
-	name, hasName := updates["name"]
-	price, hasPrice := updates["price"]
+// patchedAttributes returns the attributes a patch sets, or nil.
+func patchedAttributes(updates map[string]any) map[string]any {
+	attrs, _ := updates["attributes"].(map[string]any)
+	return attrs
+}
 
-	if !hasName && !hasPrice {
-		return nil, fmt.Errorf("PatchProduct: no  fields to update")
+// patchStatement builds an UPDATE of just the fields in updates.
+func patchStatement(productID, userID string, updates map[string]any, ifMatch []int) (string, []any, error) {
+	if len(updates) == 0 {
+		return "", nil, errors.New("no fields to update")
 	}
 
-	var query string
-	var args []any
+	fields := make([]string, 0, len(updates))
+	for field := range updates {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var args queryArgs
+	var sets []string
+	for _, field := range fields {
+		setter, ok := patchSetters[field]
+		if !ok {
+			return "", nil, fmt.Errorf("%w: %s", ErrUnknownField, field)
+		}
+		cols, err := setter(&args, updates[field])
+		if err != nil {
+			return "", nil, err
+		}
+		sets = append(sets, cols...)
+	}
 
-	switch {
-	case hasName && hasPrice:
-		query = `UPDATE products SET name = $1, price = $2 WHERE id = $3 AND user_id = $4
-                 RETURNING id, name, price, user_id, created_at, updated_at`
-		args = []any{name, price, productID, userID}
-	case hasName:
-		query = `UPDATE products SET name = $1 WHERE id = $2 AND user_id = $3
-                 RETURNING id, name, price, user_id, created_at, updated_at`
-		args = []any{name, productID, userID}
-	case hasPrice:
-		query = `UPDATE products SET price = $1 WHERE id = $2 AND user_id = $3
-                 RETURNING id, name, price, user_id, created_at, updated_at`
-		args = []any{price, productID, userID}
+	conds := []string{
+		"id = " + args.add(productID),
+		"user_id = " + args.add(userID),
+		"deleted_at IS NULL",
 	}
+	args.add(ifMatch)
+	conds = append(conds, versionCond(len(args)))
 
-	var product models.Product
-	err := r.pool.QueryRow(ctx, query, args...).Scan(
-		&product.ID,
-		&product.Name,
-		&product.Price,
-		&product.UserID,
-		&product.CreatedAt,
-		&product.UpdatedAt,
-	)
+	query := `UPDATE products SET ` + strings.Join(sets, ", ") + `
+	` + whereClause(conds) + ` RETURNING ` + productColumns
+	return query, args, nil
+}
+
+func (r *PgProductRepo) Patch(ctx context.Context, productID string, userID string, updates map[string]any, ifMatch []int) (*models.Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query, args, err := patchStatement(productID, userID, updates, ifMatch)
+	if err != nil {
+		return nil, fmt.Errorf("PatchProduct: %w", err)
+	}
 
+	product, err := writeProduct(ctx, r.pool, userID, "patch", productID, patchedAttributes(updates), ifMatch, query, args...)
 	if err != nil {
+		if errors.Is(err, ErrInvalidAttributes) {
+			return nil, err
+		}
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, ErrDoesNotExist
+			return nil, missError(ctx, r.pool, productID, userID, ifMatch)
 		}
 		return nil, fmt.Errorf("PatchProduct: %w", err)
 	}
-	return &product, nil
+	if err := attachProductDetails(ctx, r.pool, product); err != nil {
+		return nil, fmt.Errorf("PatchProduct: %w", err)
+	}
+	return product, nil
 }
 
 func (r *PgProductRepo) GetByID(ctx context.Context, id string, userID string) (*models.Product, error) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	query :=
-		`SELECT id, name, price, user_id, created_at, updated_at 
-	 FROM products WHERE id = $1 AND user_id = $2`
-
-	var product models.Product
-
-	err := r.pool.QueryRow(ctx, query, id, userID).Scan(
-		&product.ID,
-		&product.Name,
-		&product.Price,
-		&product.UserID,
-		&product.CreatedAt,
-		&product.UpdatedAt,
-	)
+	query := `SELECT ` + productColumns + `
+	 FROM products WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`
 
+	product, err := scanProduct(r.pool.QueryRow(ctx, query, id, userID))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrDoesNotExist
 		}
 		return nil, fmt.Errorf("GetProductById: %w", err)
 	}
+	if err := attachProductDetails(ctx, r.pool, product); err != nil {
+		return nil, fmt.Errorf("GetProductById: %w", err)
+	}
 
-	return &product, nil
+	return product, nil
 }
 
-func (r *PgProductRepo) GetAll(ctx context.Context, userID string) ([]models.Product, error) {
+func (r *PgProductRepo) GetAll(ctx context.Context, userID string, params models.ProductListParams) (*models.ProductPage, error) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	query := `SELECT id, name, price, user_id, created_at, updated_at FROM products WHERE user_id = $1`
-	rows, err := r.pool.Query(ctx, query, userID)
+	var args queryArgs
+	conds := []string{"user_id = " + args.add(userID)}
+
+	page, err := listProducts(ctx, r.pool, conds, args, params)
 	if err != nil {
 		return nil, fmt.Errorf("GetAllProducts: %w", err)
 	}
+	return page, nil
+}
+
+func (r *PgProductRepo) Search(ctx context.Context, userID string, params models.ProductSearchParams) (*models.ProductSearchPage, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var args queryArgs
+	conds := []string{"user_id = " + args.add(userID)}
+
+	page, err := searchProducts(ctx, r.pool, r.searchLang, conds, args, params)
+	if err != nil {
+		return nil, fmt.Errorf("SearchProducts: %w", err)
+	}
+	return page, nil
+}
+
+// exportFetchSize is how many rows each FETCH pulls from the export cursor.
+const exportFetchSize = 1000
+
+// Export passes the seller's products matching params to fn in list
+// order. Rows come through a server-side cursor in a read-only snapshot,
+// so memory stays flat however large the catalogue is. Limit and Cursor
+// are ignored, and variants and images are not attached.
+func (r *PgProductRepo) Export(ctx context.Context, userID string, params models.ProductListParams, fn func(*models.Product) error) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+
+	sortBy, order, err := productSort(params)
+	if err != nil {
+		return fmt.Errorf("ExportProducts: %w", err)
+	}
+	var args queryArgs
+	conds := productFilters([]string{"user_id = " + args.add(userID)}, &args, params)
+
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return fmt.Errorf("ExportProducts: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := fmt.Sprintf(`DECLARE product_export NO SCROLL CURSOR FOR
+	SELECT %s FROM products %s ORDER BY %s %s, id %s`,
+		productColumns, whereClause(conds), sortBy, order, order)
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("ExportProducts: %w", err)
+	}
+
+	fetch := fmt.Sprintf("FETCH %d FROM product_export", exportFetchSize)
+	for {
+		rows, err := tx.Query(ctx, fetch)
+		if err != nil {
+			return fmt.Errorf("ExportProducts: %w", err)
+		}
+		products, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*models.Product, error) {
+			return scanProduct(row)
+		})
+		if err != nil {
+			return fmt.Errorf("ExportProducts: %w", err)
+		}
+		for _, p := range products {
+			if err := fn(p); err != nil {
+				return err
+			}
+		}
+		if len(products) < exportFetchSize {
+			return nil
+		}
+	}
+}
+
+type productSortColumn struct {
+	cast  string
+	value func(p *models.Product) string
+	valid func(v string) bool
+}
+
+var productSortColumns = map[string]productSortColumn{
+	"name": {
+		cast:  "text",
+		value: func(p *models.Product) string { return p.Name },
+		valid: func(string) bool { return true },
+	},
+	"price": {
+		cast:  "bigint",
+		value: func(p *models.Product) string { return strconv.FormatInt(p.Price.Amount, 10) },
+		valid: func(v string) bool { _, err := strconv.ParseInt(v, 10, 64); return err == nil },
+	},
+	"created_at": {
+		cast:  "timestamptz",
+		value: func(p *models.Product) string { return p.CreatedAt.Format(time.RFC3339Nano) },
+		valid: validTimestamp,
+	},
+	"updated_at": {
+		cast:  "timestamptz",
+		value: func(p *models.Product) string { return p.UpdatedAt.Format(time.RFC3339Nano) },
+		valid: validTimestamp,
+	},
+	// only used for the trash, where deleted_at is never null
+	"deleted_at": {
+		cast:  "timestamptz",
+		value: func(p *models.Product) string { return p.DeletedAt.Format(time.RFC3339Nano) },
+		valid: validTimestamp,
+	},
+}
+
+func validTimestamp(v string) bool {
+	_, err := time.Parse(time.RFC3339Nano, v)
+	return err == nil
+}
+
+// productSort resolves the sort column and direction of params, with
+// newest first as the default.
+func productSort(params models.ProductListParams) (string, string, error) {
+	sortBy := params.SortBy
+	if sortBy == "" {
+		sortBy = "created_at"
+	}
+	if _, ok := productSortColumns[sortBy]; !ok {
+		return "", "", fmt.Errorf("unknown sort column %q", sortBy)
+	}
+	order := strings.ToLower(params.Order)
+	if order != "asc" {
+		order = "desc"
+	}
+	return sortBy, order, nil
+}
+
+// productFilters appends the trash state and the list filters in params
+// to conds.
+func productFilters(conds []string, args *queryArgs, params models.ProductListParams) []string {
+	if params.Trashed {
+		conds = append(conds, "deleted_at IS NOT NULL")
+	} else {
+		conds = append(conds, "deleted_at IS NULL")
+	}
+	if params.MinPrice != nil {
+		conds = append(conds, "currency = "+args.add(params.MinPrice.Currency), "price >= "+args.add(params.MinPrice.Amount))
+	}
+	if params.MaxPrice != nil {
+		conds = append(conds, "currency = "+args.add(params.MaxPrice.Currency), "price <= "+args.add(params.MaxPrice.Amount))
+	}
+	if params.NamePrefix != "" {
+		conds = append(conds, "name ILIKE "+args.add(prefixPattern(params.NamePrefix)))
+	}
+	if len(params.Tags) > 0 {
+		op := "&&"
+		if params.MatchAllTags {
+			op = "@>"
+		}
+		conds = append(conds, "tags "+op+" "+args.add(params.Tags)+"::text[]")
+	}
+	if params.Status != "" {
+		conds = append(conds, "status = "+args.add(params.Status))
+	}
+	return append(conds, attributeFilterConds(args, params.Attributes)...)
+}
+
+// listProducts runs a keyset-paginated product query. conds and args carry
+// the caller's scope (owner, visibility) and are extended with the filters
+// and the cursor position from params.
+func listProducts(ctx context.Context, pool *pgxpool.Pool, conds []string, args queryArgs, params models.ProductListParams) (*models.ProductPage, error) {
+	sortBy, order, err := productSort(params)
+	if err != nil {
+		return nil, err
+	}
+	col := productSortColumns[sortBy]
+	cmp := "<"
+	if order == "asc" {
+		cmp = ">"
+	}
+
+	limit := params.Limit
+	if limit <= 0 {
+		limit = models.DefaultPageLimit
+	}
+	if limit > models.MaxPageLimit {
+		limit = models.MaxPageLimit
+	}
+
+	conds = productFilters(conds, &args, params)
+
+	// facets count the whole filtered set, so they leave out the cursor
+	var facets *models.ProductFacets
+	if params.Facets {
+		if facets, err = productFacets(ctx, pool, conds, args); err != nil {
+			return nil, err
+		}
+	}
+
+	sortKey := sortBy + ":" + order
+	if params.Cursor != "" {
+		cur, err := decodeCursor(params.Cursor, sortKey)
+		if err != nil || !col.valid(cur.Value) {
+			return nil, ErrInvalidCursor
+		}
+		conds = append(conds, fmt.Sprintf("(%s, id) %s (%s::%s, %s::uuid)",
+			sortBy, cmp, args.add(cur.Value), col.cast, args.add(cur.ID)))
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM products %s ORDER BY %s %s, id %s LIMIT %d`,
+		productColumns, whereClause(conds), sortBy, order, order, limit+1)
+
+	rows, err := pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
 	defer rows.Close()
 
-	products := make([]models.Product, 0)
+	products := make([]models.Product, 0, limit)
 
 	for rows.Next() {
-		var product models.Product
-		err := rows.Scan(
-			&product.ID,
-			&product.Name,
-			&product.Price,
-			&product.UserID,
-			&product.CreatedAt,
-			&product.UpdatedAt,
-		)
+		product, err := scanProduct(rows)
 		if err != nil {
-			return nil, fmt.Errorf("GetAllProducts: %w", err)
+			return nil, err
 		}
-		products = append(products, product)
+		products = append(products, *product)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("GetAllProducts: %w", err)
+		return nil, err
+	}
+
+	page := &models.ProductPage{Items: products, Facets: facets}
+	if len(products) > limit {
+		page.Items = products[:limit]
+		page.HasMore = true
+		last := &page.Items[limit-1]
+		page.NextCursor = encodeCursor(pageCursor{Sort: sortKey, Value: col.value(last), ID: last.ID.String()})
+	}
+
+	items := make([]*models.Product, len(page.Items))
+	for i := range page.Items {
+		items[i] = &page.Items[i]
+	}
+	if err := attachProductDetails(ctx, pool, items...); err != nil {
+		return nil, err
 	}
 
-	return products, nil
+	return page, nil
 }
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+// queryArgs collects positional arguments for dynamically built queries.
+type queryArgs []any
+
+func (a *queryArgs) add(v any) string {
+	*a = append(*a, v)
+	return "$" + strconv.Itoa(len(*a))
+}
+
+func whereClause(conds []string) string {
+	if len(conds) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(conds, " AND ")
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func prefixPattern(prefix string) string {
+	return likeEscaper.Replace(prefix) + "%"
+}
+
+type pageCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+func encodeCursor(c pageCursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeCursor rejects cursors issued for a different sort order,
+// since their keyset values would not be comparable.
+func decodeCursor(s string, sort string) (pageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return pageCursor{}, ErrInvalidCursor
+	}
+	var c pageCursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return pageCursor{}, ErrInvalidCursor
+	}
+	if _, err := uuid.Parse(c.ID); err != nil || c.Sort != sort {
+		return pageCursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"e-commerce/internal/domain/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PgRecommendationRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewRecommendationRepo(pool *pgxpool.Pool) *PgRecommendationRepo {
+	return &PgRecommendationRepo{pool: pool}
+}
+
+func collectProducts(rows pgx.Rows) ([]models.Product, error) {
+	defer rows.Close()
+	var products []models.Product
+	for rows.Next() {
+		product, err := scanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, *product)
+	}
+	return products, rows.Err()
+}
+
+// Related recommends up to limit published products to go with a
+// published product: first those its buyers also bought, then, if there
+// aren't enough, products sharing the most categories and tags with it.
+func (r *PgRecommendationRepo) Related(ctx context.Context, productID string, limit int) ([]models.RelatedProduct, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var id uuid.UUID
+	var tags []string
+	err := r.pool.QueryRow(ctx, `SELECT id, tags FROM products WHERE id = $1 AND deleted_at IS NULL AND status = 'published'`,
+		productID).Scan(&id, &tags)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDoesNotExist
+		}
+		return nil, fmt.Errorf("ListRelatedProducts: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, `
+	SELECT `+productColumns+`
+	FROM products
+	JOIN (
+		SELECT related_id, buyers FROM product_affinity
+		WHERE product_id = $1 AND buyers >= $2
+	) a ON a.related_id = products.id
+	WHERE deleted_at IS NULL AND status = 'published'
+	ORDER BY a.buyers DESC, id
+	LIMIT $3`, id, models.MinAffinityBuyers, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ListRelatedProducts: %w", err)
+	}
+	together, err := collectProducts(rows)
+	if err != nil {
+		return nil, fmt.Errorf("ListRelatedProducts: %w", err)
+	}
+
+	var similar []models.Product
+	if len(together) < limit {
+		exclude := []uuid.UUID{id}
+		for _, p := range together {
+			exclude = append(exclude, p.ID)
+		}
+		rows, err := r.pool.Query(ctx, `
+		WITH cats AS (SELECT category_id FROM product_categories WHERE product_id = $1)
+		SELECT `+productColumns+`
+		FROM products
+		WHERE deleted_at IS NULL AND status = 'published' AND id <> ALL($3)
+		AND (tags && $2::text[] OR id IN (SELECT product_id FROM product_categories WHERE category_id IN (SELECT category_id FROM cats)))
+		ORDER BY (SELECT count(*) FROM product_categories pc WHERE pc.product_id = products.id AND pc.category_id IN (SELECT category_id FROM cats))
+			+ cardinality(ARRAY(SELECT unnest(tags) INTERSECT SELECT unnest($2::text[]))) DESC,
+			created_at DESC, id
+		LIMIT $4`, id, tags, exclude, limit-len(together))
+		if err != nil {
+			return nil, fmt.Errorf("ListRelatedProducts: %w", err)
+		}
+		if similar, err = collectProducts(rows); err != nil {
+			return nil, fmt.Errorf("ListRelatedProducts: %w", err)
+		}
+	}
+
+	related := make([]models.RelatedProduct, 0, len(together)+len(similar))
+	for _, p := range together {
+		related = append(related, models.RelatedProduct{Product: p, Reason: models.RelatedBoughtTogether})
+	}
+	for _, p := range similar {
+		related = append(related, models.RelatedProduct{Product: p, Reason: models.RelatedSimilar})
+	}
+
+	products := make([]*models.Product, len(related))
+	for i := range related {
+		products[i] = &related[i].Product
+	}
+	if err := attachProductDetails(ctx, r.pool, products...); err != nil {
+		return nil, fmt.Errorf("ListRelatedProducts: %w", err)
+	}
+	if err := attachLowestPrices(ctx, r.pool, products...); err != nil {
+		return nil, fmt.Errorf("ListRelatedProducts: %w", err)
+	}
+	return related, nil
+}
+
+// RebuildAffinity recomputes product_affinity from the sales of the last
+// AffinityLookback. Until there are orders, a basket is a buyer's
+// committed reservations within CoPurchaseWindow of each other. Sales a
+// seller records by hand have no reservation and are left out. It returns
+// the number of pairs stored.
+func (r *PgRecommendationRepo) RebuildAffinity(ctx context.Context) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("RebuildProductAffinity: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM product_affinity`); err != nil {
+		return 0, fmt.Errorf("RebuildProductAffinity: %w", err)
+	}
+	tag, err := tx.Exec(ctx, `
+	WITH sales AS (
+		SELECT DISTINCT r.user_id AS buyer_id, s.product_id, m.created_at
+		FROM stock_movements m
+		JOIN stock_reservations r ON r.id = m.reservation_id AND r.status = 'committed'
+		JOIN stock_levels s ON s.id = m.stock_id
+		WHERE m.reason = 'sale' AND m.created_at > NOW() - $1::interval
+	)
+	INSERT INTO product_affinity (product_id, related_id, buyers)
+	SELECT a.product_id, b.product_id, count(DISTINCT a.buyer_id)
+	FROM sales a
+	JOIN sales b ON b.buyer_id = a.buyer_id
+		AND b.product_id <> a.product_id
+		AND b.created_at BETWEEN a.created_at - $2::interval AND a.created_at + $2::interval
+	GROUP BY a.product_id, b.product_id
+	HAVING count(DISTINCT a.buyer_id) >= $3`,
+		models.AffinityLookback, models.CoPurchaseWindow, models.MinAffinityBuyers)
+	if err != nil {
+		return 0, fmt.Errorf("RebuildProductAffinity: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("RebuildProductAffinity: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,411 @@
+package repository
+
+import (
+	"context"
+	"e-commerce/internal/domain/models"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrReviewNotFound = errors.New("review not found")
+var ErrReviewExists = errors.New("user has already reviewed this product")
+var ErrOwnProductReview = errors.New("sellers can't review their own products")
+var ErrOwnReviewVote = errors.New("authors can't vote for their own reviews")
+
+const reviewColumns = "r.id, r.product_id, r.user_id, r.rating, r.title, r.body, r.status, r.helpful_count, r.reply, r.replied_at, r.created_at, r.updated_at"
+
+// reviewSortColumn describes the keyset column of a review sort order;
+// ties are broken by id, newest first in both orders.
+type reviewSortColumn struct {
+	name  string
+	cast  string
+	value func(r *models.Review) string
+	valid func(v string) bool
+}
+
+var reviewSortColumns = map[string]reviewSortColumn{
+	models.ReviewSortRecent: {
+		name:  "created_at",
+		cast:  "timestamptz",
+		value: func(r *models.Review) string { return r.CreatedAt.Format(time.RFC3339Nano) },
+		valid: validTimestamp,
+	},
+	models.ReviewSortHelpful: {
+		name:  "helpful_count",
+		cast:  "int",
+		value: func(r *models.Review) string { return strconv.Itoa(r.HelpfulCount) },
+		valid: func(v string) bool { _, err := strconv.Atoi(v); return err == nil },
+	},
+}
+
+type PgReviewRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewReviewRepo(pool *pgxpool.Pool) *PgReviewRepo {
+	return &PgReviewRepo{pool: pool}
+}
+
+func scanReview(row pgx.Row) (*models.Review, error) {
+	var review models.Review
+	var reply *string
+	var repliedAt *time.Time
+	err := row.Scan(
+		&review.ID,
+		&review.ProductID,
+		&review.UserID,
+		&review.Rating,
+		&review.Title,
+		&review.Body,
+		&review.Status,
+		&review.HelpfulCount,
+		&reply,
+		&repliedAt,
+		&review.CreatedAt,
+		&review.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if reply != nil && repliedAt != nil {
+		review.Reply = &models.ReviewReply{Body: *reply, RepliedAt: *repliedAt}
+	}
+	return &review, nil
+}
+
+// attachRatings fills in the rating summary of all products in one query.
+func attachRatings(ctx context.Context, q querier, products ...*models.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, len(products))
+	for i, p := range products {
+		ids[i] = p.ID
+	}
+
+	rows, err := q.Query(ctx, `
+	SELECT product_id, round(rating_sum::numeric / review_count, 2)::float8, review_count
+	FROM product_ratings
+	WHERE product_id = ANY($1) AND review_count > 0`, ids)
+	if err != nil {
+		return fmt.Errorf("attachRatings: %w", err)
+	}
+	defer rows.Close()
+
+	ratings := make(map[uuid.UUID]models.ProductRating)
+	for rows.Next() {
+		var id uuid.UUID
+		var rating models.ProductRating
+		if err := rows.Scan(&id, &rating.Average, &rating.Count); err != nil {
+			return fmt.Errorf("attachRatings: %w", err)
+		}
+		ratings[id] = rating
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("attachRatings: %w", err)
+	}
+	for _, p := range products {
+		p.Rating = ratings[p.ID]
+	}
+	return nil
+}
+
+// listReviews runs a keyset-paginated query over product_reviews r. conds
+// and args carry the caller's scope.
+func listReviews(ctx context.Context, pool *pgxpool.Pool, conds []string, args queryArgs, params models.ReviewListParams) (*models.ReviewPage, error) {
+	sortBy := params.Sort
+	if sortBy == "" {
+		sortBy = models.ReviewSortRecent
+	}
+	col, ok := reviewSortColumns[sortBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown review sort %q", sortBy)
+	}
+
+	limit := params.Limit
+	if limit <= 0 {
+		limit = models.DefaultPageLimit
+	}
+	if limit > models.MaxPageLimit {
+		limit = models.MaxPageLimit
+	}
+
+	if params.Status != "" {
+		conds = append(conds, "r.status = "+args.add(params.Status))
+	}
+	if params.Cursor != "" {
+		cur, err := decodeCursor(params.Cursor, sortBy)
+		if err != nil || !col.valid(cur.Value) {
+			return nil, ErrInvalidCursor
+		}
+		conds = append(conds, fmt.Sprintf("(r.%s, r.id) < (%s::%s, %s::uuid)",
+			col.name, args.add(cur.Value), col.cast, args.add(cur.ID)))
+	}
+
+	query := fmt.Sprintf(`
+	SELECT %s
+	FROM product_reviews r
+	%s
+	ORDER BY r.%s DESC, r.id DESC
+	LIMIT %d`, reviewColumns, whereClause(conds), col.name, limit+1)
+
+	rows, err := pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reviews := make([]models.Review, 0, limit)
+	for rows.Next() {
+		review, err := scanReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, *review)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	page := &models.ReviewPage{Items: reviews}
+	if len(reviews) > limit {
+		page.Items = reviews[:limit]
+		page.HasMore = true
+		last := &page.Items[limit-1]
+		page.NextCursor = encodeCursor(pageCursor{Sort: sortBy, Value: col.value(last), ID: last.ID.String()})
+	}
+	return page, nil
+}
+
+// productExists reports whether a product matching cond, with $1 bound to
+// productID and $2 to arg, exists outside the trash.
+func productExists(ctx context.Context, q querier, cond, productID, arg string) (bool, error) {
+	var exists bool
+	err := q.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM products WHERE id = $1 AND deleted_at IS NULL AND `+cond+`)`,
+		productID, arg).Scan(&exists)
+	return exists, err
+}
+
+// ListPublished lists the approved reviews of a published product.
+func (r *PgReviewRepo) ListPublished(ctx context.Context, productID string, params models.ReviewListParams) (*models.ReviewPage, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	exists, err := productExists(ctx, r.pool, "status = $2", productID, models.ProductPublished)
+	if err != nil {
+		return nil, fmt.Errorf("ListPublishedReviews: %w", err)
+	}
+	if !exists {
+		return nil, ErrDoesNotExist
+	}
+
+	var args queryArgs
+	conds := []string{"r.product_id = " + args.add(productID)}
+	params.Status = models.ReviewApproved
+
+	page, err := listReviews(ctx, r.pool, conds, args, params)
+	if err != nil && !errors.Is(err, ErrInvalidCursor) {
+		return nil, fmt.Errorf("ListPublishedReviews: %w", err)
+	}
+	return page, err
+}
+
+// ListByProduct lists the reviews of a seller's product in any status.
+func (r *PgReviewRepo) ListByProduct(ctx context.Context, productID, sellerID string, params models.ReviewListParams) (*models.ReviewPage, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	exists, err := productExists(ctx, r.pool, "user_id = $2", productID, sellerID)
+	if err != nil {
+		return nil, fmt.Errorf("ListProductReviews: %w", err)
+	}
+	if !exists {
+		return nil, ErrDoesNotExist
+	}
+
+	var args queryArgs
+	conds := []string{"r.product_id = " + args.add(productID)}
+
+	page, err := listReviews(ctx, r.pool, conds, args, params)
+	if err != nil && !errors.Is(err, ErrInvalidCursor) {
+		return nil, fmt.Errorf("ListProductReviews: %w", err)
+	}
+	return page, err
+}
+
+// ListByUser lists the reviews userID wrote, whatever their status.
+func (r *PgReviewRepo) ListByUser(ctx context.Context, userID string, params models.ReviewListParams) (*models.ReviewPage, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var args queryArgs
+	conds := []string{"r.user_id = " + args.add(userID)}
+
+	page, err := listReviews(ctx, r.pool, conds, args, params)
+	if err != nil && !errors.Is(err, ErrInvalidCursor) {
+		return nil, fmt.Errorf("ListUserReviews: %w", err)
+	}
+	return page, err
+}
+
+// Create adds userID's review of a published product. It starts out
+// pending.
+func (r *PgReviewRepo) Create(ctx context.Context, productID, userID string, rating int, title, body string) (*models.Review, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var sellerID uuid.UUID
+	err := r.pool.QueryRow(ctx, `SELECT user_id FROM products WHERE id = $1 AND status = $2 AND deleted_at IS NULL`,
+		productID, models.ProductPublished).Scan(&sellerID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDoesNotExist
+		}
+		return nil, fmt.Errorf("CreateReview: %w", err)
+	}
+	if sellerID.String() == userID {
+		return nil, ErrOwnProductReview
+	}
+
+	review, err := scanReview(r.pool.QueryRow(ctx, `
+	INSERT INTO product_reviews AS r (product_id, user_id, rating, title, body)
+	VALUES ($1, $2, $3, $4, $5)
+	RETURNING `+reviewColumns, productID, userID, rating, title, body))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // one review per user and product
+			return nil, ErrReviewExists
+		}
+		return nil, fmt.Errorf("CreateReview: %w", err)
+	}
+	return review, nil
+}
+
+// Update rewrites the author's review and sends it back to moderation.
+func (r *PgReviewRepo) Update(ctx context.Context, reviewID, userID string, rating int, title, body string) (*models.Review, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	review, err := scanReview(r.pool.QueryRow(ctx, `
+	UPDATE product_reviews r
+	SET rating = $3, title = $4, body = $5, status = $6, updated_at = NOW()
+	WHERE r.id = $1 AND r.user_id = $2
+	RETURNING `+reviewColumns, reviewID, userID, rating, title, body, models.ReviewPending))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrReviewNotFound
+		}
+		return nil, fmt.Errorf("UpdateReview: %w", err)
+	}
+	return review, nil
+}
+
+func (r *PgReviewRepo) Delete(ctx context.Context, reviewID, userID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tag, err := r.pool.Exec(ctx, `DELETE FROM product_reviews WHERE id = $1 AND user_id = $2`, reviewID, userID)
+	if err != nil {
+		return fmt.Errorf("DeleteReview: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrReviewNotFound
+	}
+	return nil
+}
+
+// sellerReviewCond limits an UPDATE of product_reviews r to reviews of
+// sellerID's live products; $1 is the review and $2 the seller.
+const sellerReviewCond = `r.id = $1 AND r.product_id IN (SELECT id FROM products WHERE user_id = $2 AND deleted_at IS NULL)`
+
+// SetStatus approves or rejects a review of one of sellerID's products.
+func (r *PgReviewRepo) SetStatus(ctx context.Context, reviewID, sellerID, status string) (*models.Review, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	review, err := scanReview(r.pool.QueryRow(ctx, `
+	UPDATE product_reviews r SET status = $3
+	WHERE `+sellerReviewCond+`
+	RETURNING `+reviewColumns, reviewID, sellerID, status))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrReviewNotFound
+		}
+		return nil, fmt.Errorf("SetReviewStatus: %w", err)
+	}
+	return review, nil
+}
+
+// SetReply sets the seller's reply to a review of one of their products,
+// or removes it when reply is nil.
+func (r *PgReviewRepo) SetReply(ctx context.Context, reviewID, sellerID string, reply *string) (*models.Review, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	review, err := scanReview(r.pool.QueryRow(ctx, `
+	UPDATE product_reviews r
+	SET reply = $3, replied_at = CASE WHEN $3::text IS NULL THEN NULL ELSE NOW() END
+	WHERE `+sellerReviewCond+`
+	RETURNING `+reviewColumns, reviewID, sellerID, reply))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrReviewNotFound
+		}
+		return nil, fmt.Errorf("SetReviewReply: %w", err)
+	}
+	return review, nil
+}
+
+// Vote marks a public review as helpful to userID, or takes the mark back
+// when helpful is false. Voting twice counts once.
+func (r *PgReviewRepo) Vote(ctx context.Context, reviewID, userID string, helpful bool) (*models.Review, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("VoteReview: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var authorID uuid.UUID
+	err = tx.QueryRow(ctx, `
+	SELECT r.user_id FROM product_reviews r
+	JOIN products p ON p.id = r.product_id
+	WHERE r.id = $1 AND r.status = $2 AND p.status = $3 AND p.deleted_at IS NULL`,
+		reviewID, models.ReviewApproved, models.ProductPublished).Scan(&authorID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrReviewNotFound
+		}
+		return nil, fmt.Errorf("VoteReview: %w", err)
+	}
+	if authorID.String() == userID {
+		return nil, ErrOwnReviewVote
+	}
+
+	if helpful {
+		_, err = tx.Exec(ctx, `INSERT INTO product_review_votes (review_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, reviewID, userID)
+	} else {
+		_, err = tx.Exec(ctx, `DELETE FROM product_review_votes WHERE review_id = $1 AND user_id = $2`, reviewID, userID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("VoteReview: %w", err)
+	}
+
+	review, err := scanReview(tx.QueryRow(ctx, `SELECT `+reviewColumns+` FROM product_reviews r WHERE r.id = $1`, reviewID))
+	if err != nil {
+		return nil, fmt.Errorf("VoteReview: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("VoteReview: %w", err)
+	}
+	return review, nil
+}
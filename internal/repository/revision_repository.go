@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"e-commerce/internal/domain/models"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrRevisionNotFound = errors.New("product revision not found")
+
+// revertableColumns are the product fields a revert restores. Ownership,
+// timestamps and trash state are deliberately left alone.
+var revertableColumns = []string{"name", "price", "currency", "attributes", "tags"}
+
+type PgRevisionRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewRevisionRepo(pool *pgxpool.Pool) *PgRevisionRepo {
+	return &PgRevisionRepo{pool: pool}
+}
+
+// withActor runs fn in a transaction tagged with the acting user and the
+// action name, which the record_product_revision trigger copies into the
+// revision it writes. fn's error is returned unwrapped.
+func withActor(ctx context.Context, pool *pgxpool.Pool, actorID, action string, fn func(tx pgx.Tx) error) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `SELECT set_config('app.actor_id', $1, true), set_config('app.revision_action', $2, true)`, actorID, action)
+	if err != nil {
+		return fmt.Errorf("set actor: %w", err)
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// mutateProduct runs a single-row product statement returning
+// productColumns on behalf of actorID.
+func mutateProduct(ctx context.Context, pool *pgxpool.Pool, actorID, action, query string, args ...any) (*models.Product, error) {
+	var product *models.Product
+	err := withActor(ctx, pool, actorID, action, func(tx pgx.Tx) error {
+		var err error
+		product, err = scanProduct(tx.QueryRow(ctx, query, args...))
+		return err
+	})
+	return product, err
+}
+
+func (r *PgRevisionRepo) List(ctx context.Context, productID, userID string, limit int, cursor string) (*models.ProductRevisionPage, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// history stays readable while the product is in the trash
+	var exists bool
+	err := r.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM products WHERE id = $1 AND user_id = $2)`, productID, userID).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("ListRevisions: %w", err)
+	}
+	if !exists {
+		return nil, ErrDoesNotExist
+	}
+
+	if limit <= 0 {
+		limit = models.DefaultPageLimit
+	}
+	if limit > models.MaxPageLimit {
+		limit = models.MaxPageLimit
+	}
+
+	var args queryArgs
+	conds := []string{"product_id = " + args.add(productID)}
+	if cursor != "" {
+		before, err := strconv.Atoi(cursor)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		conds = append(conds, "revision < "+args.add(before))
+	}
+
+	query := fmt.Sprintf(`
+	SELECT product_id, revision, action, actor_id, before, after, created_at
+	FROM product_revisions
+	%s
+	ORDER BY revision DESC
+	LIMIT %d`, whereClause(conds), limit+1)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ListRevisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := make([]models.ProductRevision, 0, limit)
+	for rows.Next() {
+		var rev models.ProductRevision
+		err := rows.Scan(&rev.ProductID, &rev.Revision, &rev.Action, &rev.ActorID, &rev.Before, &rev.After, &rev.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("ListRevisions: %w", err)
+		}
+		revisions = append(revisions, rev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ListRevisions: %w", err)
+	}
+
+	page := &models.ProductRevisionPage{Items: revisions}
+	if len(revisions) > limit {
+		page.Items = revisions[:limit]
+		page.HasMore = true
+		page.NextCursor = strconv.Itoa(page.Items[limit-1].Revision)
+	}
+	return page, nil
+}
+
+// Revert puts the product's revertable fields back to how they were right
+// after revision. The revert itself is recorded as a new revision.
+func (r *PgRevisionRepo) Revert(ctx context.Context, productID, userID string, revision int) (*models.Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var product *models.Product
+	err := withActor(ctx, r.pool, userID, "revert", func(tx pgx.Tx) error {
+		if _, err := lockOwnedProduct(ctx, tx, productID, userID); err != nil {
+			return err
+		}
+
+		var doc []byte
+		err := tx.QueryRow(ctx, `SELECT after FROM product_revisions WHERE product_id = $1 AND revision = $2`, productID, revision).Scan(&doc)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrRevisionNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		columns := strings.Join(revertableColumns, ", ")
+		query := fmt.Sprintf(`
+		UPDATE products
+		SET (%s) = (SELECT %s FROM jsonb_populate_record(NULL::products, $2::jsonb) d)
+		WHERE id = $1
+		RETURNING %s`, columns, "d."+strings.Join(revertableColumns, ", d."), productColumns)
+
+		product, err = scanProduct(tx.QueryRow(ctx, query, productID, doc))
+		return err
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, ErrAlreadyExists
+		}
+		if errors.Is(err, ErrDoesNotExist) || errors.Is(err, ErrRevisionNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("RevertProduct: %w", err)
+	}
+
+	if err := attachProductDetails(ctx, r.pool, product); err != nil {
+		return nil, fmt.Errorf("RevertProduct: %w", err)
+	}
+	return product, nil
+}
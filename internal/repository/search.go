@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"e-commerce/internal/domain/models"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrEmptySearchQuery = errors.New("search query has no searchable terms")
+
+type searchTerm struct {
+	text   string
+	phrase bool
+	prefix bool
+}
+
+// parseSearchQuery splits user input into "quoted phrases", prefix* terms
+// and plain words. All terms are combined with AND.
+func parseSearchQuery(q string) []searchTerm {
+	var terms []searchTerm
+	for q = strings.TrimSpace(q); q != ""; q = strings.TrimSpace(q) {
+		if q[0] == '"' {
+			end := strings.IndexByte(q[1:], '"')
+			if end < 0 {
+				end = len(q) - 1
+			}
+			if phrase := strings.TrimSpace(q[1 : end+1]); phrase != "" {
+				terms = append(terms, searchTerm{text: phrase, phrase: true})
+			}
+			q = q[min(end+2, len(q)):]
+			continue
+		}
+
+		end := strings.IndexFunc(q, unicode.IsSpace)
+		if end < 0 {
+			end = len(q)
+		}
+		word := q[:end]
+		q = q[end:]
+
+		if strings.HasSuffix(word, "*") {
+			// only letters and digits reach to_tsquery, so its operator
+			// syntax can't be smuggled in through a prefix term
+			clean := strings.Map(func(r rune) rune {
+				if unicode.IsLetter(r) || unicode.IsDigit(r) {
+					return r
+				}
+				return -1
+			}, word)
+			if clean != "" {
+				terms = append(terms, searchTerm{text: clean, prefix: true})
+			}
+			continue
+		}
+		terms = append(terms, searchTerm{text: word})
+	}
+	return terms
+}
+
+func buildTSQuery(args *queryArgs, langArg string, q string) (string, error) {
+	terms := parseSearchQuery(q)
+	if len(terms) == 0 {
+		return "", ErrEmptySearchQuery
+	}
+
+	parts := make([]string, len(terms))
+	for i, t := range terms {
+		switch {
+		case t.phrase:
+			parts[i] = fmt.Sprintf("phraseto_tsquery(%s, %s)", langArg, args.add(t.text))
+		case t.prefix:
+			parts[i] = fmt.Sprintf("to_tsquery(%s, %s || ':*')", langArg, args.add(t.text))
+		default:
+			parts[i] = fmt.Sprintf("plainto_tsquery(%s, %s)", langArg, args.add(t.text))
+		}
+	}
+	return strings.Join(parts, " && "), nil
+}
+
+// searchProducts runs a ranked full-text query against search_vector,
+// restricted by the caller's conds, and returns highlighted names.
+func searchProducts(ctx context.Context, pool *pgxpool.Pool, lang string, conds []string, args queryArgs, params models.ProductSearchParams) (*models.ProductSearchPage, error) {
+	limit := params.Limit
+	if limit <= 0 {
+		limit = models.DefaultPageLimit
+	}
+	if limit > models.MaxPageLimit {
+		limit = models.MaxPageLimit
+	}
+	offset := max(params.Offset, 0)
+
+	langArg := args.add(lang) + "::regconfig"
+	tsQuery, err := buildTSQuery(&args, langArg, params.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	conds = append(conds, "deleted_at IS NULL", "search_vector @@ q.query")
+
+	query := fmt.Sprintf(`
+	WITH q AS (SELECT %s AS query)
+	SELECT %s,
+	       ts_rank(search_vector, q.query) AS rank,
+	       ts_headline(%s, name, q.query, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS highlight
+	FROM products, q
+	%s
+	ORDER BY rank DESC, id
+	LIMIT %d OFFSET %d`,
+		tsQuery, productColumns, langArg, whereClause(conds), limit+1, offset)
+
+	rows, err := pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := make([]models.ProductSearchResult, 0, limit)
+
+	for rows.Next() {
+		var res models.ProductSearchResult
+		dest := append(productScanTargets(&res.Product), &res.Rank, &res.Highlight)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		results = append(results, res)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	page := &models.ProductSearchPage{Items: results}
+	if len(results) > limit {
+		page.Items = results[:limit]
+		page.HasMore = true
+	}
+
+	// results stay light, without variants and images, but shoppers pick
+	// by rating
+	items := make([]*models.Product, len(page.Items))
+	for i := range page.Items {
+		items[i] = &page.Items[i].Product
+	}
+	if err := attachRatings(ctx, pool, items...); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
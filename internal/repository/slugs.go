@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"e-commerce/internal/domain/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var ErrSlugTaken = errors.New("slug is used by another product")
+
+// productBySlug finds the product within conds that has or once had
+// slug. The caller tells an old slug from the current one by comparing it
+// to product.Slug.
+func productBySlug(ctx context.Context, q querier, conds []string, args queryArgs, slug string) (*models.Product, error) {
+	conds = append(conds,
+		"id = (SELECT product_id FROM product_slugs WHERE slug = "+args.add(slug)+")",
+		"deleted_at IS NULL")
+	query := `SELECT ` + productColumns + ` FROM products ` + whereClause(conds)
+
+	product, err := scanProduct(q.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDoesNotExist
+		}
+		return nil, err
+	}
+	if err := attachProductDetails(ctx, q, product); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+func (r *PgProductRepo) GetBySlug(ctx context.Context, slug, userID string) (*models.Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var args queryArgs
+	conds := []string{"user_id = " + args.add(userID)}
+
+	product, err := productBySlug(ctx, r.pool, conds, args, slug)
+	if err != nil && !errors.Is(err, ErrDoesNotExist) {
+		return nil, fmt.Errorf("GetProductBySlug: %w", err)
+	}
+	return product, err
+}
+
+// SetSlug makes slug the product's current one. The old slug keeps
+// pointing at the product, and so does slug once it is replaced in turn.
+func (r *PgProductRepo) SetSlug(ctx context.Context, productID, userID, slug string, ifMatch []int) (*models.Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var product *models.Product
+	err := withActor(ctx, r.pool, userID, "slug", func(tx pgx.Tx) error {
+		var owner uuid.UUID
+		err := tx.QueryRow(ctx, `SELECT product_id FROM product_slugs WHERE slug = $1`, slug).Scan(&owner)
+		if err == nil && owner.String() != productID {
+			return ErrSlugTaken
+		}
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		query := `
+		UPDATE products SET slug = $1
+		WHERE id = $2 AND user_id = $3 AND deleted_at IS NULL AND ` + versionCond(4) + `
+		RETURNING ` + productColumns
+		product, err = scanProduct(tx.QueryRow(ctx, query, slug, productID, userID, ifMatch))
+		return err
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		switch {
+		case errors.Is(err, ErrSlugTaken):
+			return nil, err
+		case errors.As(err, &pgErr) && pgErr.Code == "23505": // claimed concurrently
+			return nil, ErrSlugTaken
+		case errors.Is(err, pgx.ErrNoRows):
+			return nil, missError(ctx, r.pool, productID, userID, ifMatch)
+		}
+		return nil, fmt.Errorf("SetProductSlug: %w", err)
+	}
+	if err := attachProductDetails(ctx, r.pool, product); err != nil {
+		return nil, fmt.Errorf("SetProductSlug: %w", err)
+	}
+	return product, nil
+}
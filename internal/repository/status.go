@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"e-commerce/internal/domain/models"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrStatusConflict = errors.New("product status does not allow this change")
+
+// statusActions name the revision recorded for a manual status change.
+var statusActions = map[string]string{
+	models.ProductPublished: "publish",
+	models.ProductDraft:     "draft",
+	models.ProductArchived:  "archive",
+}
+
+// statusMissError explains why a write guarded by the product's status
+// matched no row: the product is gone, has moved past ifMatch, or is in
+// none of the statuses the write allows.
+func statusMissError(ctx context.Context, q querier, productID, userID string, ifMatch []int) error {
+	var version int
+	err := q.QueryRow(ctx, `SELECT version FROM products WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`, productID, userID).Scan(&version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrDoesNotExist
+		}
+		return fmt.Errorf("check product status: %w", err)
+	}
+	if ifMatch != nil && !slices.Contains(ifMatch, version) {
+		return ErrVersionMismatch
+	}
+	return ErrStatusConflict
+}
+
+// SetStatus moves the product to status if it is currently in one of
+// from. Publishing drops publish_at and an unpublish_at already past;
+// leaving published drops unpublish_at, and archiving drops both.
+func (r *PgProductRepo) SetStatus(ctx context.Context, productID, userID string, from []string, status string, ifMatch []int) (*models.Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+	UPDATE products SET status = $1,
+		publish_at = CASE WHEN $1 = 'draft' THEN publish_at END,
+		unpublish_at = CASE WHEN $1 = 'published' AND unpublish_at > NOW() THEN unpublish_at END
+	WHERE id = $2 AND user_id = $3 AND deleted_at IS NULL AND status = ANY($4) AND ` + versionCond(5) + `
+	RETURNING ` + productColumns
+
+	product, err := mutateProduct(ctx, r.pool, userID, statusActions[status], query, status, productID, userID, from, ifMatch)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, statusMissError(ctx, r.pool, productID, userID, ifMatch)
+		}
+		return nil, fmt.Errorf("SetProductStatus: %w", err)
+	}
+	if err := attachProductDetails(ctx, r.pool, product); err != nil {
+		return nil, fmt.Errorf("SetProductStatus: %w", err)
+	}
+	return product, nil
+}
+
+// SetSchedule replaces the product's publish_at and unpublish_at if it is
+// currently in one of from.
+func (r *PgProductRepo) SetSchedule(ctx context.Context, productID, userID string, publishAt, unpublishAt *time.Time, from []string, ifMatch []int) (*models.Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+	UPDATE products SET publish_at = $1, unpublish_at = $2
+	WHERE id = $3 AND user_id = $4 AND deleted_at IS NULL AND status = ANY($5) AND ` + versionCond(6) + `
+	RETURNING ` + productColumns
+
+	product, err := mutateProduct(ctx, r.pool, userID, "schedule", query, publishAt, unpublishAt, productID, userID, from, ifMatch)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, statusMissError(ctx, r.pool, productID, userID, ifMatch)
+		}
+		return nil, fmt.Errorf("SetProductSchedule: %w", err)
+	}
+	if err := attachProductDetails(ctx, r.pool, product); err != nil {
+		return nil, fmt.Errorf("SetProductSchedule: %w", err)
+	}
+	return product, nil
+}
+
+// PublishDue publishes up to limit drafts whose publish_at has come and
+// returns how many it published.
+func (r *PgProductRepo) PublishDue(ctx context.Context, now time.Time, limit int) (int, error) {
+	n, err := r.applySchedule(ctx, "scheduled_publish", `
+	WITH due AS (
+		SELECT id FROM products
+		WHERE status = 'draft' AND publish_at <= $1 AND deleted_at IS NULL
+		ORDER BY publish_at
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	)
+	UPDATE products p SET status = 'published', publish_at = NULL
+	FROM due WHERE p.id = due.id`, now, limit)
+	if err != nil {
+		return 0, fmt.Errorf("PublishDueProducts: %w", err)
+	}
+	return n, nil
+}
+
+// UnpublishDue moves up to limit published products whose unpublish_at
+// has come back to draft and returns how many it moved.
+func (r *PgProductRepo) UnpublishDue(ctx context.Context, now time.Time, limit int) (int, error) {
+	n, err := r.applySchedule(ctx, "scheduled_unpublish", `
+	WITH due AS (
+		SELECT id FROM products
+		WHERE status = 'published' AND unpublish_at <= $1 AND deleted_at IS NULL
+		ORDER BY unpublish_at
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	)
+	UPDATE products p SET status = 'draft', unpublish_at = NULL
+	FROM due WHERE p.id = due.id`, now, limit)
+	if err != nil {
+		return 0, fmt.Errorf("UnpublishDueProducts: %w", err)
+	}
+	return n, nil
+}
+
+// applySchedule runs a scheduler statement with no actor, so revisions
+// show the change as automatic.
+func (r *PgProductRepo) applySchedule(ctx context.Context, action, query string, args ...any) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	var n int
+	err := withActor(ctx, r.pool, "", action, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, query, args...)
+		n = int(tag.RowsAffected())
+		return err
+	})
+	return n, err
+}
@@ -0,0 +1,410 @@
+package repository
+
+import (
+	"context"
+	"e-commerce/internal/domain/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrVariantNotFound = errors.New("variant not found")
+var ErrSKUAlreadyExists = errors.New("variant with this sku already exists")
+var ErrCurrencyMismatch = errors.New("variant price currency differs from the product currency")
+
+const variantColumns = "id, product_id, sku, price, currency, options, created_at, updated_at"
+
+type PgVariantRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewVariantRepo(pool *pgxpool.Pool) *PgVariantRepo {
+	return &PgVariantRepo{pool: pool}
+}
+
+func scanVariant(row pgx.Row) (*models.ProductVariant, error) {
+	var variant models.ProductVariant
+	var amount *int64
+	var currency *string
+	err := row.Scan(
+		&variant.ID,
+		&variant.ProductID,
+		&variant.SKU,
+		&amount,
+		&currency,
+		&variant.Options,
+		&variant.CreatedAt,
+		&variant.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if amount != nil && currency != nil {
+		variant.Price = &models.Money{Amount: *amount, Currency: *currency}
+	}
+	return &variant, nil
+}
+
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func queryOptions(ctx context.Context, q querier, productIDs []uuid.UUID) (map[uuid.UUID][]models.ProductOption, error) {
+	rows, err := q.Query(ctx, `
+	SELECT product_id, id, name, position, option_values
+	FROM product_options
+	WHERE product_id = ANY($1)
+	ORDER BY product_id, position`, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	options := make(map[uuid.UUID][]models.ProductOption)
+	for rows.Next() {
+		var productID uuid.UUID
+		var option models.ProductOption
+		if err := rows.Scan(&productID, &option.ID, &option.Name, &option.Position, &option.Values); err != nil {
+			return nil, err
+		}
+		options[productID] = append(options[productID], option)
+	}
+	return options, rows.Err()
+}
+
+func queryVariants(ctx context.Context, q querier, productIDs []uuid.UUID) (map[uuid.UUID][]models.ProductVariant, error) {
+	rows, err := q.Query(ctx, `
+	SELECT `+variantColumns+`
+	FROM product_variants
+	WHERE product_id = ANY($1)
+	ORDER BY product_id, sku`, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	variants := make(map[uuid.UUID][]models.ProductVariant)
+	for rows.Next() {
+		variant, err := scanVariant(rows)
+		if err != nil {
+			return nil, err
+		}
+		variants[variant.ProductID] = append(variants[variant.ProductID], *variant)
+	}
+	return variants, rows.Err()
+}
+
+// attachVariants loads options and variants for all products in two
+// queries and embeds them into the products.
+func attachVariants(ctx context.Context, q querier, products ...*models.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, len(products))
+	for i, p := range products {
+		ids[i] = p.ID
+	}
+
+	options, err := queryOptions(ctx, q, ids)
+	if err != nil {
+		return fmt.Errorf("attachVariants: %w", err)
+	}
+	variants, err := queryVariants(ctx, q, ids)
+	if err != nil {
+		return fmt.Errorf("attachVariants: %w", err)
+	}
+
+	for _, p := range products {
+		p.Options = options[p.ID]
+		p.Variants = variants[p.ID]
+	}
+	return nil
+}
+
+func lockOwnedProduct(ctx context.Context, tx pgx.Tx, productID, userID string) (string, error) {
+	var name string
+	err := tx.QueryRow(ctx, `SELECT name FROM products WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL FOR UPDATE`, productID, userID).Scan(&name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrDoesNotExist
+	}
+	return name, err
+}
+
+func (r *PgVariantRepo) GetByProduct(ctx context.Context, productID, userID string) (*models.ProductVariants, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var id uuid.UUID
+	err := r.pool.QueryRow(ctx, `SELECT id FROM products WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`, productID, userID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDoesNotExist
+		}
+		return nil, fmt.Errorf("GetProductVariants: %w", err)
+	}
+
+	result, err := productVariants(ctx, r.pool, id)
+	if err != nil {
+		return nil, fmt.Errorf("GetProductVariants: %w", err)
+	}
+	return result, nil
+}
+
+func productVariants(ctx context.Context, q querier, productID uuid.UUID) (*models.ProductVariants, error) {
+	ids := []uuid.UUID{productID}
+	options, err := queryOptions(ctx, q, ids)
+	if err != nil {
+		return nil, err
+	}
+	variants, err := queryVariants(ctx, q, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.ProductVariants{
+		Options:  options[productID],
+		Variants: variants[productID],
+	}
+	if result.Options == nil {
+		result.Options = []models.ProductOption{}
+	}
+	if result.Variants == nil {
+		result.Variants = []models.ProductVariant{}
+	}
+	return result, nil
+}
+
+// SetOptions replaces the product's option definitions and reconciles its
+// variants with combos: variants for combinations that no longer exist are
+// removed, surviving ones keep their SKU and price, and missing ones are
+// created with a generated SKU.
+func (r *PgVariantRepo) SetOptions(ctx context.Context, productID, userID string, options []models.ProductOption, combos []map[string]string) (*models.ProductVariants, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("SetProductOptions: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	productName, err := lockOwnedProduct(ctx, tx, productID, userID)
+	if err != nil {
+		if errors.Is(err, ErrDoesNotExist) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("SetProductOptions: %w", err)
+	}
+
+	var inBundle bool
+	err = tx.QueryRow(ctx, `
+	SELECT EXISTS (SELECT 1 FROM product_bundles WHERE product_id = $1)
+		OR EXISTS (SELECT 1 FROM product_bundle_items WHERE component_id = $1)`, productID).Scan(&inBundle)
+	if err != nil {
+		return nil, fmt.Errorf("SetProductOptions: %w", err)
+	}
+	if inBundle && len(options) > 0 {
+		return nil, ErrBundleVariants
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM product_options WHERE product_id = $1`, productID); err != nil {
+		return nil, fmt.Errorf("SetProductOptions: %w", err)
+	}
+	for i, option := range options {
+		_, err := tx.Exec(ctx, `
+		INSERT INTO product_options (product_id, name, position, option_values)
+		VALUES ($1, $2, $3, $4)`, productID, option.Name, i, option.Values)
+		if err != nil {
+			return nil, fmt.Errorf("SetProductOptions: %w", err)
+		}
+	}
+
+	wanted := make(map[string]map[string]string, len(combos))
+	for _, combo := range combos {
+		key, _ := json.Marshal(combo)
+		wanted[string(key)] = combo
+	}
+
+	rows, err := tx.Query(ctx, `SELECT id, options FROM product_variants WHERE product_id = $1`, productID)
+	if err != nil {
+		return nil, fmt.Errorf("SetProductOptions: %w", err)
+	}
+	var stale []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		var combo map[string]string
+		if err := rows.Scan(&id, &combo); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("SetProductOptions: %w", err)
+		}
+		key, _ := json.Marshal(combo)
+		if _, ok := wanted[string(key)]; ok {
+			delete(wanted, string(key))
+		} else {
+			stale = append(stale, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("SetProductOptions: %w", err)
+	}
+
+	if len(stale) > 0 {
+		if _, err := tx.Exec(ctx, `DELETE FROM product_variants WHERE id = ANY($1)`, stale); err != nil {
+			return nil, fmt.Errorf("SetProductOptions: %w", err)
+		}
+	}
+
+	// insert in the caller's order so generated SKUs are deterministic
+	for _, combo := range combos {
+		key, _ := json.Marshal(combo)
+		if _, ok := wanted[string(key)]; !ok {
+			continue
+		}
+		values := make([]string, len(options))
+		for i, option := range options {
+			values[i] = combo[option.Name]
+		}
+		if err := insertGeneratedVariant(ctx, tx, productID, userID, generateSKU(productName, values), combo); err != nil {
+			return nil, fmt.Errorf("SetProductOptions: %w", err)
+		}
+	}
+
+	id, _ := uuid.Parse(productID)
+	result, err := productVariants(ctx, tx, id)
+	if err != nil {
+		return nil, fmt.Errorf("SetProductOptions: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("SetProductOptions: %w", err)
+	}
+	return result, nil
+}
+
+// insertGeneratedVariant falls back to a suffixed SKU when the generated
+// one is already taken by another of the seller's variants.
+func insertGeneratedVariant(ctx context.Context, tx pgx.Tx, productID, userID, sku string, combo map[string]string) error {
+	id := uuid.New()
+	query := `
+	INSERT INTO product_variants (id, product_id, user_id, sku, options)
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (user_id, sku) DO NOTHING`
+
+	result, err := tx.Exec(ctx, query, id, productID, userID, sku, combo)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 1 {
+		return nil
+	}
+
+	sku += "-" + strings.ToUpper(id.String()[:6])
+	result, err = tx.Exec(ctx, query, id, productID, userID, sku, combo)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrSKUAlreadyExists
+	}
+	return nil
+}
+
+// generateSKU builds codes like "TSHIRT-M-RED" from the product name and
+// the variant's option values.
+func generateSKU(productName string, values []string) string {
+	parts := []string{skuPart(productName, 12)}
+	for _, v := range values {
+		parts = append(parts, skuPart(v, 8))
+	}
+	return strings.Join(parts, "-")
+}
+
+func skuPart(s string, maxLen int) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(s) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+			if b.Len() == maxLen {
+				break
+			}
+		}
+	}
+	if b.Len() == 0 {
+		return "X"
+	}
+	return b.String()
+}
+
+func (r *PgVariantRepo) UpdateVariant(ctx context.Context, productID, variantID, userID, sku string, price *models.Money) (*models.ProductVariant, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var amount *int64
+	var currency *string
+	if price != nil {
+		amount, currency = &price.Amount, &price.Currency
+	}
+
+	// the override must be in the product's own currency
+	query := `
+	UPDATE product_variants v
+	SET sku = $1, price = $2, currency = $3
+	FROM products p
+	WHERE v.id = $4 AND v.product_id = $5 AND v.user_id = $6
+	  AND p.id = v.product_id AND p.deleted_at IS NULL AND ($3::char(3) IS NULL OR p.currency = $3)
+	RETURNING v.id, v.product_id, v.sku, v.price, v.currency, v.options, v.created_at, v.updated_at`
+
+	variant, err := scanVariant(r.pool.QueryRow(ctx, query, sku, amount, currency, variantID, productID, userID))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, ErrSKUAlreadyExists
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("UpdateVariant: %w", err)
+		}
+		var exists bool
+		err := r.pool.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM product_variants v JOIN products p ON p.id = v.product_id
+			WHERE v.id = $1 AND v.product_id = $2 AND v.user_id = $3 AND p.deleted_at IS NULL
+		)`,
+			variantID, productID, userID).Scan(&exists)
+		if err != nil {
+			return nil, fmt.Errorf("UpdateVariant: %w", err)
+		}
+		if exists {
+			return nil, ErrCurrencyMismatch
+		}
+		return nil, ErrVariantNotFound
+	}
+	return variant, nil
+}
+
+func (r *PgVariantRepo) DeleteVariant(ctx context.Context, productID, variantID, userID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.pool.Exec(ctx, `
+	DELETE FROM product_variants v
+	USING products p
+	WHERE v.id = $1 AND v.product_id = $2 AND v.user_id = $3
+	  AND p.id = v.product_id AND p.deleted_at IS NULL`, variantID, productID, userID)
+	if err != nil {
+		return fmt.Errorf("DeleteVariant: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrVariantNotFound
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"e-commerce/internal/domain/models"
+	"e-commerce/internal/repository"
+	"e-commerce/internal/utils/xgin"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxAttributeFilters bounds the attr[...] parameters of a listing.
+const maxAttributeFilters = 10
+
+type AttributeDefinitionRequest struct {
+	Key      string `json:"key" binding:"required,attribute_key"`
+	Type     string `json:"type" binding:"required,oneof=string number integer boolean"`
+	Required bool   `json:"required"`
+	Enum     []any  `json:"enum" binding:"omitempty,max=100"`
+	Unit     string `json:"unit" binding:"omitempty,max=20"`
+}
+
+type CategoryAttributesRequest struct {
+	Attributes []AttributeDefinitionRequest `json:"attributes" binding:"required,max=50,unique=Key,dive"`
+}
+
+// definitions converts the request, reporting the first enum that doesn't
+// fit its attribute's type.
+func (r CategoryAttributesRequest) definitions() ([]models.AttributeDefinition, string, string) {
+	defs := make([]models.AttributeDefinition, len(r.Attributes))
+	for i, a := range r.Attributes {
+		defs[i] = models.AttributeDefinition{Key: a.Key, Type: a.Type, Required: a.Required, Enum: a.Enum, Unit: a.Unit}
+		if msg := defs[i].EnumError(); msg != "" {
+			return nil, fmt.Sprintf("attributes[%d].enum", i), msg
+		}
+	}
+	return defs, "", ""
+}
+
+// attributeFilters reads attr[key]=value query parameters.
+func attributeFilters(query map[string]string) (map[string]string, string, error) {
+	if len(query) == 0 {
+		return nil, "", nil
+	}
+	if len(query) > maxAttributeFilters {
+		return nil, "attr", fmt.Errorf("at most %d attribute filters are allowed", maxAttributeFilters)
+	}
+	for key, value := range query {
+		field := "attr[" + key + "]"
+		if !models.ValidAttributeKey(key) {
+			return nil, field, errors.New("unknown attribute name")
+		}
+		if len(value) > models.MaxAttributeValueLength {
+			return nil, field, fmt.Errorf("must be at most %d characters long", models.MaxAttributeValueLength)
+		}
+	}
+	return query, "", nil
+}
+
+// sameAttributes treats a missing attribute set as an empty one.
+func sameAttributes(a, b map[string]any) bool {
+	return len(a) == 0 && len(b) == 0 || reflect.DeepEqual(a, b)
+}
+
+func attributesProblem(err *repository.AttributesError) *xgin.RfcValidationError {
+	problem := xgin.Problem(http.StatusUnprocessableEntity, "Validation error", "Attributes don't match the product's category definitions")
+	problem.Type = "https://example.com/errors/validation"
+	for _, v := range err.Violations {
+		problem.Errors = append(problem.Errors, xgin.RfcFieldError{Field: "attributes." + v.Key, Message: v.Message})
+	}
+	return problem
+}
+
+// attributesError responds with the attributes that break the category
+// definitions, if that is what err is about.
+func attributesError(c *gin.Context, err error) bool {
+	var attrErr *repository.AttributesError
+	if !errors.As(err, &attrErr) {
+		return false
+	}
+	problem := attributesProblem(attrErr)
+	c.JSON(problem.Status, problem)
+	return true
+}
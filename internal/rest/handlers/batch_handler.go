@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"e-commerce/internal/domain/models"
+	"e-commerce/internal/repository"
+	"e-commerce/internal/utils/xgin"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type BatchOperationRequest struct {
+	Op string `json:"op" binding:"required,oneof=create update patch delete"`
+	ID string `json:"id" binding:"required_unless=Op create,omitempty,uuid"`
+	// IfMatch takes the same value as the If-Match header, e.g. "\"3\"".
+	IfMatch string          `json:"if_match"`
+	Body    json.RawMessage `json:"body"`
+}
+
+type BatchRequest struct {
+	ContinueOnError bool                    `json:"continue_on_error"`
+	Operations      []BatchOperationRequest `json:"operations" binding:"required,min=1,max=500,dive"`
+}
+
+// BatchOperationResult carries the status and body the operation would
+// have got from its single endpoint: the product, or a problem detail.
+type BatchOperationResult struct {
+	Index   int                      `json:"index"`
+	Op      string                   `json:"op"`
+	Status  int                      `json:"status"`
+	Product *models.Product          `json:"product,omitempty"`
+	Error   *xgin.RfcValidationError `json:"error,omitempty"`
+}
+
+type BatchResponse struct {
+	Committed bool                   `json:"committed"`
+	Results   []BatchOperationResult `json:"results"`
+}
+
+var batchSuccessStatus = map[string]int{
+	"create": http.StatusCreated,
+	"update": http.StatusOK,
+	"patch":  http.StatusOK,
+	"delete": http.StatusNoContent,
+}
+
+func operationProblem(err error) *xgin.RfcValidationError {
+	var attrErr *repository.AttributesError
+	switch {
+	case errors.As(err, &attrErr):
+		return attributesProblem(attrErr)
+	case errors.Is(err, repository.ErrDoesNotExist):
+		return xgin.Problem(http.StatusNotFound, "Not found", "Product not found")
+	case errors.Is(err, repository.ErrAlreadyExists):
+		return xgin.Problem(http.StatusConflict, "Conflict", "A product with the same name and price already exists")
+	case errors.Is(err, repository.ErrVersionMismatch):
+		return xgin.Problem(http.StatusPreconditionFailed, "Precondition failed", "Product was modified since the given version")
+	case errors.Is(err, repository.ErrProductInBundle):
+		return xgin.Problem(http.StatusConflict, "Conflict", "The product is part of a bundle; remove it from the bundle first")
+	default:
+		return xgin.Problem(http.StatusUnprocessableEntity, "Validation error", err.Error())
+	}
+}
+
+// decodeOperation validates one operation's body with the binding rules of
+// its single endpoint.
+func decodeOperation(req BatchOperationRequest, requireIfMatch bool) (models.ProductOperation, *xgin.RfcValidationError) {
+	op := models.ProductOperation{Op: req.Op, ID: req.ID, IfMatch: parseIfMatch(req.IfMatch)}
+
+	if requireIfMatch && req.Op != "create" && req.IfMatch == "" {
+		return op, xgin.Problem(http.StatusPreconditionRequired, "Precondition required", "Send the product's ETag in if_match")
+	}
+
+	switch req.Op {
+	case "create", "update":
+		var input ProductRequest
+		if err := bindOperationBody(req.Body, &input); err != nil {
+			return op, xgin.BindProblem(err)
+		}
+		op.Name, op.Price, op.Attributes, op.Tags = input.Name, input.Price, input.Attributes, input.Tags
+	case "patch":
+		var input PatchProductRequest
+		if err := bindOperationBody(req.Body, &input); err != nil {
+			return op, xgin.BindProblem(err)
+		}
+		op.Updates = make(map[string]any)
+		if input.Name != nil {
+			op.Updates["name"] = *input.Name
+		}
+		if input.Price != nil {
+			op.Updates["price"] = *input.Price
+		}
+		if input.Attributes != nil {
+			op.Updates["attributes"] = input.Attributes
+		}
+		if input.Tags != nil {
+			op.Updates["tags"] = input.Tags
+		}
+	}
+	return op, nil
+}
+
+func bindOperationBody(body json.RawMessage, obj any) error {
+	if len(body) == 0 {
+		body = json.RawMessage("{}")
+	}
+	if err := json.Unmarshal(body, obj); err != nil {
+		return err
+	}
+	return binding.Validator.ValidateStruct(obj)
+}
+
+func BatchProductsHandler(svc productService, requireIfMatch bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		var input BatchRequest
+		if err := c.ShouldBindJSON(&input); err != nil {
+			xgin.BindError(c, err)
+			return
+		}
+
+		results := make([]BatchOperationResult, len(input.Operations))
+		var ops []models.ProductOperation
+		var opIndex []int
+		invalid := false
+		for i, req := range input.Operations {
+			results[i] = BatchOperationResult{Index: i, Op: req.Op}
+			op, problem := decodeOperation(req, requireIfMatch)
+			if problem != nil {
+				results[i].Status, results[i].Error = problem.Status, problem
+				invalid = true
+				continue
+			}
+			ops = append(ops, op)
+			opIndex = append(opIndex, i)
+		}
+
+		// all-or-nothing: an invalid operation means nothing is attempted
+		if invalid && !input.ContinueOnError {
+			skipOperations(results, func(int) string { return "Not applied because another operation is invalid" })
+			c.JSON(http.StatusOK, BatchResponse{Committed: false, Results: results})
+			return
+		}
+
+		committed := true
+		if len(ops) > 0 {
+			var opResults []models.ProductOperationResult
+			var err error
+			opResults, committed, err = svc.Batch(c.Request.Context(), userID, ops, input.ContinueOnError)
+			if err != nil {
+				log.Printf("[ERROR] BatchProductsHandler: %v", err)
+				xgin.InternalError(c)
+				return
+			}
+
+			failed := -1
+			for j, r := range opResults {
+				res := &results[opIndex[j]]
+				switch {
+				case r.Err != nil:
+					res.Error = operationProblem(r.Err)
+					res.Status = res.Error.Status
+					failed = opIndex[j]
+				case !committed:
+					// filled in below, once the failed operation is known
+				default:
+					res.Status = batchSuccessStatus[res.Op]
+					res.Product = r.Product
+				}
+			}
+			if !committed {
+				skipOperations(results, func(i int) string {
+					if i < failed {
+						return fmt.Sprintf("Rolled back because operation %d failed", failed)
+					}
+					return fmt.Sprintf("Not applied because operation %d failed", failed)
+				})
+			}
+		}
+
+		c.JSON(http.StatusOK, BatchResponse{Committed: committed, Results: results})
+	}
+}
+
+// skipOperations marks every operation without an error of its own as
+// not applied, with detail explaining why.
+func skipOperations(results []BatchOperationResult, detail func(i int) string) {
+	for i := range results {
+		if results[i].Error == nil {
+			results[i].Product = nil
+			results[i].Error = xgin.Problem(http.StatusFailedDependency, "Failed dependency", detail(i))
+			results[i].Status = http.StatusFailedDependency
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"e-commerce/internal/domain/models"
+	"e-commerce/internal/repository"
+	"e-commerce/internal/utils/xgin"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type BundleItemRequest struct {
+	ProductID string `json:"product_id" binding:"required,uuid"`
+	Quantity  int    `json:"quantity" binding:"required,min=1,max=100"`
+}
+
+// BundleRequest replaces the bundle's composition. The bundle is priced
+// either at price or at its items' total less discount_percent.
+type BundleRequest struct {
+	Items           []BundleItemRequest `json:"items" binding:"required,min=1,max=20,unique=ProductID,dive"`
+	Price           *models.Money       `json:"price" binding:"omitempty,money"`
+	DiscountPercent *int                `json:"discount_percent" binding:"omitempty,min=1,max=99"`
+}
+
+func (r BundleRequest) items() []models.BundleItem {
+	items := make([]models.BundleItem, len(r.Items))
+	for i, item := range r.Items {
+		items[i] = models.BundleItem{ProductID: uuid.MustParse(item.ProductID), Quantity: item.Quantity}
+	}
+	return items
+}
+
+func bundleError(c *gin.Context, svc productService, err error, productID, userID, handler string) {
+	switch {
+	case errors.Is(err, repository.ErrDoesNotExist):
+		xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Product not found")
+	case errors.Is(err, repository.ErrNotBundle):
+		xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Product is not a bundle")
+	case errors.Is(err, repository.ErrVersionMismatch):
+		preconditionFailed(c, svc, productID, userID, handler)
+	case errors.Is(err, repository.ErrAlreadyExists):
+		xgin.ErrorResponse(c, http.StatusConflict, "Conflict", "A product with the same name and price already exists")
+	case errors.Is(err, repository.ErrBundleContainsItself):
+		xgin.FieldError(c, "items", "must not contain the bundle itself")
+	case errors.Is(err, repository.ErrBundleComponentNotFound):
+		xgin.FieldError(c, "items", "must all be your own products that aren't deleted")
+	case errors.Is(err, repository.ErrBundleNesting):
+		xgin.ErrorResponse(c, http.StatusUnprocessableEntity, "Validation error", "Bundles can't contain bundles, and a bundle component can't become a bundle")
+	case errors.Is(err, repository.ErrBundleVariants):
+		xgin.ErrorResponse(c, http.StatusUnprocessableEntity, "Validation error", "Bundles and their components can't have variants")
+	case errors.Is(err, repository.ErrBundleCurrency):
+		xgin.FieldError(c, "items", "must all be priced in the bundle's currency")
+	case errors.Is(err, repository.ErrBundlePriceTooHigh):
+		xgin.FieldError(c, "price", "must be below the items' total")
+	default:
+		log.Printf("[ERROR] %s: %v", handler, err)
+		xgin.InternalError(c)
+	}
+}
+
+func GetProductBundleHandler(svc bundleService, products productService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		bundle, err := svc.Get(c.Request.Context(), idStr, userID)
+		if err != nil {
+			bundleError(c, products, err, idStr, userID, "GetProductBundleHandler")
+			return
+		}
+		c.JSON(http.StatusOK, bundle)
+	}
+}
+
+func SetProductBundleHandler(svc bundleService, products productService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		var input BundleRequest
+		if err := c.ShouldBindJSON(&input); err != nil {
+			xgin.BindError(c, err)
+			return
+		}
+		if (input.Price == nil) == (input.DiscountPercent == nil) {
+			xgin.FieldError(c, "price", "exactly one of price and discount_percent is required")
+			return
+		}
+
+		product, err := svc.Set(c.Request.Context(), idStr, userID, input.items(), input.Price, input.DiscountPercent, ifMatchVersions(c))
+		if err != nil {
+			bundleError(c, products, err, idStr, userID, "SetProductBundleHandler")
+			return
+		}
+		setProductETag(c, product)
+
+		c.JSON(http.StatusOK, product)
+	}
+}
+
+func DeleteProductBundleHandler(svc bundleService, products productService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		if err := svc.Remove(c.Request.Context(), idStr, userID); err != nil {
+			bundleError(c, products, err, idStr, userID, "DeleteProductBundleHandler")
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"e-commerce/internal/domain/models"
+	"e-commerce/internal/repository"
+	"e-commerce/internal/utils/xgin"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CatalogQuery struct {
+	ListProductsQuery
+	SellerID string `form:"seller_id" binding:"omitempty,uuid"`
+}
+
+// CatalogProductResponse is the public projection of a product. Only
+// fields that are safe to show to anonymous shoppers belong here.
+type CatalogProductResponse struct {
+	ID         string         `json:"id"`
+	Name       string         `json:"name"`
+	Slug       string         `json:"slug"`
+	Type       string         `json:"type"`
+	Price      models.Money   `json:"price"`
+	Attributes map[string]any `json:"attributes,omitempty"`
+	Tags       []string       `json:"tags"`
+	SellerID   string         `json:"seller_id"`
+	CreatedAt  time.Time      `json:"created_at"`
+
+	ConvertedPrice *models.ConvertedPrice `json:"converted_price,omitempty"`
+	// LowestPrice30d is the lowest price in the 30 days before the current
+	// one took effect, for showing next to a reduced price.
+	LowestPrice30d *models.Money `json:"lowest_price_30d,omitempty"`
+
+	Rating models.ProductRating `json:"rating"`
+
+	Options  []models.ProductOption  `json:"options,omitempty"`
+	Variants []models.ProductVariant `json:"variants,omitempty"`
+	Images   []models.ProductImage   `json:"images,omitempty"`
+	Bundle   *models.ProductBundle   `json:"bundle,omitempty"`
+}
+
+type CatalogSearchResultResponse struct {
+	CatalogProductResponse
+	Rank      float32 `json:"rank"`
+	Highlight string  `json:"highlight"`
+}
+
+type CatalogSearchPageResponse struct {
+	Items   []CatalogSearchResultResponse `json:"items"`
+	HasMore bool                          `json:"has_more"`
+}
+
+type CatalogPageResponse struct {
+	Items      []CatalogProductResponse `json:"items"`
+	NextCursor string                   `json:"next_cursor,omitempty"`
+	HasMore    bool                     `json:"has_more"`
+	Facets     *models.ProductFacets    `json:"facets,omitempty"`
+}
+
+func newCatalogProductResponse(p *models.Product) CatalogProductResponse {
+	return CatalogProductResponse{
+		ID:         p.ID.String(),
+		Name:       p.Name,
+		Slug:       p.Slug,
+		Type:       p.Type,
+		Price:      p.Price,
+		Attributes: p.Attributes,
+		Tags:       p.Tags,
+		SellerID:   p.UserID.String(),
+		CreatedAt:  p.CreatedAt,
+		Options:    p.Options,
+		Variants:   p.Variants,
+		Images:     p.Images,
+		Bundle:     p.Bundle,
+
+		ConvertedPrice: p.ConvertedPrice,
+		LowestPrice30d: p.LowestPrice30d,
+		Rating:         p.Rating,
+	}
+}
+
+func newCatalogPageResponse(page *models.ProductPage) CatalogPageResponse {
+	resp := CatalogPageResponse{
+		Items:      make([]CatalogProductResponse, len(page.Items)),
+		NextCursor: page.NextCursor,
+		HasMore:    page.HasMore,
+		Facets:     page.Facets,
+	}
+	for i := range page.Items {
+		resp.Items[i] = newCatalogProductResponse(&page.Items[i])
+	}
+	return resp
+}
+
+func GetCatalogProductHandler(svc catalogService, rates priceConverter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		currency, ok := requestedCurrency(c)
+		if !ok {
+			return
+		}
+
+		product, err := svc.GetByID(c.Request.Context(), idStr)
+		if err != nil {
+			if errors.Is(err, repository.ErrDoesNotExist) {
+				xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Product not found")
+				return
+			}
+			log.Printf("[ERROR] GetCatalogProductHandler: %v", err)
+			xgin.InternalError(c)
+			return
+		}
+
+		if !convertPrices(c, rates, currency, "GetCatalogProductHandler", product) {
+			return
+		}
+		setProductETag(c, product)
+
+		c.JSON(http.StatusOK, newCatalogProductResponse(product))
+	}
+}
+
+func ListCatalogCategoryProductsHandler(svc catalogService, rates priceConverter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		var query ListProductsQuery
+		params, ok := bindListQuery(c, &query, &query)
+		if !ok {
+			return
+		}
+
+		currency, ok := requestedCurrency(c)
+		if !ok {
+			return
+		}
+
+		page, err := svc.ListByCategory(c.Request.Context(), idStr, params)
+		if err != nil {
+			if errors.Is(err, repository.ErrCategoryNotFound) {
+				xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Category not found")
+				return
+			}
+			if errors.Is(err, repository.ErrInvalidCursor) {
+				xgin.ErrorResponse(c, http.StatusBadRequest, "Bad request", "Invalid cursor")
+				return
+			}
+			log.Printf("[ERROR] ListCatalogCategoryProductsHandler: %v", err)
+			xgin.InternalError(c)
+			return
+		}
+
+		if !convertPrices(c, rates, currency, "ListCatalogCategoryProductsHandler", pageProducts(page.Items)...) {
+			return
+		}
+
+		c.JSON(http.StatusOK, newCatalogPageResponse(page))
+	}
+}
+
+func ListCatalogProductsHandler(svc catalogService, rates priceConverter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query CatalogQuery
+		params, ok := bindListQuery(c, &query, &query.ListProductsQuery)
+		if !ok {
+			return
+		}
+
+		currency, ok := requestedCurrency(c)
+		if !ok {
+			return
+		}
+
+		page, err := svc.List(c.Request.Context(), query.SellerID, params)
+		if err != nil {
+			if errors.Is(err, repository.ErrInvalidCursor) {
+				xgin.ErrorResponse(c, http.StatusBadRequest, "Bad request", "Invalid cursor")
+				return
+			}
+			log.Printf("[ERROR] ListCatalogProductsHandler: %v", err)
+			xgin.InternalError(c)
+			return
+		}
+
+		if !convertPrices(c, rates, currency, "ListCatalogProductsHandler", pageProducts(page.Items)...) {
+			return
+		}
+
+		c.JSON(http.StatusOK, newCatalogPageResponse(page))
+	}
+}
+
+func SearchCatalogProductsHandler(svc catalogService, rates priceConverter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query SearchProductsQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			xgin.BindError(c, err)
+			return
+		}
+
+		currency, ok := requestedCurrency(c)
+		if !ok {
+			return
+		}
+
+		page, err := svc.Search(c.Request.Context(), query.params())
+		if err != nil {
+			if errors.Is(err, repository.ErrEmptySearchQuery) {
+				xgin.ErrorResponse(c, http.StatusBadRequest, "Bad request", "Search query has no searchable terms")
+				return
+			}
+			log.Printf("[ERROR] SearchCatalogProductsHandler: %v", err)
+			xgin.InternalError(c)
+			return
+		}
+
+		if !convertPrices(c, rates, currency, "SearchCatalogProductsHandler", searchProducts(page.Items)...) {
+			return
+		}
+
+		resp := CatalogSearchPageResponse{
+			Items:   make([]CatalogSearchResultResponse, len(page.Items)),
+			HasMore: page.HasMore,
+		}
+		for i := range page.Items {
+			item := &page.Items[i]
+			resp.Items[i] = CatalogSearchResultResponse{
+				CatalogProductResponse: newCatalogProductResponse(&item.Product),
+				Rank:                   item.Rank,
+				Highlight:              item.Highlight,
+			}
+		}
+
+		c.JSON(http.StatusOK, resp)
+	}
+}
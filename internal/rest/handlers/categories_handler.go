@@ -0,0 +1,316 @@
+package handlers
+
+import (
+	"e-commerce/internal/repository"
+	"e-commerce/internal/utils/xgin"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CategoryRequest struct {
+	Name     string  `json:"name" binding:"required,min=2,max=100"`
+	ParentID *string `json:"parent_id" binding:"omitempty,uuid"`
+}
+
+type DeleteCategoryQuery struct {
+	Cascade bool `form:"cascade"`
+}
+
+type ProductCategoriesRequest struct {
+	CategoryIDs []string `json:"category_ids" binding:"required,unique,dive,uuid"`
+}
+
+func categoryError(c *gin.Context, handler string, err error) {
+	if attributesError(c, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, repository.ErrCategoryNotFound):
+		xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Category not found")
+	case errors.Is(err, repository.ErrDoesNotExist):
+		xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Product not found")
+	case errors.Is(err, repository.ErrCategoryAlreadyExists):
+		xgin.ErrorResponse(c, http.StatusConflict, "Conflict", "Category with this name already exists under the same parent")
+	case errors.Is(err, repository.ErrCategoryCycle):
+		xgin.ErrorResponse(c, http.StatusConflict, "Conflict", "Category cannot be moved under itself or its descendants")
+	case errors.Is(err, repository.ErrCategoryHasChildren):
+		xgin.ErrorResponse(c, http.StatusConflict, "Conflict", "Category has subcategories; delete them first or use cascade=true")
+	case errors.Is(err, repository.ErrInvalidCursor):
+		xgin.ErrorResponse(c, http.StatusBadRequest, "Bad request", "Invalid cursor")
+	default:
+		log.Printf("[ERROR] %s: %v", handler, err)
+		xgin.InternalError(c)
+	}
+}
+
+func CreateCategoryHandler(svc categoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		var input CategoryRequest
+		if err := c.ShouldBindJSON(&input); err != nil {
+			xgin.BindError(c, err)
+			return
+		}
+
+		category, err := svc.Create(c.Request.Context(), userID, input.Name, input.ParentID)
+		if err != nil {
+			categoryError(c, "CreateCategoryHandler", err)
+			return
+		}
+		c.JSON(http.StatusCreated, category)
+	}
+}
+
+func GetCategoryByIdHandler(svc categoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		category, err := svc.GetByID(c.Request.Context(), idStr, userID)
+		if err != nil {
+			categoryError(c, "GetCategoryByIdHandler", err)
+			return
+		}
+		c.JSON(http.StatusOK, category)
+	}
+}
+
+func GetAllCategoriesHandler(svc categoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		categories, err := svc.GetAll(c.Request.Context(), userID)
+		if err != nil {
+			categoryError(c, "GetAllCategoriesHandler", err)
+			return
+		}
+		c.JSON(http.StatusOK, categories)
+	}
+}
+
+func UpdateCategoryHandler(svc categoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		var input CategoryRequest
+		if err := c.ShouldBindJSON(&input); err != nil {
+			xgin.BindError(c, err)
+			return
+		}
+
+		category, err := svc.Update(c.Request.Context(), idStr, userID, input.Name, input.ParentID)
+		if err != nil {
+			categoryError(c, "UpdateCategoryHandler", err)
+			return
+		}
+		c.JSON(http.StatusOK, category)
+	}
+}
+
+func DeleteCategoryHandler(svc categoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		var query DeleteCategoryQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			xgin.BindError(c, err)
+			return
+		}
+
+		if err := svc.Delete(c.Request.Context(), idStr, userID, query.Cascade); err != nil {
+			categoryError(c, "DeleteCategoryHandler", err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func ListCategoryProductsHandler(svc categoryService, rates priceConverter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		var query ListProductsQuery
+		params, ok := bindListQuery(c, &query, &query)
+		if !ok {
+			return
+		}
+
+		currency, ok := requestedCurrency(c)
+		if !ok {
+			return
+		}
+
+		page, err := svc.ListProducts(c.Request.Context(), idStr, userID, params)
+		if err != nil {
+			categoryError(c, "ListCategoryProductsHandler", err)
+			return
+		}
+
+		if !convertPrices(c, rates, currency, "ListCategoryProductsHandler", pageProducts(page.Items)...) {
+			return
+		}
+		c.JSON(http.StatusOK, page)
+	}
+}
+
+func SetProductCategoriesHandler(svc categoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		var input ProductCategoriesRequest
+		if err := c.ShouldBindJSON(&input); err != nil {
+			xgin.BindError(c, err)
+			return
+		}
+
+		categories, err := svc.SetProductCategories(c.Request.Context(), idStr, userID, input.CategoryIDs)
+		if err != nil {
+			categoryError(c, "SetProductCategoriesHandler", err)
+			return
+		}
+		c.JSON(http.StatusOK, categories)
+	}
+}
+
+func GetProductCategoriesHandler(svc categoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		categories, err := svc.GetProductCategories(c.Request.Context(), idStr, userID)
+		if err != nil {
+			categoryError(c, "GetProductCategoriesHandler", err)
+			return
+		}
+		c.JSON(http.StatusOK, categories)
+	}
+}
+
+func SetCategoryAttributesHandler(svc categoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		var input CategoryAttributesRequest
+		if err := c.ShouldBindJSON(&input); err != nil {
+			xgin.BindError(c, err)
+			return
+		}
+		defs, field, msg := input.definitions()
+		if field != "" {
+			xgin.FieldError(c, field, msg)
+			return
+		}
+
+		category, err := svc.SetAttributes(c.Request.Context(), idStr, userID, defs)
+		if err != nil {
+			categoryError(c, "SetCategoryAttributesHandler", err)
+			return
+		}
+		c.JSON(http.StatusOK, category)
+	}
+}
+
+func GetCategoryAttributesHandler(svc categoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		defs, err := svc.GetAttributes(c.Request.Context(), idStr, userID)
+		if err != nil {
+			categoryError(c, "GetCategoryAttributesHandler", err)
+			return
+		}
+		c.JSON(http.StatusOK, defs)
+	}
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"e-commerce/internal/domain/models"
+	"e-commerce/internal/service"
+	"e-commerce/internal/utils/xgin"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestedCurrency returns the currency the client wants prices shown in:
+// ?currency= takes precedence over the Accept-Currency header, whose
+// entries are tried in the order listed. An empty result means prices are
+// returned as stored.
+func requestedCurrency(c *gin.Context) (string, bool) {
+	if q, ok := c.GetQuery("currency"); ok {
+		currency := strings.ToUpper(strings.TrimSpace(q))
+		if _, known := models.CurrencyExponent(currency); !known {
+			xgin.FieldError(c, "currency", "Must be a supported ISO 4217 currency code")
+			return "", false
+		}
+		return currency, true
+	}
+
+	header := c.GetHeader("Accept-Currency")
+	if header == "" {
+		return "", true
+	}
+	for _, entry := range strings.Split(header, ",") {
+		code, _, _ := strings.Cut(entry, ";")
+		currency := strings.ToUpper(strings.TrimSpace(code))
+		if _, known := models.CurrencyExponent(currency); known {
+			return currency, true
+		}
+	}
+	xgin.ErrorResponse(c, http.StatusNotAcceptable, "Not acceptable", "None of the currencies in Accept-Currency are supported")
+	return "", false
+}
+
+// convertPrices adds converted prices to products when a currency was
+// requested, writing the error response itself if that fails.
+func convertPrices(c *gin.Context, rates priceConverter, currency, handler string, products ...*models.Product) bool {
+	if currency == "" {
+		return true
+	}
+	err := rates.ConvertProducts(c.Request.Context(), currency, products...)
+	if err != nil {
+		if errors.Is(err, service.ErrNoExchangeRate) {
+			xgin.ErrorResponse(c, http.StatusUnprocessableEntity, "Validation error", "No exchange rate available to convert prices to "+currency)
+			return false
+		}
+		log.Printf("[ERROR] %s: %v", handler, err)
+		xgin.InternalError(c)
+		return false
+	}
+	return true
+}
+
+func pageProducts(items []models.Product) []*models.Product {
+	products := make([]*models.Product, len(items))
+	for i := range items {
+		products[i] = &items[i]
+	}
+	return products
+}
+
+func searchProducts(items []models.ProductSearchResult) []*models.Product {
+	products := make([]*models.Product, len(items))
+	for i := range items {
+		products[i] = &items[i].Product
+	}
+	return products
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"e-commerce/internal/auth"
+	"e-commerce/internal/repository"
+	"e-commerce/internal/utils/xgin"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// downloadWriteTimeout is how long each write of a download may take; the
+// deadline moves on as long as the client keeps reading.
+const downloadWriteTimeout = 30 * time.Second
+
+type deadlineWriter struct {
+	w  io.Writer
+	rc *http.ResponseController
+}
+
+func (d deadlineWriter) Write(p []byte) (int, error) {
+	_ = d.rc.SetWriteDeadline(time.Now().Add(downloadWriteTimeout))
+	return d.w.Write(p)
+}
+
+func downloadError(c *gin.Context, handler string, err error) {
+	switch {
+	case errors.Is(err, repository.ErrDoesNotExist):
+		xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Product not found")
+	case errors.Is(err, repository.ErrNotDigital):
+		xgin.ErrorResponse(c, http.StatusConflict, "Conflict", "Product is not digital")
+	case errors.Is(err, repository.ErrNotPurchased):
+		xgin.ErrorResponse(c, http.StatusForbidden, "Forbidden", "Buy the product to download its files")
+	case errors.Is(err, auth.ErrInvalidDownloadToken), errors.Is(err, repository.ErrDownloadNotFound):
+		xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Download link not found")
+	case errors.Is(err, auth.ErrDownloadTokenExpired), errors.Is(err, repository.ErrDownloadExpired):
+		xgin.ErrorResponse(c, http.StatusGone, "Gone", "Download link has expired")
+	case errors.Is(err, repository.ErrDownloadLimitReached):
+		xgin.ErrorResponse(c, http.StatusGone, "Gone", "Download link has been used up")
+	default:
+		log.Printf("[ERROR] %s: %v", handler, err)
+		xgin.InternalError(c)
+	}
+}
+
+// CreateDownloadLinksHandler hands a buyer, or the seller, fresh links to
+// the files of a digital product.
+func CreateDownloadLinksHandler(svc downloadService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		links, err := svc.IssueLinks(c.Request.Context(), idStr, userID)
+		if err != nil {
+			downloadError(c, "CreateDownloadLinksHandler", err)
+			return
+		}
+		c.JSON(http.StatusCreated, links)
+	}
+}
+
+// DownloadFileHandler serves a file to whoever holds a valid link; the
+// token is the only credential.
+func DownloadFileHandler(svc downloadService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		file, blob, err := svc.Open(c.Request.Context(), c.Param("token"))
+		if err != nil {
+			downloadError(c, "DownloadFileHandler", err)
+			return
+		}
+		defer blob.Close()
+
+		disposition := mime.FormatMediaType("attachment", map[string]string{"filename": file.Name})
+		if disposition == "" {
+			disposition = "attachment"
+		}
+		c.Header("Content-Type", file.ContentType)
+		c.Header("Content-Length", strconv.FormatInt(file.SizeBytes, 10))
+		c.Header("Content-Disposition", disposition)
+		c.Header("Cache-Control", "no-store")
+		c.Header("X-Content-Type-Options", "nosniff")
+		// the token is in the URL, so don't leak it to other sites
+		c.Header("Referrer-Policy", "no-referrer")
+		c.Status(http.StatusOK)
+
+		w := deadlineWriter{w: c.Writer, rc: http.NewResponseController(c.Writer)}
+		if _, err := io.Copy(w, blob); err != nil {
+			log.Printf("[ERROR] DownloadFileHandler: %v", err)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"e-commerce/internal/domain/models"
+	"e-commerce/internal/repository"
+	"e-commerce/internal/utils/xgin"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setProductETag sends the product version as a strong validator, so it
+// can be echoed back in If-Match.
+func setProductETag(c *gin.Context, p *models.Product) {
+	c.Header("ETag", `"`+strconv.Itoa(p.Version)+`"`)
+}
+
+// ifMatchVersions reads the versions listed in If-Match. nil means the
+// header is absent or "*" and the write is unconditional. Weak and
+// unrecognised tags are dropped, so they can never match.
+func ifMatchVersions(c *gin.Context) []int {
+	return parseIfMatch(c.GetHeader("If-Match"))
+}
+
+func parseIfMatch(header string) []int {
+	if header == "" {
+		return nil
+	}
+
+	versions := []int{}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return nil
+		}
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+		if v, err := strconv.Atoi(tag[1 : len(tag)-1]); err == nil {
+			versions = append(versions, v)
+		}
+	}
+	return versions
+}
+
+// preconditionFailed answers a write whose If-Match no longer holds with
+// 412 and the product as it is now, so the client can merge and retry.
+func preconditionFailed(c *gin.Context, svc productService, productID, userID, handler string) {
+	product, err := svc.GetByID(c.Request.Context(), productID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrDoesNotExist) {
+			xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Product not found")
+			return
+		}
+		log.Printf("[ERROR] %s: %v", handler, err)
+		xgin.InternalError(c)
+		return
+	}
+	setProductETag(c, product)
+	c.JSON(http.StatusPreconditionFailed, product)
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"e-commerce/internal/domain/models"
+	"e-commerce/internal/utils/xgin"
+	"e-commerce/internal/utils/xlsx"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exportFlushRows is how often the export pushes rows to the client and
+// extends the write deadline, which is otherwise the server's 10s.
+const (
+	exportFlushRows    = 500
+	exportWriteTimeout = 30 * time.Second
+)
+
+// ExportProductsQuery takes the listing filters and sort; limit and
+// cursor are accepted for symmetry but an export is never paginated.
+type ExportProductsQuery struct {
+	ListProductsQuery
+	Format string `form:"format" binding:"required,oneof=csv ndjson xlsx"`
+}
+
+var exportContentTypes = map[string]string{
+	"csv":    "text/csv; charset=utf-8",
+	"ndjson": "application/x-ndjson",
+	"xlsx":   xlsx.ContentType,
+}
+
+var exportHeader = []string{"id", "name", "price", "currency", "created_at", "updated_at", "version"}
+
+type productEncoder interface {
+	Encode(p *models.Product) error
+	Flush() error
+	Close() error
+}
+
+func newProductEncoder(format string, w io.Writer) (productEncoder, error) {
+	switch format {
+	case "csv":
+		enc := &csvProductEncoder{w: csv.NewWriter(w)}
+		return enc, enc.w.Write(exportHeader)
+	case "ndjson":
+		return &ndjsonProductEncoder{enc: json.NewEncoder(w)}, nil
+	default:
+		enc := &xlsxProductEncoder{w: xlsx.NewWriter(w, "Products")}
+		cells := make([]xlsx.Cell, len(exportHeader))
+		for i, h := range exportHeader {
+			cells[i] = xlsx.String(h)
+		}
+		return enc, enc.w.WriteRow(cells...)
+	}
+}
+
+type csvProductEncoder struct {
+	w *csv.Writer
+}
+
+func (e *csvProductEncoder) Encode(p *models.Product) error {
+	return e.w.Write([]string{
+		p.ID.String(),
+		csvSafe(p.Name),
+		p.Price.Decimal(),
+		p.Price.Currency,
+		p.CreatedAt.UTC().Format(time.RFC3339),
+		p.UpdatedAt.UTC().Format(time.RFC3339),
+		strconv.Itoa(p.Version),
+	})
+}
+
+func (e *csvProductEncoder) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvProductEncoder) Close() error {
+	return e.Flush()
+}
+
+// csvSafe keeps spreadsheet apps from running a name as a formula.
+func csvSafe(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+type ndjsonProductEncoder struct {
+	enc *json.Encoder
+}
+
+func (e *ndjsonProductEncoder) Encode(p *models.Product) error {
+	return e.enc.Encode(p)
+}
+
+func (e *ndjsonProductEncoder) Flush() error { return nil }
+
+func (e *ndjsonProductEncoder) Close() error { return nil }
+
+type xlsxProductEncoder struct {
+	w *xlsx.Writer
+}
+
+func (e *xlsxProductEncoder) Encode(p *models.Product) error {
+	return e.w.WriteRow(
+		xlsx.String(p.ID.String()),
+		xlsx.String(p.Name),
+		xlsx.Number(p.Price.Decimal()),
+		xlsx.String(p.Price.Currency),
+		xlsx.String(p.CreatedAt.UTC().Format(time.RFC3339)),
+		xlsx.String(p.UpdatedAt.UTC().Format(time.RFC3339)),
+		xlsx.Number(strconv.Itoa(p.Version)),
+	)
+}
+
+func (e *xlsxProductEncoder) Flush() error {
+	return e.w.Flush()
+}
+
+func (e *xlsxProductEncoder) Close() error {
+	return e.w.Close()
+}
+
+func ExportProductsHandler(svc productService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		var query ExportProductsQuery
+		params, ok := bindListQuery(c, &query, &query.ListProductsQuery)
+		if !ok {
+			return
+		}
+
+		rc := http.NewResponseController(c.Writer)
+		filename := fmt.Sprintf("products-%s.%s", time.Now().UTC().Format("20060102-150405"), query.Format)
+
+		// the response starts with the first row, so a failing query can
+		// still be answered with a proper error
+		var enc productEncoder
+		start := func() error {
+			c.Header("Content-Type", exportContentTypes[query.Format])
+			c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+			c.Header("Cache-Control", "no-store")
+			c.Status(http.StatusOK)
+			_ = rc.SetWriteDeadline(time.Now().Add(exportWriteTimeout))
+
+			var err error
+			enc, err = newProductEncoder(query.Format, c.Writer)
+			return err
+		}
+
+		rows := 0
+		err := svc.Export(c.Request.Context(), userID, params, func(p *models.Product) error {
+			if enc == nil {
+				if err := start(); err != nil {
+					return err
+				}
+			}
+			if err := enc.Encode(p); err != nil {
+				return err
+			}
+			rows++
+			if rows%exportFlushRows == 0 {
+				if err := enc.Flush(); err != nil {
+					return err
+				}
+				_ = rc.Flush()
+				_ = rc.SetWriteDeadline(time.Now().Add(exportWriteTimeout))
+			}
+			return nil
+		})
+		if err == nil && enc == nil {
+			err = start()
+		}
+		if err == nil {
+			err = enc.Close()
+		}
+		if err != nil {
+			if enc == nil {
+				log.Printf("[ERROR] ExportProductsHandler: %v", err)
+				xgin.InternalError(c)
+				return
+			}
+			// the status is already sent; the client gets a truncated file
+			log.Printf("[ERROR] ExportProductsHandler: aborted after %d rows: %v", rows, err)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"e-commerce/internal/domain/models"
+	"e-commerce/internal/repository"
+	"e-commerce/internal/service"
+	"e-commerce/internal/utils/xgin"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fileUploadTimeout replaces the server's 10s read deadline for file
+// uploads, which can be far larger than an image.
+const fileUploadTimeout = 15 * time.Minute
+
+func fileError(c *gin.Context, handler string, err error) {
+	switch {
+	case errors.Is(err, repository.ErrDoesNotExist):
+		xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Product not found")
+	case errors.Is(err, repository.ErrFileNotFound):
+		xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "File not found")
+	case errors.Is(err, repository.ErrNotDigital):
+		xgin.ErrorResponse(c, http.StatusConflict, "Conflict", "Only digital products can have files")
+	case errors.Is(err, repository.ErrTooManyFiles):
+		xgin.ErrorResponse(c, http.StatusUnprocessableEntity, "Validation error",
+			fmt.Sprintf("A product can have at most %d files", models.MaxProductFiles))
+	case errors.Is(err, service.ErrFileTooLarge):
+		xgin.ErrorResponse(c, http.StatusRequestEntityTooLarge, "Payload too large", "File is too large")
+	case errors.Is(err, service.ErrEmptyFile):
+		xgin.FieldError(c, "file", "must not be empty")
+	case errors.Is(err, service.ErrInvalidFileName):
+		xgin.FieldError(c, "file", "must have a file name")
+	default:
+		log.Printf("[ERROR] %s: %v", handler, err)
+		xgin.InternalError(c)
+	}
+}
+
+func GetProductFilesHandler(svc fileService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		files, err := svc.GetByProduct(c.Request.Context(), idStr, userID)
+		if err != nil {
+			fileError(c, "GetProductFilesHandler", err)
+			return
+		}
+		c.JSON(http.StatusOK, files)
+	}
+}
+
+func UploadProductFileHandler(svc fileService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		maxBytes := svc.MaxBytes()
+		_ = http.NewResponseController(c.Writer).SetReadDeadline(time.Now().Add(fileUploadTimeout))
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes+multipartOverhead)
+
+		header, err := c.FormFile("file")
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				fileError(c, "UploadProductFileHandler", service.ErrFileTooLarge)
+				return
+			}
+			xgin.FieldError(c, "file", "A multipart file field named file is required")
+			return
+		}
+
+		file, err := header.Open()
+		if err != nil {
+			fileError(c, "UploadProductFileHandler", err)
+			return
+		}
+		defer file.Close()
+
+		created, err := svc.Upload(c.Request.Context(), idStr, userID, header.Filename, file, header.Size)
+		if err != nil {
+			fileError(c, "UploadProductFileHandler", err)
+			return
+		}
+		c.JSON(http.StatusCreated, created)
+	}
+}
+
+func DeleteProductFileHandler(svc fileService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+		fileID, ok := xgin.ParseUUIDParam(c, "file_id")
+		if !ok {
+			return
+		}
+
+		if err := svc.Delete(c.Request.Context(), idStr, fileID, userID); err != nil {
+			fileError(c, "DeleteProductFileHandler", err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
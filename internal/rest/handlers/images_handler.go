@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"e-commerce/internal/domain/models"
+	"e-commerce/internal/repository"
+	"e-commerce/internal/service"
+	"e-commerce/internal/utils/xgin"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead leaves room for boundaries and part headers on top of
+// the image itself when capping the request body.
+const multipartOverhead = 1 << 20
+
+type ImageOrderRequest struct {
+	ImageIDs []string `json:"image_ids" binding:"required,unique,dive,uuid"`
+}
+
+func imageError(c *gin.Context, handler string, err error) {
+	switch {
+	case errors.Is(err, repository.ErrDoesNotExist):
+		xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Product not found")
+	case errors.Is(err, repository.ErrImageNotFound):
+		xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Image not found")
+	case errors.Is(err, repository.ErrTooManyImages):
+		xgin.ErrorResponse(c, http.StatusUnprocessableEntity, "Validation error",
+			fmt.Sprintf("A product can have at most %d images", models.MaxProductImages))
+	case errors.Is(err, repository.ErrInvalidImageOrder):
+		xgin.ErrorResponse(c, http.StatusUnprocessableEntity, "Validation error", "image_ids must list every image of the product exactly once")
+	case errors.Is(err, service.ErrUnsupportedImage):
+		xgin.ErrorResponse(c, http.StatusUnsupportedMediaType, "Unsupported media type", "Image must be a JPEG, PNG or GIF")
+	case errors.Is(err, service.ErrImageTooLarge):
+		xgin.ErrorResponse(c, http.StatusRequestEntityTooLarge, "Payload too large", "Image is too large")
+	default:
+		log.Printf("[ERROR] %s: %v", handler, err)
+		xgin.InternalError(c)
+	}
+}
+
+func GetProductImagesHandler(svc imageService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		images, err := svc.GetByProduct(c.Request.Context(), idStr, userID)
+		if err != nil {
+			imageError(c, "GetProductImagesHandler", err)
+			return
+		}
+		c.JSON(http.StatusOK, images)
+	}
+}
+
+func UploadProductImageHandler(svc imageService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		maxBytes := svc.MaxBytes()
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes+multipartOverhead)
+
+		header, err := c.FormFile("image")
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				imageError(c, "UploadProductImageHandler", service.ErrImageTooLarge)
+				return
+			}
+			xgin.FieldError(c, "image", "A multipart file field named image is required")
+			return
+		}
+		if header.Size > maxBytes {
+			imageError(c, "UploadProductImageHandler", service.ErrImageTooLarge)
+			return
+		}
+
+		file, err := header.Open()
+		if err != nil {
+			imageError(c, "UploadProductImageHandler", err)
+			return
+		}
+		defer file.Close()
+		data, err := io.ReadAll(io.LimitReader(file, maxBytes+1))
+		if err != nil {
+			imageError(c, "UploadProductImageHandler", err)
+			return
+		}
+
+		image, err := svc.Upload(c.Request.Context(), idStr, userID, data)
+		if err != nil {
+			imageError(c, "UploadProductImageHandler", err)
+			return
+		}
+		c.JSON(http.StatusCreated, image)
+	}
+}
+
+func ReorderProductImagesHandler(svc imageService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		var input ImageOrderRequest
+		if err := c.ShouldBindJSON(&input); err != nil {
+			xgin.BindError(c, err)
+			return
+		}
+
+		images, err := svc.Reorder(c.Request.Context(), idStr, userID, input.ImageIDs)
+		if err != nil {
+			imageError(c, "ReorderProductImagesHandler", err)
+			return
+		}
+		c.JSON(http.StatusOK, images)
+	}
+}
+
+func DeleteProductImageHandler(svc imageService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+		imageID, ok := xgin.ParseUUIDParam(c, "image_id")
+		if !ok {
+			return
+		}
+
+		if err := svc.Delete(c.Request.Context(), idStr, imageID, userID); err != nil {
+			imageError(c, "DeleteProductImageHandler", err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
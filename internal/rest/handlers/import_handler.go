@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"e-commerce/internal/domain/models"
+	"e-commerce/internal/repository"
+	"e-commerce/internal/service"
+	"e-commerce/internal/utils/xgin"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// importUploadTimeout replaces the server's 10s read deadline for import
+// uploads, which a large spreadsheet can easily exceed.
+const importUploadTimeout = 5 * time.Minute
+
+type ImportProductsQuery struct {
+	DryRun bool   `form:"dry_run"`
+	Format string `form:"format" binding:"omitempty,oneof=csv xlsx"`
+}
+
+func importError(c *gin.Context, handler string, err error) {
+	switch {
+	case errors.Is(err, repository.ErrImportJobNotFound):
+		xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Import job not found")
+	case errors.Is(err, service.ErrInvalidImportFile):
+		xgin.ErrorResponse(c, http.StatusUnprocessableEntity, "Invalid file", err.Error())
+	case errors.Is(err, service.ErrTooManyImportRows):
+		xgin.ErrorResponse(c, http.StatusUnprocessableEntity, "Invalid file",
+			fmt.Sprintf("A file can have at most %d rows", service.MaxImportRows))
+	default:
+		log.Printf("[ERROR] %s: %v", handler, err)
+		xgin.InternalError(c)
+	}
+}
+
+// validateImportRow applies the ProductRequest binding rules to a row, so
+// an import accepts exactly what POST /products does.
+func validateImportRow(name string, price models.Money) []models.ImportRowError {
+	err := binding.Validator.ValidateStruct(&ProductRequest{Name: name, Price: price})
+	var ve validator.ValidationErrors
+	if !errors.As(err, &ve) {
+		return nil
+	}
+	fieldErrors := xgin.FieldErrors(ve)
+	errs := make([]models.ImportRowError, len(fieldErrors))
+	for i, fe := range fieldErrors {
+		errs[i] = models.ImportRowError{Field: strings.ToLower(fe.Field), Message: fe.Message}
+	}
+	return errs
+}
+
+// importFormat takes the format from the query or, failing that, the
+// file extension.
+func importFormat(query ImportProductsQuery, filename string) string {
+	if query.Format != "" {
+		return query.Format
+	}
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return "csv"
+	case ".xlsx":
+		return "xlsx"
+	}
+	return ""
+}
+
+func ImportProductsHandler(svc importService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		var query ImportProductsQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			xgin.BindError(c, err)
+			return
+		}
+
+		maxBytes := svc.MaxBytes()
+		_ = http.NewResponseController(c.Writer).SetReadDeadline(time.Now().Add(importUploadTimeout))
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes+multipartOverhead)
+
+		header, err := c.FormFile("file")
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				xgin.ErrorResponse(c, http.StatusRequestEntityTooLarge, "Payload too large", "Import file is too large")
+				return
+			}
+			xgin.FieldError(c, "file", "A multipart file field named file is required")
+			return
+		}
+		if header.Size > maxBytes {
+			xgin.ErrorResponse(c, http.StatusRequestEntityTooLarge, "Payload too large", "Import file is too large")
+			return
+		}
+
+		format := importFormat(query, header.Filename)
+		if format == "" {
+			xgin.ErrorResponse(c, http.StatusUnsupportedMediaType, "Unsupported media type",
+				"File must be .csv or .xlsx, or name its format with ?format=")
+			return
+		}
+
+		file, err := header.Open()
+		if err != nil {
+			importError(c, "ImportProductsHandler", err)
+			return
+		}
+		defer file.Close()
+
+		job, err := svc.Start(c.Request.Context(), userID, format, file, header.Size, query.DryRun, validateImportRow)
+		if err != nil {
+			importError(c, "ImportProductsHandler", err)
+			return
+		}
+		c.Header("Location", "/products/imports/"+job.ID.String())
+		c.JSON(http.StatusAccepted, job)
+	}
+}
+
+func GetImportJobHandler(svc importService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUIDParam(c, "job_id")
+		if !ok {
+			return
+		}
+
+		job, err := svc.Get(c.Request.Context(), idStr, userID)
+		if err != nil {
+			importError(c, "GetImportJobHandler", err)
+			return
+		}
+		c.JSON(http.StatusOK, job)
+	}
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"e-commerce/internal/repository"
+	"e-commerce/internal/service"
+	"e-commerce/internal/utils/xgin"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StockMovementRequest struct {
+	VariantID *string `json:"variant_id" binding:"omitempty,uuid"`
+	Quantity  int     `json:"quantity" binding:"required"`
+	Reason    string  `json:"reason" binding:"required,oneof=receipt sale adjustment return"`
+	Note      string  `json:"note" binding:"max=500"`
+}
+
+type ListMovementsQuery struct {
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Cursor string `form:"cursor"`
+}
+
+type ReservationRequest struct {
+	ProductID string  `json:"product_id" binding:"required,uuid"`
+	VariantID *string `json:"variant_id" binding:"omitempty,uuid"`
+	Quantity  int     `json:"quantity" binding:"required,min=1,max=1000"`
+}
+
+func inventoryError(c *gin.Context, handler string, err error) {
+	switch {
+	case errors.Is(err, repository.ErrDoesNotExist):
+		xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Product not found")
+	case errors.Is(err, repository.ErrVariantNotFound):
+		xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Variant not found")
+	case errors.Is(err, repository.ErrReservationNotFound):
+		xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Reservation not found")
+	case errors.Is(err, repository.ErrVariantRequired):
+		xgin.ErrorResponse(c, http.StatusUnprocessableEntity, "Validation error", "Product has variants; variant_id is required")
+	case errors.Is(err, repository.ErrBundleStock):
+		xgin.ErrorResponse(c, http.StatusUnprocessableEntity, "Validation error", "A bundle's stock is its components' stock; use the components")
+	case errors.Is(err, service.ErrInvalidMovement):
+		xgin.ErrorResponse(c, http.StatusUnprocessableEntity, "Validation error", "Quantity sign does not match movement reason")
+	case errors.Is(err, repository.ErrInsufficientStock):
+		xgin.ErrorResponse(c, http.StatusConflict, "Conflict", "Insufficient stock")
+	case errors.Is(err, repository.ErrReservationNotActive):
+		xgin.ErrorResponse(c, http.StatusConflict, "Conflict", "Reservation is no longer active")
+	case errors.Is(err, repository.ErrInvalidCursor):
+		xgin.ErrorResponse(c, http.StatusBadRequest, "Bad request", "Invalid cursor")
+	default:
+		log.Printf("[ERROR] %s: %v", handler, err)
+		xgin.InternalError(c)
+	}
+}
+
+func GetStockHandler(svc inventoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		levels, err := svc.GetStock(c.Request.Context(), idStr, userID)
+		if err != nil {
+			inventoryError(c, "GetStockHandler", err)
+			return
+		}
+		c.JSON(http.StatusOK, levels)
+	}
+}
+
+func RecordStockMovementHandler(svc inventoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		var input StockMovementRequest
+		if err := c.ShouldBindJSON(&input); err != nil {
+			xgin.BindError(c, err)
+			return
+		}
+
+		level, err := svc.RecordMovement(c.Request.Context(), idStr, input.VariantID, userID, input.Quantity, input.Reason, input.Note)
+		if err != nil {
+			inventoryError(c, "RecordStockMovementHandler", err)
+			return
+		}
+		c.JSON(http.StatusCreated, level)
+	}
+}
+
+func ListStockMovementsHandler(svc inventoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		var query ListMovementsQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			xgin.BindError(c, err)
+			return
+		}
+
+		page, err := svc.ListMovements(c.Request.Context(), idStr, userID, query.Limit, query.Cursor)
+		if err != nil {
+			inventoryError(c, "ListStockMovementsHandler", err)
+			return
+		}
+		c.JSON(http.StatusOK, page)
+	}
+}
+
+func CreateReservationHandler(svc inventoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		var input ReservationRequest
+		if err := c.ShouldBindJSON(&input); err != nil {
+			xgin.BindError(c, err)
+			return
+		}
+
+		res, err := svc.Reserve(c.Request.Context(), input.ProductID, input.VariantID, userID, input.Quantity)
+		if err != nil {
+			inventoryError(c, "CreateReservationHandler", err)
+			return
+		}
+		c.JSON(http.StatusCreated, res)
+	}
+}
+
+func CommitReservationHandler(svc inventoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		res, err := svc.CommitReservation(c.Request.Context(), idStr, userID)
+		if err != nil {
+			inventoryError(c, "CommitReservationHandler", err)
+			return
+		}
+		c.JSON(http.StatusOK, res)
+	}
+}
+
+func ReleaseReservationHandler(svc inventoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		res, err := svc.ReleaseReservation(c.Request.Context(), idStr, userID)
+		if err != nil {
+			inventoryError(c, "ReleaseReservationHandler", err)
+			return
+		}
+		c.JSON(http.StatusOK, res)
+	}
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"e-commerce/internal/domain/models"
+	"e-commerce/internal/repository"
+	"e-commerce/internal/utils/xgin"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SchedulePriceRequest plans a price from effective_from; without
+// effective_to the price stays until changed.
+type SchedulePriceRequest struct {
+	Price         models.Money `json:"price" binding:"money"`
+	EffectiveFrom time.Time    `json:"effective_from" binding:"required"`
+	EffectiveTo   *time.Time   `json:"effective_to"`
+}
+
+// check reports the first field that is in the past or out of order.
+func (r SchedulePriceRequest) check(now time.Time) (string, string) {
+	if !r.EffectiveFrom.After(now) {
+		return "effective_from", "must be in the future"
+	}
+	if r.EffectiveTo != nil && !r.EffectiveTo.After(r.EffectiveFrom) {
+		return "effective_to", "must be after effective_from"
+	}
+	return "", ""
+}
+
+func priceError(c *gin.Context, handler string, err error) {
+	switch {
+	case errors.Is(err, repository.ErrDoesNotExist):
+		xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Product not found")
+	case errors.Is(err, repository.ErrScheduledPriceNotFound):
+		xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Scheduled price not found")
+	case errors.Is(err, repository.ErrPriceScheduleOverlap):
+		xgin.ErrorResponse(c, http.StatusConflict, "Conflict", "The period overlaps another scheduled price of the product")
+	case errors.Is(err, repository.ErrScheduledPriceStarted):
+		xgin.ErrorResponse(c, http.StatusConflict, "Conflict", "The scheduled price has already started")
+	default:
+		log.Printf("[ERROR] %s: %v", handler, err)
+		xgin.InternalError(c)
+	}
+}
+
+func GetProductPricesHandler(svc priceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		timeline, err := svc.Timeline(c.Request.Context(), idStr, userID)
+		if err != nil {
+			priceError(c, "GetProductPricesHandler", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, timeline)
+	}
+}
+
+func SchedulePriceHandler(svc priceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		var input SchedulePriceRequest
+		if err := c.ShouldBindJSON(&input); err != nil {
+			xgin.BindError(c, err)
+			return
+		}
+		if field, msg := input.check(time.Now()); field != "" {
+			xgin.FieldError(c, field, msg)
+			return
+		}
+
+		scheduled, err := svc.Schedule(c.Request.Context(), idStr, userID, input.Price, input.EffectiveFrom, input.EffectiveTo)
+		if err != nil {
+			priceError(c, "SchedulePriceHandler", err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, scheduled)
+	}
+}
+
+func CancelScheduledPriceHandler(svc priceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+		priceID, ok := xgin.ParseUUIDParam(c, "price_id")
+		if !ok {
+			return
+		}
+
+		if err := svc.Cancel(c.Request.Context(), idStr, priceID, userID); err != nil {
+			priceError(c, "CancelScheduledPriceHandler", err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"bytes"
+	"e-commerce/internal/domain/models"
+	"e-commerce/internal/repository"
+	"e-commerce/internal/utils/jsonpatch"
+	"e-commerce/internal/utils/xgin"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+const maxPatchBytes = 1 << 20
+
+// patchProductDocument applies a merge patch or JSON Patch body to the
+// product's editable document, validates the result as a ProductRequest
+// and saves whatever changed. The write is conditional on the version the
+// patch was applied to, so a concurrent change can't be overwritten.
+func patchProductDocument(c *gin.Context, svc productService, productID, userID string) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxPatchBytes))
+	if err != nil {
+		if errors.As(err, new(*http.MaxBytesError)) {
+			xgin.ErrorResponse(c, http.StatusRequestEntityTooLarge, "Payload too large", "Patch document is too large")
+			return
+		}
+		xgin.ErrorResponse(c, http.StatusBadRequest, "Bad request", "Could not read the patch document")
+		return
+	}
+
+	current, err := svc.GetByID(c.Request.Context(), productID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrDoesNotExist) {
+			xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Product not found")
+			return
+		}
+		log.Printf("[ERROR] PatchProductHandler: %v", err)
+		xgin.InternalError(c)
+		return
+	}
+	if ifMatch := ifMatchVersions(c); ifMatch != nil && !slices.Contains(ifMatch, current.Version) {
+		setProductETag(c, current)
+		c.JSON(http.StatusPreconditionFailed, current)
+		return
+	}
+
+	doc, err := json.Marshal(ProductRequest{Name: current.Name, Price: current.Price, Attributes: current.Attributes, Tags: current.Tags})
+	if err != nil {
+		log.Printf("[ERROR] PatchProductHandler: %v", err)
+		xgin.InternalError(c)
+		return
+	}
+
+	var patched []byte
+	if c.ContentType() == jsonpatch.MergePatchType {
+		patched, err = jsonpatch.MergePatch(doc, body)
+	} else {
+		patched, err = jsonpatch.Apply(doc, body)
+	}
+	if err != nil {
+		patchError(c, err)
+		return
+	}
+
+	input, ok := bindPatchedProduct(c, patched)
+	if !ok {
+		return
+	}
+
+	updates := make(map[string]any)
+	if input.Name != current.Name {
+		updates["name"] = input.Name
+	}
+	if input.Price != current.Price {
+		updates["price"] = input.Price
+	}
+	if !sameAttributes(input.Attributes, current.Attributes) {
+		if input.Attributes == nil {
+			input.Attributes = map[string]any{} // removed by the patch
+		}
+		updates["attributes"] = input.Attributes
+	}
+	if !slices.Equal(models.NormalizeTags(input.Tags), current.Tags) {
+		if input.Tags == nil {
+			input.Tags = []string{} // removed by the patch
+		}
+		updates["tags"] = input.Tags
+	}
+	if len(updates) == 0 {
+		setProductETag(c, current)
+		c.JSON(http.StatusOK, current)
+		return
+	}
+
+	savePatch(c, svc, productID, userID, updates, []int{current.Version})
+}
+
+// bindPatchedProduct decodes the patched document against the product
+// schema. Fields outside ProductRequest are unknown or read-only and are
+// rejected rather than ignored.
+func bindPatchedProduct(c *gin.Context, patched []byte) (*ProductRequest, bool) {
+	var input ProductRequest
+	dec := json.NewDecoder(bytes.NewReader(patched))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&input); err != nil {
+		if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			if name, err := strconv.Unquote(field); err == nil {
+				field = name
+			}
+			xgin.FieldError(c, field, "unknown or read-only field")
+			return nil, false
+		}
+		xgin.BindError(c, err)
+		return nil, false
+	}
+	if err := binding.Validator.ValidateStruct(&input); err != nil {
+		xgin.BindError(c, err)
+		return nil, false
+	}
+	return &input, true
+}
+
+func patchError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, jsonpatch.ErrTestFailed):
+		xgin.ErrorResponse(c, http.StatusConflict, "Conflict", err.Error())
+	case errors.Is(err, jsonpatch.ErrInvalidPatch):
+		xgin.ErrorResponse(c, http.StatusUnprocessableEntity, "Invalid patch", err.Error())
+	default:
+		xgin.BindError(c, err)
+	}
+}
+
+func savePatch(c *gin.Context, svc productService, productID, userID string, updates map[string]any, ifMatch []int) {
+	product, err := svc.Patch(c.Request.Context(), productID, userID, updates, ifMatch)
+	if err != nil {
+		if attributesError(c, err) {
+			return
+		}
+		if errors.Is(err, repository.ErrDoesNotExist) {
+			xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Product not found")
+			return
+		}
+		if errors.Is(err, repository.ErrVersionMismatch) {
+			preconditionFailed(c, svc, productID, userID, "PatchProductHandler")
+			return
+		}
+		log.Printf("[ERROR] PatchProductHandler: %v", err)
+		xgin.InternalError(c)
+		return
+	}
+	setProductETag(c, product)
+	c.JSON(http.StatusOK, product)
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"e-commerce/internal/domain/models"
+	"e-commerce/internal/repository"
+	"e-commerce/internal/utils/xgin"
+	"errors"
+	"log"
+	"net/http"
+	"path"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProductSlugRequest struct {
+	Slug string `json:"slug" binding:"required,slug"`
+}
+
+// redirectToSlug answers a lookup by an old slug with a permanent redirect
+// to the same URL under the current one.
+func redirectToSlug(c *gin.Context, slug string) {
+	location := path.Join(path.Dir(c.Request.URL.Path), slug)
+	if c.Request.URL.RawQuery != "" {
+		location += "?" + c.Request.URL.RawQuery
+	}
+	c.Redirect(http.StatusMovedPermanently, location)
+}
+
+func GetProductBySlugHandler(svc productService, rates priceConverter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		slug := c.Param("slug")
+		if !models.ValidSlug(slug) {
+			xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Product not found")
+			return
+		}
+
+		currency, ok := requestedCurrency(c)
+		if !ok {
+			return
+		}
+
+		product, err := svc.GetBySlug(c.Request.Context(), slug, userID)
+		if err != nil {
+			if errors.Is(err, repository.ErrDoesNotExist) {
+				xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Product not found")
+				return
+			}
+			log.Printf("[ERROR] GetProductBySlugHandler: %v", err)
+			xgin.InternalError(c)
+			return
+		}
+		if product.Slug != slug {
+			redirectToSlug(c, product.Slug)
+			return
+		}
+
+		if !convertPrices(c, rates, currency, "GetProductBySlugHandler", product) {
+			return
+		}
+		setProductETag(c, product)
+
+		c.JSON(http.StatusOK, product)
+	}
+}
+
+func GetCatalogProductBySlugHandler(svc catalogService, rates priceConverter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		slug := c.Param("slug")
+		if !models.ValidSlug(slug) {
+			xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Product not found")
+			return
+		}
+
+		currency, ok := requestedCurrency(c)
+		if !ok {
+			return
+		}
+
+		product, err := svc.GetBySlug(c.Request.Context(), slug)
+		if err != nil {
+			if errors.Is(err, repository.ErrDoesNotExist) {
+				xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Product not found")
+				return
+			}
+			log.Printf("[ERROR] GetCatalogProductBySlugHandler: %v", err)
+			xgin.InternalError(c)
+			return
+		}
+		if product.Slug != slug {
+			redirectToSlug(c, product.Slug)
+			return
+		}
+
+		if !convertPrices(c, rates, currency, "GetCatalogProductBySlugHandler", product) {
+			return
+		}
+		setProductETag(c, product)
+
+		c.JSON(http.StatusOK, newCatalogProductResponse(product))
+	}
+}
+
+func SetProductSlugHandler(svc productService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		var input ProductSlugRequest
+		if err := c.ShouldBindJSON(&input); err != nil {
+			xgin.BindError(c, err)
+			return
+		}
+
+		product, err := svc.SetSlug(c.Request.Context(), idStr, userID, input.Slug, ifMatchVersions(c))
+		if err != nil {
+			if errors.Is(err, repository.ErrDoesNotExist) {
+				xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Product not found")
+				return
+			}
+			if errors.Is(err, repository.ErrVersionMismatch) {
+				preconditionFailed(c, svc, idStr, userID, "SetProductSlugHandler")
+				return
+			}
+			if errors.Is(err, repository.ErrSlugTaken) {
+				xgin.ErrorResponse(c, http.StatusConflict, "Conflict", "The slug is or was used by another product")
+				return
+			}
+			log.Printf("[ERROR] SetProductSlugHandler: %v", err)
+			xgin.InternalError(c)
+			return
+		}
+		setProductETag(c, product)
+
+		c.JSON(http.StatusOK, product)
+	}
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"e-commerce/internal/repository"
+	"e-commerce/internal/utils/xgin"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProductStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=draft published archived"`
+}
+
+// ProductScheduleRequest replaces the whole schedule; a time left out or
+// null is cleared.
+type ProductScheduleRequest struct {
+	PublishAt   *time.Time `json:"publish_at"`
+	UnpublishAt *time.Time `json:"unpublish_at"`
+}
+
+// check reports the first field that is in the past or out of order.
+func (r ProductScheduleRequest) check(now time.Time) (string, string) {
+	if r.PublishAt != nil && !r.PublishAt.After(now) {
+		return "publish_at", "must be in the future"
+	}
+	if r.UnpublishAt != nil && !r.UnpublishAt.After(now) {
+		return "unpublish_at", "must be in the future"
+	}
+	if r.PublishAt != nil && r.UnpublishAt != nil && !r.UnpublishAt.After(*r.PublishAt) {
+		return "unpublish_at", "must be after publish_at"
+	}
+	return "", ""
+}
+
+// statusError answers the errors shared by status and schedule changes.
+func statusError(c *gin.Context, svc productService, err error, productID, userID, conflict, handler string) {
+	switch {
+	case errors.Is(err, repository.ErrDoesNotExist):
+		xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Product not found")
+	case errors.Is(err, repository.ErrVersionMismatch):
+		preconditionFailed(c, svc, productID, userID, handler)
+	case errors.Is(err, repository.ErrStatusConflict):
+		xgin.ErrorResponse(c, http.StatusConflict, "Conflict", conflict)
+	default:
+		log.Printf("[ERROR] %s: %v", handler, err)
+		xgin.InternalError(c)
+	}
+}
+
+func SetProductStatusHandler(svc productService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		var input ProductStatusRequest
+		if err := c.ShouldBindJSON(&input); err != nil {
+			xgin.BindError(c, err)
+			return
+		}
+
+		product, err := svc.SetStatus(c.Request.Context(), idStr, userID, input.Status, ifMatchVersions(c))
+		if err != nil {
+			statusError(c, svc, err, idStr, userID,
+				"The product can't move to "+input.Status+" from its current status", "SetProductStatusHandler")
+			return
+		}
+		setProductETag(c, product)
+
+		c.JSON(http.StatusOK, product)
+	}
+}
+
+func SetProductScheduleHandler(svc productService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		var input ProductScheduleRequest
+		if err := c.ShouldBindJSON(&input); err != nil {
+			xgin.BindError(c, err)
+			return
+		}
+		if field, msg := input.check(time.Now()); field != "" {
+			xgin.FieldError(c, field, msg)
+			return
+		}
+
+		product, err := svc.SetSchedule(c.Request.Context(), idStr, userID, input.PublishAt, input.UnpublishAt, ifMatchVersions(c))
+		if err != nil {
+			statusError(c, svc, err, idStr, userID,
+				"Only drafts can be scheduled to publish, and archived products can't be scheduled", "SetProductScheduleHandler")
+			return
+		}
+		setProductETag(c, product)
+
+		c.JSON(http.StatusOK, product)
+	}
+}
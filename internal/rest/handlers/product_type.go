@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"e-commerce/internal/repository"
+	"e-commerce/internal/utils/xgin"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProductTypeRequest struct {
+	Type string `json:"type" binding:"required,oneof=physical digital"`
+}
+
+func SetProductTypeHandler(svc productService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		var input ProductTypeRequest
+		if err := c.ShouldBindJSON(&input); err != nil {
+			xgin.BindError(c, err)
+			return
+		}
+
+		product, err := svc.SetType(c.Request.Context(), idStr, userID, input.Type, ifMatchVersions(c))
+		if err != nil {
+			if errors.Is(err, repository.ErrDoesNotExist) {
+				xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Product not found")
+				return
+			}
+			if errors.Is(err, repository.ErrVersionMismatch) {
+				preconditionFailed(c, svc, idStr, userID, "SetProductTypeHandler")
+				return
+			}
+			if errors.Is(err, repository.ErrProductHasFiles) {
+				xgin.ErrorResponse(c, http.StatusConflict, "Conflict", "Delete the product's files before making it physical")
+				return
+			}
+			log.Printf("[ERROR] SetProductTypeHandler: %v", err)
+			xgin.InternalError(c)
+			return
+		}
+		setProductETag(c, product)
+
+		c.JSON(http.StatusOK, product)
+	}
+}
@@ -1,25 +1,117 @@
 package handlers
 
 import (
+	"e-commerce/internal/domain/models"
 	"e-commerce/internal/repository"
+	"e-commerce/internal/utils/jsonpatch"
 	"e-commerce/internal/utils/xgin"
 	"errors"
 	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
+// ProductRequest creates or replaces a product. Attributes and tags left
+// out of an update stay as they are.
 type ProductRequest struct {
-	Name  string  `json:"name" binding:"required,min=2"`
-	Price float64 `json:"price" binding:"required,gt=0"`
+	Name       string         `json:"name" binding:"required,min=2"`
+	Price      models.Money   `json:"price" binding:"money"`
+	Attributes map[string]any `json:"attributes" binding:"omitempty,attributes"`
+	Tags       []string       `json:"tags" binding:"omitempty,max=20,dive,min=1,max=50"`
 }
 
+// PatchProductRequest replaces the whole attribute set or tag list when
+// it is given.
 type PatchProductRequest struct {
-	Name  *string  `json:"name"  binding:"required_without_all=Price,omitempty,min=2"`
-	Price *float64 `json:"price" binding:"required_without_all=Name,omitempty,gt=0"`
+	Name       *string        `json:"name"  binding:"required_without_all=Price Attributes Tags,omitempty,min=2"`
+	Price      *models.Money  `json:"price" binding:"required_without_all=Name Attributes Tags,omitempty,money"`
+	Attributes map[string]any `json:"attributes" binding:"required_without_all=Name Price Tags,omitempty,attributes"`
+	Tags       []string       `json:"tags" binding:"required_without_all=Name Price Attributes,omitempty,max=20,dive,min=1,max=50"`
 }
 
+type ListProductsQuery struct {
+	Limit         int      `form:"limit" binding:"omitempty,min=1,max=100"`
+	Cursor        string   `form:"cursor"`
+	Sort          string   `form:"sort" binding:"omitempty,oneof=name price created_at updated_at"`
+	Order         string   `form:"order" binding:"omitempty,oneof=asc desc"`
+	MinPrice      string   `form:"min_price" binding:"omitempty,numeric"`
+	MaxPrice      string   `form:"max_price" binding:"omitempty,numeric"`
+	PriceCurrency string   `form:"price_currency" binding:"required_with=MinPrice MaxPrice,omitempty,currency"`
+	NamePrefix    string   `form:"name_prefix" binding:"omitempty,max=100"`
+	Tags          []string `form:"tag" binding:"omitempty,max=10,dive,min=1,max=50"`
+	TagMode       string   `form:"tag_mode" binding:"omitempty,oneof=any all"`
+	Facets        bool     `form:"facets"`
+	Status        string   `form:"status" binding:"omitempty,oneof=draft published archived"`
+}
+
+type TrashQuery struct {
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Cursor string `form:"cursor"`
+}
+
+type SearchProductsQuery struct {
+	Q      string `form:"q" binding:"required,max=200"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int    `form:"offset" binding:"omitempty,min=0,max=10000"`
+}
+
+func (q SearchProductsQuery) params() models.ProductSearchParams {
+	return models.ProductSearchParams{Query: q.Q, Limit: q.Limit, Offset: q.Offset}
+}
+
+// params converts the query into list parameters. It reports the
+// offending query field when a price bound doesn't fit the currency.
+func (q ListProductsQuery) params() (models.ProductListParams, string, error) {
+	params := models.ProductListParams{
+		Limit:        q.Limit,
+		Cursor:       q.Cursor,
+		SortBy:       q.Sort,
+		Order:        q.Order,
+		NamePrefix:   q.NamePrefix,
+		Tags:         models.NormalizeTags(q.Tags),
+		MatchAllTags: q.TagMode == "all",
+		Facets:       q.Facets,
+		Status:       q.Status,
+	}
+	if q.MinPrice != "" {
+		minPrice, err := models.ParseMoney(q.MinPrice, q.PriceCurrency)
+		if err != nil {
+			return params, "min_price", err
+		}
+		params.MinPrice = &minPrice
+	}
+	if q.MaxPrice != "" {
+		maxPrice, err := models.ParseMoney(q.MaxPrice, q.PriceCurrency)
+		if err != nil {
+			return params, "max_price", err
+		}
+		if params.MinPrice != nil && maxPrice.Amount < params.MinPrice.Amount {
+			return params, "max_price", errors.New("must be greater than or equal to min_price")
+		}
+		params.MaxPrice = &maxPrice
+	}
+	return params, "", nil
+}
+
+// bindListQuery binds query, which embeds or is list, and writes the error
+// response itself when the parameters are invalid.
+func bindListQuery(c *gin.Context, query any, list *ListProductsQuery) (models.ProductListParams, bool) {
+	if err := c.ShouldBindQuery(query); err != nil {
+		xgin.BindError(c, err)
+		return models.ProductListParams{}, false
+	}
+	params, field, err := list.params()
+	if err == nil {
+		params.Attributes, field, err = attributeFilters(c.QueryMap("attr"))
+	}
+	if err != nil {
+		xgin.FieldError(c, field, err.Error())
+		return models.ProductListParams{}, false
+	}
+	return params, true
+}
 
 func CreateProductHandler(svc productService) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -37,7 +129,7 @@ func CreateProductHandler(svc productService) gin.HandlerFunc {
 			return
 		}
 
-		product, err := svc.Create(c.Request.Context(), input.Name, input.Price, userID)
+		product, err := svc.Create(c.Request.Context(), input.Name, input.Price, input.Attributes, input.Tags, userID)
 		if err != nil {
 			if errors.Is(err, repository.ErrAlreadyExists) {
 				xgin.ErrorResponse(c, http.StatusConflict, "Conflict", "Product already exists")
@@ -47,11 +139,12 @@ func CreateProductHandler(svc productService) gin.HandlerFunc {
 			xgin.InternalError(c)
 			return
 		}
+		setProductETag(c, product)
 		c.JSON(http.StatusCreated, product)
 	}
 }
 
-func GetProductByIdHandler(svc productService) gin.HandlerFunc {
+func GetProductByIdHandler(svc productService, rates priceConverter) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userID, exists := xgin.GetUserID(c)
 
@@ -65,6 +158,11 @@ func GetProductByIdHandler(svc productService) gin.HandlerFunc {
 			return
 		}
 
+		currency, ok := requestedCurrency(c)
+		if !ok {
+			return
+		}
+
 		product, err := svc.GetByID(c.Request.Context(), idStr, userID)
 		if err != nil {
 			if errors.Is(err, repository.ErrDoesNotExist) {
@@ -76,6 +174,11 @@ func GetProductByIdHandler(svc productService) gin.HandlerFunc {
 			return
 		}
 
+		if !convertPrices(c, rates, currency, "GetProductByIdHandler", product) {
+			return
+		}
+		setProductETag(c, product)
+
 		c.JSON(http.StatusOK, product)
 	}
 }
@@ -94,12 +197,20 @@ func DeleteProductByIdHandler(svc productService) gin.HandlerFunc {
 			return
 		}
 
-		err := svc.Delete(c.Request.Context(), idStr, userID)
+		err := svc.Delete(c.Request.Context(), idStr, userID, ifMatchVersions(c))
 		if err != nil {
 			if errors.Is(err, repository.ErrDoesNotExist) {
 				xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Product not found")
 				return
 			}
+			if errors.Is(err, repository.ErrVersionMismatch) {
+				preconditionFailed(c, svc, idStr, userID, "DeleteProductByIdHandler")
+				return
+			}
+			if errors.Is(err, repository.ErrProductInBundle) {
+				xgin.ErrorResponse(c, http.StatusConflict, "Conflict", "The product is part of a bundle; remove it from the bundle first")
+				return
+			}
 			log.Printf("[ERROR] DeleteProductByIdHandler: %v", err)
 			xgin.InternalError(c)
 			return
@@ -123,6 +234,17 @@ func PatchProductHandler(svc productService) gin.HandlerFunc {
 			return
 		}
 
+		switch c.ContentType() {
+		case jsonpatch.MergePatchType, jsonpatch.JSONPatchType:
+			patchProductDocument(c, svc, idStr, userID)
+			return
+		case binding.MIMEJSON, "":
+		default:
+			xgin.ErrorResponse(c, http.StatusUnsupportedMediaType, "Unsupported media type",
+				"Use application/merge-patch+json, application/json-patch+json or application/json")
+			return
+		}
+
 		var input PatchProductRequest
 		if err := c.ShouldBindJSON(&input); err != nil {
 			xgin.BindError(c, err)
@@ -136,18 +258,14 @@ func PatchProductHandler(svc productService) gin.HandlerFunc {
 		if input.Price != nil {
 			updates["price"] = *input.Price
 		}
-
-		product, err := svc.Patch(c.Request.Context(), idStr, userID, updates)
-		if err != nil {
-			if errors.Is(err, repository.ErrDoesNotExist) {
-				xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Product not found")
-				return
-			}
-			log.Printf("[ERROR] PatchProductHandler: %v", err)
-			xgin.InternalError(c)
-			return
+		if input.Attributes != nil {
+			updates["attributes"] = input.Attributes
 		}
-		c.JSON(http.StatusOK, product)
+		if input.Tags != nil {
+			updates["tags"] = input.Tags
+		}
+
+		savePatch(c, svc, idStr, userID, updates, ifMatchVersions(c))
 	}
 }
 
@@ -171,22 +289,30 @@ func UpdateProductHandler(svc productService) gin.HandlerFunc {
 			return
 		}
 
-		product, err := svc.Update(c.Request.Context(), idStr, userID, input.Name, input.Price)
+		product, err := svc.Update(c.Request.Context(), idStr, userID, input.Name, input.Price, input.Attributes, input.Tags, ifMatchVersions(c))
 		if err != nil {
+			if attributesError(c, err) {
+				return
+			}
 			if errors.Is(err, repository.ErrDoesNotExist) {
 				xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Product not found")
 				return
 			}
+			if errors.Is(err, repository.ErrVersionMismatch) {
+				preconditionFailed(c, svc, idStr, userID, "UpdateProductHandler")
+				return
+			}
 			log.Printf("[ERROR] UpdateProductHandler: %v", err)
 			xgin.InternalError(c)
 			return
 		}
+		setProductETag(c, product)
 
 		c.JSON(http.StatusOK, product)
 	}
 }
 
-func GetAllProductsHandler(svc productService) gin.HandlerFunc {
+func GetAllProductsHandler(svc productService, rates priceConverter) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userID, exists := xgin.GetUserID(c)
 
@@ -195,13 +321,139 @@ func GetAllProductsHandler(svc productService) gin.HandlerFunc {
 			return
 		}
 
-		products, err := svc.GetAll(c.Request.Context(), userID)
+		var query ListProductsQuery
+		params, ok := bindListQuery(c, &query, &query)
+		if !ok {
+			return
+		}
+
+		currency, ok := requestedCurrency(c)
+		if !ok {
+			return
+		}
+
+		page, err := svc.GetAll(c.Request.Context(), userID, params)
 		if err != nil {
+			if errors.Is(err, repository.ErrInvalidCursor) {
+				xgin.ErrorResponse(c, http.StatusBadRequest, "Bad request", "Invalid cursor")
+				return
+			}
 			log.Printf("[ERROR] GetAllProductsHandler: %v", err)
 			xgin.InternalError(c)
 			return
 		}
 
-		c.JSON(http.StatusOK, products)
+		if !convertPrices(c, rates, currency, "GetAllProductsHandler", pageProducts(page.Items)...) {
+			return
+		}
+
+		c.JSON(http.StatusOK, page)
+	}
+}
+
+func SearchProductsHandler(svc productService, rates priceConverter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		var query SearchProductsQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			xgin.BindError(c, err)
+			return
+		}
+
+		currency, ok := requestedCurrency(c)
+		if !ok {
+			return
+		}
+
+		page, err := svc.Search(c.Request.Context(), userID, query.params())
+		if err != nil {
+			if errors.Is(err, repository.ErrEmptySearchQuery) {
+				xgin.ErrorResponse(c, http.StatusBadRequest, "Bad request", "Search query has no searchable terms")
+				return
+			}
+			log.Printf("[ERROR] SearchProductsHandler: %v", err)
+			xgin.InternalError(c)
+			return
+		}
+
+		if !convertPrices(c, rates, currency, "SearchProductsHandler", searchProducts(page.Items)...) {
+			return
+		}
+
+		c.JSON(http.StatusOK, page)
+	}
+}
+
+func ListTrashHandler(svc productService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		var query TrashQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			xgin.BindError(c, err)
+			return
+		}
+
+		page, err := svc.Trash(c.Request.Context(), userID, query.Limit, query.Cursor)
+		if err != nil {
+			if errors.Is(err, repository.ErrInvalidCursor) {
+				xgin.ErrorResponse(c, http.StatusBadRequest, "Bad request", "Invalid cursor")
+				return
+			}
+			log.Printf("[ERROR] ListTrashHandler: %v", err)
+			xgin.InternalError(c)
+			return
+		}
+
+		c.JSON(http.StatusOK, page)
+	}
+}
+
+func RestoreProductHandler(svc productService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		product, err := svc.Restore(c.Request.Context(), idStr, userID)
+		if err != nil {
+			if errors.Is(err, repository.ErrDoesNotExist) {
+				xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Product not found in trash")
+				return
+			}
+			if errors.Is(err, repository.ErrAlreadyExists) {
+				xgin.ErrorResponse(c, http.StatusConflict, "Conflict", "A product with the same name and price already exists")
+				return
+			}
+			if errors.Is(err, repository.ErrBundleComponentTrashed) {
+				xgin.ErrorResponse(c, http.StatusConflict, "Conflict", "Restore the bundle's components first")
+				return
+			}
+			log.Printf("[ERROR] RestoreProductHandler: %v", err)
+			xgin.InternalError(c)
+			return
+		}
+		setProductETag(c, product)
+
+		c.JSON(http.StatusOK, product)
 	}
 }
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"e-commerce/internal/domain/models"
+	"e-commerce/internal/repository"
+	"e-commerce/internal/utils/xgin"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RelatedProductsQuery struct {
+	Limit int `form:"limit" binding:"omitempty,min=1,max=20"`
+}
+
+type RelatedProductResponse struct {
+	CatalogProductResponse
+	Reason string `json:"reason"`
+}
+
+func ListRelatedProductsHandler(svc recommendationService, rates priceConverter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		var query RelatedProductsQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			xgin.BindError(c, err)
+			return
+		}
+
+		currency, ok := requestedCurrency(c)
+		if !ok {
+			return
+		}
+
+		related, err := svc.Related(c.Request.Context(), idStr, query.Limit)
+		if err != nil {
+			if errors.Is(err, repository.ErrDoesNotExist) {
+				xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Product not found")
+				return
+			}
+			log.Printf("[ERROR] ListRelatedProductsHandler: %v", err)
+			xgin.InternalError(c)
+			return
+		}
+
+		products := make([]*models.Product, len(related))
+		for i := range related {
+			products[i] = &related[i].Product
+		}
+		if !convertPrices(c, rates, currency, "ListRelatedProductsHandler", products...) {
+			return
+		}
+
+		resp := make([]RelatedProductResponse, len(related))
+		for i := range related {
+			resp[i] = RelatedProductResponse{
+				CatalogProductResponse: newCatalogProductResponse(&related[i].Product),
+				Reason:                 related[i].Reason,
+			}
+		}
+		c.JSON(http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,308 @@
+package handlers
+
+import (
+	"e-commerce/internal/domain/models"
+	"e-commerce/internal/repository"
+	"e-commerce/internal/utils/xgin"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReviewRequest struct {
+	Rating int    `json:"rating" binding:"required,min=1,max=5"`
+	Title  string `json:"title" binding:"required,max=200"`
+	Body   string `json:"body" binding:"max=5000"`
+}
+
+type ReviewStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=pending approved rejected"`
+}
+
+type ReviewReplyRequest struct {
+	Body string `json:"body" binding:"required,max=5000"`
+}
+
+type ReviewsQuery struct {
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Cursor string `form:"cursor"`
+	Sort   string `form:"sort" binding:"omitempty,oneof=recent helpful"`
+}
+
+type ProductReviewsQuery struct {
+	ReviewsQuery
+	Status string `form:"status" binding:"omitempty,oneof=pending approved rejected"`
+}
+
+func (q ReviewsQuery) params() models.ReviewListParams {
+	return models.ReviewListParams{Limit: q.Limit, Cursor: q.Cursor, Sort: q.Sort}
+}
+
+func reviewError(c *gin.Context, handler string, err error) {
+	switch {
+	case errors.Is(err, repository.ErrDoesNotExist):
+		xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Product not found")
+	case errors.Is(err, repository.ErrReviewNotFound):
+		xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Review not found")
+	case errors.Is(err, repository.ErrReviewExists):
+		xgin.ErrorResponse(c, http.StatusConflict, "Conflict", "You have already reviewed this product")
+	case errors.Is(err, repository.ErrOwnProductReview):
+		xgin.ErrorResponse(c, http.StatusForbidden, "Forbidden", "Sellers can't review their own products")
+	case errors.Is(err, repository.ErrOwnReviewVote):
+		xgin.ErrorResponse(c, http.StatusForbidden, "Forbidden", "You can't vote for your own review")
+	case errors.Is(err, repository.ErrInvalidCursor):
+		xgin.ErrorResponse(c, http.StatusBadRequest, "Bad request", "Invalid cursor")
+	default:
+		log.Printf("[ERROR] %s: %v", handler, err)
+		xgin.InternalError(c)
+	}
+}
+
+func ListCatalogReviewsHandler(svc reviewService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		var query ReviewsQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			xgin.BindError(c, err)
+			return
+		}
+
+		page, err := svc.ListPublished(c.Request.Context(), idStr, query.params())
+		if err != nil {
+			reviewError(c, "ListCatalogReviewsHandler", err)
+			return
+		}
+		c.JSON(http.StatusOK, page)
+	}
+}
+
+func CreateReviewHandler(svc reviewService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		var input ReviewRequest
+		if err := c.ShouldBindJSON(&input); err != nil {
+			xgin.BindError(c, err)
+			return
+		}
+
+		review, err := svc.Create(c.Request.Context(), idStr, userID, input.Rating, input.Title, input.Body)
+		if err != nil {
+			reviewError(c, "CreateReviewHandler", err)
+			return
+		}
+		c.JSON(http.StatusCreated, review)
+	}
+}
+
+func ListProductReviewsHandler(svc reviewService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		var query ProductReviewsQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			xgin.BindError(c, err)
+			return
+		}
+		params := query.params()
+		params.Status = query.Status
+
+		page, err := svc.ListByProduct(c.Request.Context(), idStr, userID, params)
+		if err != nil {
+			reviewError(c, "ListProductReviewsHandler", err)
+			return
+		}
+		c.JSON(http.StatusOK, page)
+	}
+}
+
+func ListMyReviewsHandler(svc reviewService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		var query ReviewsQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			xgin.BindError(c, err)
+			return
+		}
+
+		page, err := svc.ListByUser(c.Request.Context(), userID, query.params())
+		if err != nil {
+			reviewError(c, "ListMyReviewsHandler", err)
+			return
+		}
+		c.JSON(http.StatusOK, page)
+	}
+}
+
+func UpdateReviewHandler(svc reviewService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		var input ReviewRequest
+		if err := c.ShouldBindJSON(&input); err != nil {
+			xgin.BindError(c, err)
+			return
+		}
+
+		review, err := svc.Update(c.Request.Context(), idStr, userID, input.Rating, input.Title, input.Body)
+		if err != nil {
+			reviewError(c, "UpdateReviewHandler", err)
+			return
+		}
+		c.JSON(http.StatusOK, review)
+	}
+}
+
+func DeleteReviewHandler(svc reviewService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		if err := svc.Delete(c.Request.Context(), idStr, userID); err != nil {
+			reviewError(c, "DeleteReviewHandler", err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func ModerateReviewHandler(svc reviewService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		var input ReviewStatusRequest
+		if err := c.ShouldBindJSON(&input); err != nil {
+			xgin.BindError(c, err)
+			return
+		}
+
+		review, err := svc.Moderate(c.Request.Context(), idStr, userID, input.Status)
+		if err != nil {
+			reviewError(c, "ModerateReviewHandler", err)
+			return
+		}
+		c.JSON(http.StatusOK, review)
+	}
+}
+
+// ReplyToReviewHandler sets the seller's reply with PUT and removes it
+// with DELETE.
+func ReplyToReviewHandler(svc reviewService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		var reply *string
+		if c.Request.Method != http.MethodDelete {
+			var input ReviewReplyRequest
+			if err := c.ShouldBindJSON(&input); err != nil {
+				xgin.BindError(c, err)
+				return
+			}
+			reply = &input.Body
+		}
+
+		review, err := svc.Reply(c.Request.Context(), idStr, userID, reply)
+		if err != nil {
+			reviewError(c, "ReplyToReviewHandler", err)
+			return
+		}
+		c.JSON(http.StatusOK, review)
+	}
+}
+
+// VoteReviewHelpfulHandler marks the review helpful with PUT and takes the
+// vote back with DELETE.
+func VoteReviewHelpfulHandler(svc reviewService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		helpful := c.Request.Method != http.MethodDelete
+		review, err := svc.Vote(c.Request.Context(), idStr, userID, helpful)
+		if err != nil {
+			reviewError(c, "VoteReviewHelpfulHandler", err)
+			return
+		}
+		c.JSON(http.StatusOK, review)
+	}
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"e-commerce/internal/repository"
+	"e-commerce/internal/utils/xgin"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HistoryQuery struct {
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Cursor string `form:"cursor"`
+}
+
+func revisionError(c *gin.Context, handler string, err error) {
+	switch {
+	case errors.Is(err, repository.ErrDoesNotExist):
+		xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Product not found")
+	case errors.Is(err, repository.ErrRevisionNotFound):
+		xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Revision not found")
+	case errors.Is(err, repository.ErrAlreadyExists):
+		xgin.ErrorResponse(c, http.StatusConflict, "Conflict", "A product with the same name and price already exists")
+	case errors.Is(err, repository.ErrInvalidCursor):
+		xgin.ErrorResponse(c, http.StatusBadRequest, "Bad request", "Invalid cursor")
+	default:
+		log.Printf("[ERROR] %s: %v", handler, err)
+		xgin.InternalError(c)
+	}
+}
+
+func GetProductHistoryHandler(svc revisionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		var query HistoryQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			xgin.BindError(c, err)
+			return
+		}
+
+		page, err := svc.History(c.Request.Context(), idStr, userID, query.Limit, query.Cursor)
+		if err != nil {
+			revisionError(c, "GetProductHistoryHandler", err)
+			return
+		}
+		c.JSON(http.StatusOK, page)
+	}
+}
+
+func RevertProductHandler(svc revisionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+		revision, err := strconv.Atoi(c.Param("revision"))
+		if err != nil || revision < 1 {
+			xgin.ErrorResponse(c, http.StatusBadRequest, "Bad request", "Revision must be a positive integer")
+			return
+		}
+
+		product, err := svc.Revert(c.Request.Context(), idStr, userID, revision)
+		if err != nil {
+			revisionError(c, "RevertProductHandler", err)
+			return
+		}
+		setProductETag(c, product)
+		c.JSON(http.StatusOK, product)
+	}
+}
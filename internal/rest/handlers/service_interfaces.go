@@ -3,23 +3,136 @@ package handlers
 import (
 	"context"
 	"e-commerce/internal/domain/models"
+	"e-commerce/internal/service"
+	"io"
+	"time"
 )
 
-type productService interface{
-	Create(ctx context.Context, name string, price float64, userID string) (*models.Product, error)
-	Delete(ctx context.Context, productID string, userID string) error
-	Update(ctx context.Context, productID string, userID string, name string, price float64) (*models.Product, error)
-	Patch(ctx context.Context, productID string, userID string, updates map[string]any) (*models.Product, error)
-	GetAll(ctx context.Context, userID string) ([]models.Product, error)
+type productService interface {
+	Create(ctx context.Context, name string, price models.Money, attrs map[string]any, tags []string, userID string) (*models.Product, error)
+	Delete(ctx context.Context, productID string, userID string, ifMatch []int) error
+	Trash(ctx context.Context, userID string, limit int, cursor string) (*models.ProductPage, error)
+	Restore(ctx context.Context, productID string, userID string) (*models.Product, error)
+	Update(ctx context.Context, productID string, userID string, name string, price models.Money, attrs map[string]any, tags []string, ifMatch []int) (*models.Product, error)
+	Patch(ctx context.Context, productID string, userID string, updates map[string]any, ifMatch []int) (*models.Product, error)
+	GetAll(ctx context.Context, userID string, params models.ProductListParams) (*models.ProductPage, error)
 	GetByID(ctx context.Context, id string, userID string) (*models.Product, error)
+	Search(ctx context.Context, userID string, params models.ProductSearchParams) (*models.ProductSearchPage, error)
+	Export(ctx context.Context, userID string, params models.ProductListParams, fn func(*models.Product) error) error
+	Batch(ctx context.Context, userID string, ops []models.ProductOperation, continueOnError bool) ([]models.ProductOperationResult, bool, error)
+	SetStatus(ctx context.Context, productID, userID, status string, ifMatch []int) (*models.Product, error)
+	SetSchedule(ctx context.Context, productID, userID string, publishAt, unpublishAt *time.Time, ifMatch []int) (*models.Product, error)
+	GetBySlug(ctx context.Context, slug, userID string) (*models.Product, error)
+	SetSlug(ctx context.Context, productID, userID, slug string, ifMatch []int) (*models.Product, error)
+	SetType(ctx context.Context, productID, userID, productType string, ifMatch []int) (*models.Product, error)
 }
 
-type userService interface{
+type revisionService interface {
+	History(ctx context.Context, productID, userID string, limit int, cursor string) (*models.ProductRevisionPage, error)
+	Revert(ctx context.Context, productID, userID string, revision int) (*models.Product, error)
+}
+
+type catalogService interface {
+	GetByID(ctx context.Context, id string) (*models.Product, error)
+	GetBySlug(ctx context.Context, slug string) (*models.Product, error)
+	List(ctx context.Context, sellerID string, params models.ProductListParams) (*models.ProductPage, error)
+	Search(ctx context.Context, params models.ProductSearchParams) (*models.ProductSearchPage, error)
+	ListByCategory(ctx context.Context, categoryID string, params models.ProductListParams) (*models.ProductPage, error)
+}
+
+type priceConverter interface {
+	ConvertProducts(ctx context.Context, currency string, products ...*models.Product) error
+}
+
+type categoryService interface {
+	Create(ctx context.Context, userID, name string, parentID *string) (*models.Category, error)
+	GetByID(ctx context.Context, id, userID string) (*models.Category, error)
+	GetAll(ctx context.Context, userID string) ([]models.Category, error)
+	Update(ctx context.Context, id, userID, name string, parentID *string) (*models.Category, error)
+	Delete(ctx context.Context, id, userID string, cascade bool) error
+	SetProductCategories(ctx context.Context, productID, userID string, categoryIDs []string) ([]models.Category, error)
+	GetProductCategories(ctx context.Context, productID, userID string) ([]models.Category, error)
+	ListProducts(ctx context.Context, categoryID, userID string, params models.ProductListParams) (*models.ProductPage, error)
+	SetAttributes(ctx context.Context, id, userID string, defs []models.AttributeDefinition) (*models.Category, error)
+	GetAttributes(ctx context.Context, id, userID string) ([]models.AttributeDefinition, error)
+}
+
+type variantService interface {
+	GetByProduct(ctx context.Context, productID, userID string) (*models.ProductVariants, error)
+	SetOptions(ctx context.Context, productID, userID string, options []models.ProductOption) (*models.ProductVariants, error)
+	UpdateVariant(ctx context.Context, productID, variantID, userID, sku string, price *models.Money) (*models.ProductVariant, error)
+	DeleteVariant(ctx context.Context, productID, variantID, userID string) error
+}
+
+type imageService interface {
+	MaxBytes() int64
+	GetByProduct(ctx context.Context, productID, userID string) ([]models.ProductImage, error)
+	Upload(ctx context.Context, productID, userID string, data []byte) (*models.ProductImage, error)
+	Reorder(ctx context.Context, productID, userID string, imageIDs []string) ([]models.ProductImage, error)
+	Delete(ctx context.Context, productID, imageID, userID string) error
+}
+
+type fileService interface {
+	MaxBytes() int64
+	GetByProduct(ctx context.Context, productID, userID string) ([]models.ProductFile, error)
+	Upload(ctx context.Context, productID, userID, name string, r io.Reader, size int64) (*models.ProductFile, error)
+	Delete(ctx context.Context, productID, fileID, userID string) error
+}
+
+type downloadService interface {
+	IssueLinks(ctx context.Context, productID, userID string) ([]models.DownloadLink, error)
+	Open(ctx context.Context, token string) (*models.ProductFile, io.ReadCloser, error)
+}
+
+type priceService interface {
+	Timeline(ctx context.Context, productID, userID string) (*models.PriceTimeline, error)
+	Schedule(ctx context.Context, productID, userID string, price models.Money, effectiveFrom time.Time, effectiveTo *time.Time) (*models.ScheduledPrice, error)
+	Cancel(ctx context.Context, productID, scheduleID, userID string) error
+}
+
+type reviewService interface {
+	ListPublished(ctx context.Context, productID string, params models.ReviewListParams) (*models.ReviewPage, error)
+	ListByProduct(ctx context.Context, productID, sellerID string, params models.ReviewListParams) (*models.ReviewPage, error)
+	ListByUser(ctx context.Context, userID string, params models.ReviewListParams) (*models.ReviewPage, error)
+	Create(ctx context.Context, productID, userID string, rating int, title, body string) (*models.Review, error)
+	Update(ctx context.Context, reviewID, userID string, rating int, title, body string) (*models.Review, error)
+	Delete(ctx context.Context, reviewID, userID string) error
+	Moderate(ctx context.Context, reviewID, sellerID, status string) (*models.Review, error)
+	Reply(ctx context.Context, reviewID, sellerID string, reply *string) (*models.Review, error)
+	Vote(ctx context.Context, reviewID, userID string, helpful bool) (*models.Review, error)
+}
+
+type recommendationService interface {
+	Related(ctx context.Context, productID string, limit int) ([]models.RelatedProduct, error)
+}
+
+type bundleService interface {
+	Get(ctx context.Context, productID, userID string) (*models.ProductBundle, error)
+	Set(ctx context.Context, productID, userID string, items []models.BundleItem, price *models.Money, discountPercent *int, ifMatch []int) (*models.Product, error)
+	Remove(ctx context.Context, productID, userID string) error
+}
+
+type importService interface {
+	MaxBytes() int64
+	Start(ctx context.Context, userID, format string, file io.ReaderAt, size int64, dryRun bool, validate service.ImportRowValidator) (*models.ImportJob, error)
+	Get(ctx context.Context, id, userID string) (*models.ImportJob, error)
+}
+
+type inventoryService interface {
+	GetStock(ctx context.Context, productID, userID string) ([]models.StockLevel, error)
+	RecordMovement(ctx context.Context, productID string, variantID *string, userID string, quantity int, reason, note string) (*models.StockLevel, error)
+	ListMovements(ctx context.Context, productID, userID string, limit int, cursor string) (*models.StockMovementPage, error)
+	Reserve(ctx context.Context, productID string, variantID *string, buyerID string, quantity int) (*models.Reservation, error)
+	CommitReservation(ctx context.Context, reservationID, buyerID string) (*models.Reservation, error)
+	ReleaseReservation(ctx context.Context, reservationID, buyerID string) (*models.Reservation, error)
+}
+
+type userService interface {
 	Register(ctx context.Context, email, password string) (*models.User, error)
 	Login(ctx context.Context, email, password string) (string, error)
 }
 
 type userQuerier interface {
-    GetUserByEmail(ctx context.Context, email string) (*models.User, error)
-    GetUserByID(ctx context.Context, id string) (*models.User, error)
-}
\ No newline at end of file
+	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+	GetUserByID(ctx context.Context, id string) (*models.User, error)
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"e-commerce/internal/domain/models"
+	"e-commerce/internal/repository"
+	"e-commerce/internal/service"
+	"e-commerce/internal/utils/xgin"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProductOptionRequest struct {
+	Name   string   `json:"name" binding:"required,min=1,max=50"`
+	Values []string `json:"values" binding:"required,min=1,max=50,unique,dive,required,max=50"`
+}
+
+type ProductOptionsRequest struct {
+	Options []ProductOptionRequest `json:"options" binding:"max=3,dive"`
+}
+
+type VariantRequest struct {
+	SKU   string        `json:"sku" binding:"required,min=1,max=64"`
+	Price *models.Money `json:"price" binding:"omitempty,money"`
+}
+
+func variantError(c *gin.Context, handler string, err error) {
+	switch {
+	case errors.Is(err, repository.ErrDoesNotExist):
+		xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Product not found")
+	case errors.Is(err, repository.ErrVariantNotFound):
+		xgin.ErrorResponse(c, http.StatusNotFound, "Not found", "Variant not found")
+	case errors.Is(err, repository.ErrCurrencyMismatch):
+		xgin.ErrorResponse(c, http.StatusUnprocessableEntity, "Validation error", "Variant price must use the product currency")
+	case errors.Is(err, repository.ErrSKUAlreadyExists):
+		xgin.ErrorResponse(c, http.StatusConflict, "Conflict", "Variant with this SKU already exists")
+	case errors.Is(err, repository.ErrBundleVariants):
+		xgin.ErrorResponse(c, http.StatusUnprocessableEntity, "Validation error", "Bundles and their components can't have variants")
+	case errors.Is(err, service.ErrDuplicateOption):
+		xgin.ErrorResponse(c, http.StatusUnprocessableEntity, "Validation error", "Option names must be unique")
+	case errors.Is(err, service.ErrTooManyVariants):
+		xgin.ErrorResponse(c, http.StatusUnprocessableEntity, "Validation error", "Option combinations exceed the variant limit")
+	default:
+		log.Printf("[ERROR] %s: %v", handler, err)
+		xgin.InternalError(c)
+	}
+}
+
+func GetProductVariantsHandler(svc variantService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		variants, err := svc.GetByProduct(c.Request.Context(), idStr, userID)
+		if err != nil {
+			variantError(c, "GetProductVariantsHandler", err)
+			return
+		}
+		c.JSON(http.StatusOK, variants)
+	}
+}
+
+func SetProductOptionsHandler(svc variantService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+
+		var input ProductOptionsRequest
+		if err := c.ShouldBindJSON(&input); err != nil {
+			xgin.BindError(c, err)
+			return
+		}
+
+		options := make([]models.ProductOption, len(input.Options))
+		for i, o := range input.Options {
+			options[i] = models.ProductOption{Name: o.Name, Position: i, Values: o.Values}
+		}
+
+		variants, err := svc.SetOptions(c.Request.Context(), idStr, userID, options)
+		if err != nil {
+			variantError(c, "SetProductOptionsHandler", err)
+			return
+		}
+		c.JSON(http.StatusOK, variants)
+	}
+}
+
+func UpdateVariantHandler(svc variantService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+		variantID, ok := xgin.ParseUUIDParam(c, "variant_id")
+		if !ok {
+			return
+		}
+
+		var input VariantRequest
+		if err := c.ShouldBindJSON(&input); err != nil {
+			xgin.BindError(c, err)
+			return
+		}
+
+		variant, err := svc.UpdateVariant(c.Request.Context(), idStr, variantID, userID, input.SKU, input.Price)
+		if err != nil {
+			variantError(c, "UpdateVariantHandler", err)
+			return
+		}
+		c.JSON(http.StatusOK, variant)
+	}
+}
+
+func DeleteVariantHandler(svc variantService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := xgin.GetUserID(c)
+
+		if !exists {
+			xgin.AbortMissingUserID(c)
+			return
+		}
+
+		idStr, ok := xgin.ParseUUID(c)
+		if !ok {
+			return
+		}
+		variantID, ok := xgin.ParseUUIDParam(c, "variant_id")
+		if !ok {
+			return
+		}
+
+		if err := svc.DeleteVariant(c.Request.Context(), idStr, variantID, userID); err != nil {
+			variantError(c, "DeleteVariantHandler", err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
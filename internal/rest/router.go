@@ -6,7 +6,9 @@ import (
 	"e-commerce/internal/repository"
 	"e-commerce/internal/rest/handlers"
 	"e-commerce/internal/service"
+	"e-commerce/internal/utils/xgin"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,9 +17,24 @@ func SetupRouter(
 	userRepo *repository.PgUserRepo,
 	userService *service.UserService,
 	productService *service.ProductService,
+	catalogService *service.CatalogService,
+	categoryService *service.CategoryService,
+	variantService *service.VariantService,
+	inventoryService *service.InventoryService,
+	exchangeRateService *service.ExchangeRateService,
+	imageService *service.ImageService,
+	revisionService *service.RevisionService,
+	importService *service.ImportService,
+	priceService *service.PriceService,
+	reviewService *service.ReviewService,
+	recommendationService *service.RecommendationService,
+	bundleService *service.BundleService,
+	fileService *service.FileService,
+	downloadService *service.DownloadService,
 	blacklist *repository.Blacklist,
 	cfg *config.Config,
 ) *gin.Engine {
+	xgin.RegisterValidators()
 	router := gin.Default()
 	router.GET("/", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
@@ -27,9 +44,20 @@ func SetupRouter(
 		})
 	})
 
+	// local blob storage is served directly; a CDN or S3 URL is not ours to serve
+	if strings.HasPrefix(cfg.MediaURL, "/") {
+		router.Static(cfg.MediaURL, cfg.MediaDir)
+	}
+
 	products := router.Group("/products")
 	users := router.Group("/users")
+	categories := router.Group("/categories")
+	reservations := router.Group("/reservations")
+	reviews := router.Group("/reviews")
 	products.Use(middleware.AuthMiddleware(cfg, blacklist))
+	categories.Use(middleware.AuthMiddleware(cfg, blacklist))
+	reservations.Use(middleware.AuthMiddleware(cfg, blacklist))
+	reviews.Use(middleware.AuthMiddleware(cfg, blacklist))
 	users.Use(middleware.AuthMiddleware(cfg, blacklist))
 	authGroup := router.Group("/auth")
 
@@ -38,11 +66,84 @@ func SetupRouter(
 	authGroup.POST("/logout", middleware.AuthMiddleware(cfg, blacklist), handlers.LogoutHandler(blacklist))
 
 	products.POST("", handlers.CreateProductHandler(productService))
-	products.GET("/:id", handlers.GetProductByIdHandler(productService))
-	products.GET("", handlers.GetAllProductsHandler(productService))
-	products.PUT("/:id", handlers.UpdateProductHandler(productService))
-	products.PATCH("/:id", handlers.PatchProductHandler(productService))
-	products.DELETE("/:id", handlers.DeleteProductByIdHandler(productService))
+	products.POST("/batch", handlers.BatchProductsHandler(productService, cfg.RequireIfMatch))
+	products.GET("/search", handlers.SearchProductsHandler(productService, exchangeRateService))
+	products.GET("/trash", handlers.ListTrashHandler(productService))
+	products.GET("/export", handlers.ExportProductsHandler(productService))
+	products.POST("/import", handlers.ImportProductsHandler(importService))
+	products.GET("/imports/:job_id", handlers.GetImportJobHandler(importService))
+	products.GET("/by-slug/:slug", handlers.GetProductBySlugHandler(productService, exchangeRateService))
+	products.GET("/:id", handlers.GetProductByIdHandler(productService, exchangeRateService))
+	products.GET("", handlers.GetAllProductsHandler(productService, exchangeRateService))
+	products.PUT("/:id", middleware.RequireIfMatch(cfg), handlers.UpdateProductHandler(productService))
+	products.PATCH("/:id", middleware.RequireIfMatch(cfg), handlers.PatchProductHandler(productService))
+	products.DELETE("/:id", middleware.RequireIfMatch(cfg), handlers.DeleteProductByIdHandler(productService))
+	products.POST("/:id/restore", handlers.RestoreProductHandler(productService))
+	products.PUT("/:id/status", middleware.RequireIfMatch(cfg), handlers.SetProductStatusHandler(productService))
+	products.PUT("/:id/schedule", middleware.RequireIfMatch(cfg), handlers.SetProductScheduleHandler(productService))
+	products.PUT("/:id/slug", middleware.RequireIfMatch(cfg), handlers.SetProductSlugHandler(productService))
+	products.PUT("/:id/type", middleware.RequireIfMatch(cfg), handlers.SetProductTypeHandler(productService))
+	products.GET("/:id/history", handlers.GetProductHistoryHandler(revisionService))
+	products.POST("/:id/revert/:revision", handlers.RevertProductHandler(revisionService))
+	products.GET("/:id/categories", handlers.GetProductCategoriesHandler(categoryService))
+	products.PUT("/:id/categories", handlers.SetProductCategoriesHandler(categoryService))
+	products.GET("/:id/variants", handlers.GetProductVariantsHandler(variantService))
+	products.PUT("/:id/variants/options", handlers.SetProductOptionsHandler(variantService))
+	products.PUT("/:id/variants/:variant_id", handlers.UpdateVariantHandler(variantService))
+	products.DELETE("/:id/variants/:variant_id", handlers.DeleteVariantHandler(variantService))
+	products.GET("/:id/images", handlers.GetProductImagesHandler(imageService))
+	products.POST("/:id/images", handlers.UploadProductImageHandler(imageService))
+	products.PUT("/:id/images/order", handlers.ReorderProductImagesHandler(imageService))
+	products.DELETE("/:id/images/:image_id", handlers.DeleteProductImageHandler(imageService))
+	products.GET("/:id/files", handlers.GetProductFilesHandler(fileService))
+	products.POST("/:id/files", handlers.UploadProductFileHandler(fileService))
+	products.DELETE("/:id/files/:file_id", handlers.DeleteProductFileHandler(fileService))
+	products.GET("/:id/prices", handlers.GetProductPricesHandler(priceService))
+	products.POST("/:id/prices", handlers.SchedulePriceHandler(priceService))
+	products.DELETE("/:id/prices/:price_id", handlers.CancelScheduledPriceHandler(priceService))
+	products.GET("/:id/reviews", handlers.ListProductReviewsHandler(reviewService))
+	products.GET("/:id/bundle", handlers.GetProductBundleHandler(bundleService, productService))
+	products.PUT("/:id/bundle", middleware.RequireIfMatch(cfg), handlers.SetProductBundleHandler(bundleService, productService))
+	products.DELETE("/:id/bundle", handlers.DeleteProductBundleHandler(bundleService, productService))
+	products.GET("/:id/stock", handlers.GetStockHandler(inventoryService))
+	products.POST("/:id/stock/movements", handlers.RecordStockMovementHandler(inventoryService))
+	products.GET("/:id/stock/movements", handlers.ListStockMovementsHandler(inventoryService))
+
+	reservations.POST("", handlers.CreateReservationHandler(inventoryService))
+	reservations.POST("/:id/commit", handlers.CommitReservationHandler(inventoryService))
+	reservations.POST("/:id/release", handlers.ReleaseReservationHandler(inventoryService))
+
+	reviews.GET("", handlers.ListMyReviewsHandler(reviewService))
+	reviews.PUT("/:id", handlers.UpdateReviewHandler(reviewService))
+	reviews.DELETE("/:id", handlers.DeleteReviewHandler(reviewService))
+	reviews.PUT("/:id/status", handlers.ModerateReviewHandler(reviewService))
+	reviews.PUT("/:id/reply", handlers.ReplyToReviewHandler(reviewService))
+	reviews.DELETE("/:id/reply", handlers.ReplyToReviewHandler(reviewService))
+	reviews.PUT("/:id/helpful", handlers.VoteReviewHelpfulHandler(reviewService))
+	reviews.DELETE("/:id/helpful", handlers.VoteReviewHelpfulHandler(reviewService))
+
+	categories.POST("", handlers.CreateCategoryHandler(categoryService))
+	categories.GET("", handlers.GetAllCategoriesHandler(categoryService))
+	categories.GET("/:id", handlers.GetCategoryByIdHandler(categoryService))
+	categories.PUT("/:id", handlers.UpdateCategoryHandler(categoryService))
+	categories.DELETE("/:id", handlers.DeleteCategoryHandler(categoryService))
+	categories.GET("/:id/products", handlers.ListCategoryProductsHandler(categoryService, exchangeRateService))
+	categories.GET("/:id/attributes", handlers.GetCategoryAttributesHandler(categoryService))
+	categories.PUT("/:id/attributes", handlers.SetCategoryAttributesHandler(categoryService))
+
+	catalog := router.Group("/catalog")
+	catalog.GET("/products", handlers.ListCatalogProductsHandler(catalogService, exchangeRateService))
+	catalog.GET("/products/search", handlers.SearchCatalogProductsHandler(catalogService, exchangeRateService))
+	catalog.GET("/products/by-slug/:slug", handlers.GetCatalogProductBySlugHandler(catalogService, exchangeRateService))
+	catalog.GET("/products/:id", handlers.GetCatalogProductHandler(catalogService, exchangeRateService))
+	catalog.GET("/products/:id/related", handlers.ListRelatedProductsHandler(recommendationService, exchangeRateService))
+	catalog.GET("/products/:id/reviews", handlers.ListCatalogReviewsHandler(reviewService))
+	catalog.POST("/products/:id/reviews", middleware.AuthMiddleware(cfg, blacklist), handlers.CreateReviewHandler(reviewService))
+	catalog.POST("/products/:id/downloads", middleware.AuthMiddleware(cfg, blacklist), handlers.CreateDownloadLinksHandler(downloadService))
+	catalog.GET("/categories/:id/products", handlers.ListCatalogCategoryProductsHandler(catalogService, exchangeRateService))
+
+	// the signed token is the credential, so the link works without a login
+	router.GET("/downloads/:token", handlers.DownloadFileHandler(downloadService))
 
 	users.GET("/id/:id", handlers.GetUserByIdHandler(userRepo))
 	users.GET("/email/:email", handlers.GetUserByEmailHandler(userRepo))
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"e-commerce/internal/domain/models"
+)
+
+type bundleRepo interface {
+	Get(ctx context.Context, productID, userID string) (*models.ProductBundle, error)
+	Set(ctx context.Context, productID, userID string, items []models.BundleItem, price *models.Money, discountPercent *int, ifMatch []int) (*models.Product, error)
+	Remove(ctx context.Context, productID, userID string) error
+}
+
+type BundleService struct {
+	repo bundleRepo
+}
+
+func NewBundleService(repo bundleRepo) *BundleService {
+	return &BundleService{repo: repo}
+}
+
+func (s *BundleService) Get(ctx context.Context, productID, userID string) (*models.ProductBundle, error) {
+	return s.repo.Get(ctx, productID, userID)
+}
+
+// Set makes the product a bundle of items priced either at price or at
+// the items' total less discountPercent.
+func (s *BundleService) Set(ctx context.Context, productID, userID string, items []models.BundleItem, price *models.Money, discountPercent *int, ifMatch []int) (*models.Product, error) {
+	return s.repo.Set(ctx, productID, userID, items, price, discountPercent, ifMatch)
+}
+
+func (s *BundleService) Remove(ctx context.Context, productID, userID string) error {
+	return s.repo.Remove(ctx, productID, userID)
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"e-commerce/internal/domain/models"
+)
+
+type catalogRepo interface {
+	GetByID(ctx context.Context, id string) (*models.Product, error)
+	GetBySlug(ctx context.Context, slug string) (*models.Product, error)
+	List(ctx context.Context, sellerID string, params models.ProductListParams) (*models.ProductPage, error)
+	Search(ctx context.Context, params models.ProductSearchParams) (*models.ProductSearchPage, error)
+	ListByCategory(ctx context.Context, categoryID string, params models.ProductListParams) (*models.ProductPage, error)
+}
+
+// CatalogService serves the public, read-only storefront view of products
+// across all sellers.
+type CatalogService struct {
+	repo catalogRepo
+}
+
+func NewCatalogService(repo catalogRepo) *CatalogService {
+	return &CatalogService{repo: repo}
+}
+
+func (s *CatalogService) GetByID(ctx context.Context, id string) (*models.Product, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// GetBySlug also finds products by a slug they had before; the returned
+// product's Slug is the current one.
+func (s *CatalogService) GetBySlug(ctx context.Context, slug string) (*models.Product, error) {
+	return s.repo.GetBySlug(ctx, slug)
+}
+
+func (s *CatalogService) List(ctx context.Context, sellerID string, params models.ProductListParams) (*models.ProductPage, error) {
+	return s.repo.List(ctx, sellerID, params)
+}
+
+func (s *CatalogService) Search(ctx context.Context, params models.ProductSearchParams) (*models.ProductSearchPage, error) {
+	return s.repo.Search(ctx, params)
+}
+
+func (s *CatalogService) ListByCategory(ctx context.Context, categoryID string, params models.ProductListParams) (*models.ProductPage, error) {
+	return s.repo.ListByCategory(ctx, categoryID, params)
+}
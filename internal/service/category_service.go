@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"e-commerce/internal/domain/models"
+)
+
+type categoryRepo interface {
+	Create(ctx context.Context, userID, name string, parentID *string) (*models.Category, error)
+	GetByID(ctx context.Context, id, userID string) (*models.Category, error)
+	GetAll(ctx context.Context, userID string) ([]models.Category, error)
+	Update(ctx context.Context, id, userID, name string, parentID *string) (*models.Category, error)
+	Delete(ctx context.Context, id, userID string, cascade bool) error
+	SetProductCategories(ctx context.Context, productID, userID string, categoryIDs []string) ([]models.Category, error)
+	GetProductCategories(ctx context.Context, productID, userID string) ([]models.Category, error)
+	ListProducts(ctx context.Context, categoryID, userID string, params models.ProductListParams) (*models.ProductPage, error)
+	SetAttributes(ctx context.Context, id, userID string, defs []models.AttributeDefinition) (*models.Category, error)
+	GetAttributes(ctx context.Context, id, userID string) ([]models.AttributeDefinition, error)
+}
+
+type CategoryService struct {
+	repo categoryRepo
+}
+
+func NewCategoryService(repo categoryRepo) *CategoryService {
+	return &CategoryService{repo: repo}
+}
+
+func (s *CategoryService) Create(ctx context.Context, userID, name string, parentID *string) (*models.Category, error) {
+	return s.repo.Create(ctx, userID, name, parentID)
+}
+
+func (s *CategoryService) GetByID(ctx context.Context, id, userID string) (*models.Category, error) {
+	return s.repo.GetByID(ctx, id, userID)
+}
+
+func (s *CategoryService) GetAll(ctx context.Context, userID string) ([]models.Category, error) {
+	return s.repo.GetAll(ctx, userID)
+}
+
+func (s *CategoryService) Update(ctx context.Context, id, userID, name string, parentID *string) (*models.Category, error) {
+	return s.repo.Update(ctx, id, userID, name, parentID)
+}
+
+func (s *CategoryService) Delete(ctx context.Context, id, userID string, cascade bool) error {
+	return s.repo.Delete(ctx, id, userID, cascade)
+}
+
+func (s *CategoryService) SetProductCategories(ctx context.Context, productID, userID string, categoryIDs []string) ([]models.Category, error) {
+	return s.repo.SetProductCategories(ctx, productID, userID, categoryIDs)
+}
+
+func (s *CategoryService) GetProductCategories(ctx context.Context, productID, userID string) ([]models.Category, error) {
+	return s.repo.GetProductCategories(ctx, productID, userID)
+}
+
+func (s *CategoryService) ListProducts(ctx context.Context, categoryID, userID string, params models.ProductListParams) (*models.ProductPage, error) {
+	return s.repo.ListProducts(ctx, categoryID, userID, params)
+}
+
+func (s *CategoryService) SetAttributes(ctx context.Context, id, userID string, defs []models.AttributeDefinition) (*models.Category, error) {
+	return s.repo.SetAttributes(ctx, id, userID, defs)
+}
+
+func (s *CategoryService) GetAttributes(ctx context.Context, id, userID string) ([]models.AttributeDefinition, error) {
+	return s.repo.GetAttributes(ctx, id, userID)
+}
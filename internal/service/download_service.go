@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"e-commerce/internal/auth"
+	"e-commerce/internal/domain/models"
+	"e-commerce/internal/storage"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// downloadPath is where the router serves GET /downloads/:token.
+const downloadPath = "/downloads/"
+
+type downloadRepo interface {
+	CreateLinks(ctx context.Context, productID, userID string, maxDownloads int, expiresAt time.Time) ([]models.DownloadLink, error)
+	File(ctx context.Context, linkID uuid.UUID) (*models.ProductFile, error)
+	Use(ctx context.Context, linkID uuid.UUID) error
+	DeleteExpired(ctx context.Context) (int, error)
+}
+
+type DownloadService struct {
+	repo   downloadRepo
+	files  storage.BlobStore
+	secret string
+	ttl    time.Duration
+	limit  int
+}
+
+// NewDownloadService signs download tokens with a key derived from the
+// JWT secret. Each link lasts ttl and allows limit downloads.
+func NewDownloadService(repo downloadRepo, files storage.BlobStore, secret string, ttl time.Duration, limit int) *DownloadService {
+	return &DownloadService{repo: repo, files: files, secret: secret, ttl: ttl, limit: limit}
+}
+
+// IssueLinks returns a fresh signed link to each of the product's files.
+func (s *DownloadService) IssueLinks(ctx context.Context, productID, userID string) ([]models.DownloadLink, error) {
+	// tokens carry the expiry to the second
+	expires := time.Now().Add(s.ttl).Truncate(time.Second)
+	links, err := s.repo.CreateLinks(ctx, productID, userID, s.limit, expires)
+	if err != nil {
+		return nil, err
+	}
+	for i := range links {
+		links[i].URL = downloadPath + auth.SignDownload(s.secret, links[i].ID, expires)
+	}
+	return links, nil
+}
+
+// Open checks the token, opens the file and then counts the download
+// against its link, so a storage failure doesn't cost the buyer one. The
+// caller must close the reader.
+func (s *DownloadService) Open(ctx context.Context, token string) (*models.ProductFile, io.ReadCloser, error) {
+	linkID, err := auth.ParseDownload(s.secret, token, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+	file, err := s.repo.File(ctx, linkID)
+	if err != nil {
+		return nil, nil, err
+	}
+	blob, err := s.files.Open(ctx, file.BlobKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.repo.Use(ctx, linkID); err != nil {
+		blob.Close()
+		return nil, nil, err
+	}
+	return file, blob, nil
+}
+
+func (s *DownloadService) DeleteExpiredLinks(ctx context.Context) (int, error) {
+	return s.repo.DeleteExpired(ctx)
+}
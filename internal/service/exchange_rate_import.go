@@ -0,0 +1,118 @@
+package service
+
+import (
+	"e-commerce/internal/domain/models"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ParseRatesCSV reads rates from a CSV file with a header row naming the
+// columns base, quote, rate and optionally as_of (a date or RFC 3339
+// timestamp). Rows without as_of are stamped with the current time.
+func ParseRatesCSV(r io.Reader, source string) ([]models.ExchangeRate, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"base", "quote", "rate"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("header is missing the %q column", required)
+		}
+	}
+	asOfColumn, hasAsOf := columns["as_of"]
+
+	now := time.Now().UTC()
+	var rates []models.ExchangeRate
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		rate := models.ExchangeRate{
+			Base:   record[columns["base"]],
+			Quote:  record[columns["quote"]],
+			Rate:   record[columns["rate"]],
+			AsOf:   now,
+			Source: source,
+		}
+		if hasAsOf && strings.TrimSpace(record[asOfColumn]) != "" {
+			rate.AsOf, err = parseRateDate(strings.TrimSpace(record[asOfColumn]))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+		}
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
+
+func parseRateDate(s string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("as_of %q is neither YYYY-MM-DD nor RFC 3339", s)
+	}
+	return t, nil
+}
+
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string `xml:"currency,attr"`
+			Rate     string `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+// ParseECBRates reads the European Central Bank reference rate XML
+// (eurofxref-daily.xml or one of the historical files). All rates are
+// quoted against EUR; currencies we don't support are skipped.
+func ParseECBRates(r io.Reader) ([]models.ExchangeRate, error) {
+	var envelope ecbEnvelope
+	if err := xml.NewDecoder(r).Decode(&envelope); err != nil {
+		return nil, fmt.Errorf("decode ECB XML: %w", err)
+	}
+	if len(envelope.Days) == 0 {
+		return nil, errors.New("no rates found in ECB XML")
+	}
+
+	var rates []models.ExchangeRate
+	for _, day := range envelope.Days {
+		asOf, err := time.Parse(time.DateOnly, day.Time)
+		if err != nil {
+			return nil, fmt.Errorf("ECB date %q: %w", day.Time, err)
+		}
+		for _, cube := range day.Rates {
+			if _, ok := models.CurrencyExponent(cube.Currency); !ok {
+				continue
+			}
+			rates = append(rates, models.ExchangeRate{
+				Base:   "EUR",
+				Quote:  cube.Currency,
+				Rate:   cube.Rate,
+				AsOf:   asOf,
+				Source: "ecb",
+			})
+		}
+	}
+	return rates, nil
+}
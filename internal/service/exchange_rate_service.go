@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"e-commerce/internal/domain/models"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var ErrNoExchangeRate = errors.New("no exchange rate for currency pair")
+var ErrInvalidExchangeRate = errors.New("invalid exchange rate")
+
+var decimalRatePattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+type exchangeRateRepo interface {
+	GetAll(ctx context.Context) ([]models.ExchangeRate, error)
+	Upsert(ctx context.Context, rates []models.ExchangeRate) (int, error)
+}
+
+type ExchangeRateService struct {
+	repo exchangeRateRepo
+}
+
+func NewExchangeRateService(repo exchangeRateRepo) *ExchangeRateService {
+	return &ExchangeRateService{repo: repo}
+}
+
+// Import validates rates and stores the most recent one for each pair.
+// It returns the number of stored rates that changed.
+func (s *ExchangeRateService) Import(ctx context.Context, rates []models.ExchangeRate) (int, error) {
+	latest := make(map[[2]string]models.ExchangeRate)
+	for i, rate := range rates {
+		rate.Base = strings.ToUpper(strings.TrimSpace(rate.Base))
+		rate.Quote = strings.ToUpper(strings.TrimSpace(rate.Quote))
+		rate.Rate = strings.TrimSpace(rate.Rate)
+		if err := validateRate(rate); err != nil {
+			return 0, fmt.Errorf("rate %d (%s/%s): %w", i+1, rate.Base, rate.Quote, err)
+		}
+		key := [2]string{rate.Base, rate.Quote}
+		if prev, ok := latest[key]; !ok || !rate.AsOf.Before(prev.AsOf) {
+			latest[key] = rate
+		}
+	}
+
+	unique := make([]models.ExchangeRate, 0, len(latest))
+	for _, rate := range latest {
+		unique = append(unique, rate)
+	}
+	// deterministic order keeps concurrent imports from deadlocking
+	sort.Slice(unique, func(i, j int) bool {
+		if unique[i].Base != unique[j].Base {
+			return unique[i].Base < unique[j].Base
+		}
+		return unique[i].Quote < unique[j].Quote
+	})
+	return s.repo.Upsert(ctx, unique)
+}
+
+func validateRate(rate models.ExchangeRate) error {
+	if _, ok := models.CurrencyExponent(rate.Base); !ok {
+		return fmt.Errorf("%w: base %q", models.ErrUnknownCurrency, rate.Base)
+	}
+	if _, ok := models.CurrencyExponent(rate.Quote); !ok {
+		return fmt.Errorf("%w: quote %q", models.ErrUnknownCurrency, rate.Quote)
+	}
+	if rate.Base == rate.Quote {
+		return fmt.Errorf("%w: base and quote are the same", ErrInvalidExchangeRate)
+	}
+	if !decimalRatePattern.MatchString(rate.Rate) {
+		return fmt.Errorf("%w: %q is not a decimal number", ErrInvalidExchangeRate, rate.Rate)
+	}
+	if r, _ := new(big.Rat).SetString(rate.Rate); r.Sign() <= 0 {
+		return fmt.Errorf("%w: rate must be positive", ErrInvalidExchangeRate)
+	}
+	if rate.AsOf.IsZero() {
+		return fmt.Errorf("%w: missing date", ErrInvalidExchangeRate)
+	}
+	return nil
+}
+
+type rateEdge struct {
+	rate *big.Rat
+	asOf time.Time
+}
+
+// rateTable answers "how many units of B is one unit of A" from the stored
+// pairs, their inverses, and crosses through one shared currency (ECB
+// publishes everything against EUR).
+type rateTable map[string]map[string]rateEdge
+
+func newRateTable(rates []models.ExchangeRate) (rateTable, error) {
+	t := make(rateTable)
+	set := func(from, to string, edge rateEdge) {
+		if t[from] == nil {
+			t[from] = make(map[string]rateEdge)
+		}
+		t[from][to] = edge
+	}
+
+	parsed := make([]rateEdge, len(rates))
+	for i, rate := range rates {
+		r, ok := new(big.Rat).SetString(rate.Rate)
+		if !ok || r.Sign() <= 0 {
+			return nil, fmt.Errorf("%w: stored %s/%s rate %q", ErrInvalidExchangeRate, rate.Base, rate.Quote, rate.Rate)
+		}
+		parsed[i] = rateEdge{rate: r, asOf: rate.AsOf}
+	}
+	// inverses first so that an explicitly stored pair always wins
+	for i, rate := range rates {
+		set(rate.Quote, rate.Base, rateEdge{rate: new(big.Rat).Inv(parsed[i].rate), asOf: parsed[i].asOf})
+	}
+	for i, rate := range rates {
+		set(rate.Base, rate.Quote, parsed[i])
+	}
+	return t, nil
+}
+
+func (t rateTable) lookup(from, to string) (rateEdge, error) {
+	if direct, ok := t[from][to]; ok {
+		return direct, nil
+	}
+
+	pivots := make([]string, 0, len(t[from]))
+	for pivot := range t[from] {
+		pivots = append(pivots, pivot)
+	}
+	sort.Strings(pivots)
+	for _, pivot := range pivots {
+		second, ok := t[pivot][to]
+		if !ok {
+			continue
+		}
+		first := t[from][pivot]
+		asOf := first.asOf
+		if second.asOf.Before(asOf) {
+			asOf = second.asOf
+		}
+		return rateEdge{rate: new(big.Rat).Mul(first.rate, second.rate), asOf: asOf}, nil
+	}
+	return rateEdge{}, fmt.Errorf("%w: %s to %s", ErrNoExchangeRate, from, to)
+}
+
+// convert returns price in currency, with the rate shown rounded to ten
+// decimal places. Same-currency prices are passed through at rate 1.
+func (t rateTable) convert(price models.Money, currency string) (*models.ConvertedPrice, error) {
+	if price.Currency == currency {
+		return &models.ConvertedPrice{Price: price, Rate: "1"}, nil
+	}
+	edge, err := t.lookup(price.Currency, currency)
+	if err != nil {
+		return nil, err
+	}
+	converted, err := price.Convert(edge.rate, currency)
+	if err != nil {
+		return nil, fmt.Errorf("convert %s: %w", price, err)
+	}
+	asOf := edge.asOf
+	return &models.ConvertedPrice{Price: converted, Rate: formatRate(edge.rate), RateAsOf: &asOf}, nil
+}
+
+func formatRate(r *big.Rat) string {
+	s := r.FloatString(10)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// ConvertProducts fills ConvertedPrice on products and their variants with
+// prices in currency. It fails if any price can't be converted, so callers
+// never return a page where only some items are converted.
+func (s *ExchangeRateService) ConvertProducts(ctx context.Context, currency string, products ...*models.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+	rates, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	table, err := newRateTable(rates)
+	if err != nil {
+		return err
+	}
+
+	for _, product := range products {
+		product.ConvertedPrice, err = table.convert(product.Price, currency)
+		if err != nil {
+			return err
+		}
+		for i := range product.Variants {
+			variant := &product.Variants[i]
+			if variant.Price == nil {
+				continue
+			}
+			variant.ConvertedPrice, err = table.convert(*variant.Price, currency)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"e-commerce/internal/domain/models"
+	"e-commerce/internal/storage"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+var ErrFileTooLarge = errors.New("file is too large")
+var ErrEmptyFile = errors.New("file is empty")
+var ErrInvalidFileName = errors.New("invalid file name")
+
+type fileRepo interface {
+	GetByProduct(ctx context.Context, productID, userID string) ([]models.ProductFile, error)
+	Create(ctx context.Context, userID string, file *models.ProductFile) (*models.ProductFile, error)
+	Delete(ctx context.Context, productID, fileID, userID string) (*models.ProductFile, error)
+}
+
+type FileService struct {
+	repo     fileRepo
+	blobs    storage.BlobStore
+	maxBytes int64
+}
+
+// NewFileService takes the private store for digital files, not the
+// public media one.
+func NewFileService(repo fileRepo, blobs storage.BlobStore, maxBytes int64) *FileService {
+	return &FileService{repo: repo, blobs: blobs, maxBytes: maxBytes}
+}
+
+func (s *FileService) MaxBytes() int64 {
+	return s.maxBytes
+}
+
+func (s *FileService) GetByProduct(ctx context.Context, productID, userID string) ([]models.ProductFile, error) {
+	return s.repo.GetByProduct(ctx, productID, userID)
+}
+
+// cleanFileName keeps the last path element of a client's file name,
+// without control characters, so it is safe to send back in a
+// Content-Disposition header.
+func cleanFileName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name))
+	if name == "." || name == "/" || name == ".." {
+		return ""
+	}
+	if runes := []rune(name); len(runes) > models.MaxFileNameLength {
+		name = string(runes[:models.MaxFileNameLength])
+	}
+	return name
+}
+
+// Upload stores size bytes from r as a file of the digital product. The
+// content type follows the file name's extension, falling back to
+// sniffing the content.
+func (s *FileService) Upload(ctx context.Context, productID, userID, name string, r io.Reader, size int64) (*models.ProductFile, error) {
+	if size > s.maxBytes {
+		return nil, ErrFileTooLarge
+	}
+	if size <= 0 {
+		return nil, ErrEmptyFile
+	}
+	if name = cleanFileName(name); name == "" {
+		return nil, ErrInvalidFileName
+	}
+
+	br := bufio.NewReader(io.LimitReader(r, size))
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		head, _ := br.Peek(512)
+		contentType = http.DetectContentType(head)
+	}
+
+	id := uuid.New()
+	pid, err := uuid.Parse(productID)
+	if err != nil {
+		return nil, fmt.Errorf("product id: %w", err)
+	}
+	key := fmt.Sprintf("products/%s/%s", productID, id)
+
+	if err := s.blobs.Put(ctx, key, br, contentType); err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.Create(ctx, userID, &models.ProductFile{
+		ID:          id,
+		ProductID:   pid,
+		Name:        name,
+		BlobKey:     key,
+		ContentType: contentType,
+		SizeBytes:   size,
+	})
+	if err != nil {
+		deleteBlobs(ctx, s.blobs, key)
+		return nil, err
+	}
+	return created, nil
+}
+
+func (s *FileService) Delete(ctx context.Context, productID, fileID, userID string) error {
+	file, err := s.repo.Delete(ctx, productID, fileID, userID)
+	if err != nil {
+		return err
+	}
+	deleteBlobs(ctx, s.blobs, file.BlobKey)
+	return nil
+}
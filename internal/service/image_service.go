@@ -0,0 +1,151 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"e-commerce/internal/domain/models"
+	"e-commerce/internal/storage"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+var ErrUnsupportedImage = errors.New("unsupported image type")
+var ErrImageTooLarge = errors.New("image is too large")
+
+// maxImagePixels guards against decompression bombs: small files that
+// decode to enormous bitmaps.
+const maxImagePixels = 40_000_000
+
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+type imageRepo interface {
+	GetByProduct(ctx context.Context, productID, userID string) ([]models.ProductImage, error)
+	Create(ctx context.Context, userID string, image *models.ProductImage) (*models.ProductImage, error)
+	Delete(ctx context.Context, productID, imageID, userID string) (*models.ProductImage, error)
+	Reorder(ctx context.Context, productID, userID string, imageIDs []string) ([]models.ProductImage, error)
+}
+
+type ImageService struct {
+	repo     imageRepo
+	blobs    storage.BlobStore
+	maxBytes int64
+}
+
+func NewImageService(repo imageRepo, blobs storage.BlobStore, maxBytes int64) *ImageService {
+	return &ImageService{repo: repo, blobs: blobs, maxBytes: maxBytes}
+}
+
+func (s *ImageService) MaxBytes() int64 {
+	return s.maxBytes
+}
+
+func (s *ImageService) GetByProduct(ctx context.Context, productID, userID string) ([]models.ProductImage, error) {
+	return s.repo.GetByProduct(ctx, productID, userID)
+}
+
+// Upload validates data as a JPEG, PNG or GIF image, stores the original
+// and a thumbnail, and appends the image to the product's gallery.
+func (s *ImageService) Upload(ctx context.Context, productID, userID string, data []byte) (*models.ProductImage, error) {
+	if int64(len(data)) > s.maxBytes {
+		return nil, ErrImageTooLarge
+	}
+	contentType := http.DetectContentType(data)
+	ext, ok := imageExtensions[contentType]
+	if !ok {
+		return nil, ErrUnsupportedImage
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxImagePixels {
+		return nil, ErrImageTooLarge
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+
+	// JPEG thumbnails for photos, PNG where transparency may matter
+	var thumb bytes.Buffer
+	thumbType, thumbExt := "image/jpeg", ".jpg"
+	if contentType == "image/jpeg" {
+		err = jpeg.Encode(&thumb, thumbnail(src, thumbnailSize), &jpeg.Options{Quality: 85})
+	} else {
+		thumbType, thumbExt = "image/png", ".png"
+		err = png.Encode(&thumb, thumbnail(src, thumbnailSize))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("encode thumbnail: %w", err)
+	}
+
+	id := uuid.New()
+	pid, err := uuid.Parse(productID)
+	if err != nil {
+		return nil, fmt.Errorf("product id: %w", err)
+	}
+	originalKey := fmt.Sprintf("products/%s/%s%s", productID, id, ext)
+	thumbnailKey := fmt.Sprintf("products/%s/%s_thumb%s", productID, id, thumbExt)
+
+	if err := s.blobs.Put(ctx, originalKey, bytes.NewReader(data), contentType); err != nil {
+		return nil, err
+	}
+	if err := s.blobs.Put(ctx, thumbnailKey, &thumb, thumbType); err != nil {
+		deleteBlobs(ctx, s.blobs, originalKey)
+		return nil, err
+	}
+
+	created, err := s.repo.Create(ctx, userID, &models.ProductImage{
+		ID:           id,
+		ProductID:    pid,
+		OriginalKey:  originalKey,
+		ThumbnailKey: thumbnailKey,
+		URL:          s.blobs.URL(originalKey),
+		ThumbnailURL: s.blobs.URL(thumbnailKey),
+		ContentType:  contentType,
+		SizeBytes:    int64(len(data)),
+		Width:        cfg.Width,
+		Height:       cfg.Height,
+	})
+	if err != nil {
+		deleteBlobs(ctx, s.blobs, originalKey, thumbnailKey)
+		return nil, err
+	}
+	return created, nil
+}
+
+func (s *ImageService) Delete(ctx context.Context, productID, imageID, userID string) error {
+	image, err := s.repo.Delete(ctx, productID, imageID, userID)
+	if err != nil {
+		return err
+	}
+	deleteBlobs(ctx, s.blobs, image.OriginalKey, image.ThumbnailKey)
+	return nil
+}
+
+func (s *ImageService) Reorder(ctx context.Context, productID, userID string, imageIDs []string) ([]models.ProductImage, error) {
+	return s.repo.Reorder(ctx, productID, userID, imageIDs)
+}
+
+// deleteBlobs is best effort: the rows are already gone, so a failure only
+// leaves an orphaned file behind.
+func deleteBlobs(ctx context.Context, blobs storage.BlobStore, keys ...string) {
+	for _, key := range keys {
+		if err := blobs.Delete(ctx, key); err != nil {
+			log.Printf("[ERROR] delete blob %s: %v", key, err)
+		}
+	}
+}
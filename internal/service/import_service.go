@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"e-commerce/internal/domain/models"
+	"e-commerce/internal/utils/xlsx"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidImportFile = errors.New("invalid import file")
+var ErrTooManyImportRows = errors.New("import file has too many rows")
+
+// MaxImportRows caps a single import; larger catalogues are split.
+const MaxImportRows = 50_000
+
+// importStaleAfter is how long a running import may go without finishing
+// before another worker takes it over.
+const importStaleAfter = 15 * time.Minute
+
+var importColumns = []string{"id", "name", "price", "currency"}
+
+// exportOnlyColumns appear in product exports and are skipped on import,
+// so an exported file can be edited and loaded back as is.
+var exportOnlyColumns = map[string]bool{"created_at": true, "updated_at": true, "version": true}
+
+// ImportRowValidator checks a parsed row against the same rules as a
+// product created through the API.
+type ImportRowValidator func(name string, price models.Money) []models.ImportRowError
+
+type importRepo interface {
+	CreateJob(ctx context.Context, userID, format string, dryRun bool, total int, rows []models.ProductImportRow, rejected []models.ImportRowResult) (*models.ImportJob, error)
+	GetJob(ctx context.Context, id, userID string) (*models.ImportJob, error)
+	ClaimJob(ctx context.Context, staleBefore time.Time) (*models.ImportJob, []models.ProductImportRow, error)
+	ApplyRows(ctx context.Context, userID string, rows []models.ProductImportRow, dryRun bool) ([]models.ImportRowResult, error)
+	FinishJob(ctx context.Context, id, status string, results []models.ImportRowResult, errMsg string) error
+}
+
+type ImportService struct {
+	repo     importRepo
+	maxBytes int64
+}
+
+func NewImportService(repo importRepo, maxBytes int64) *ImportService {
+	return &ImportService{repo: repo, maxBytes: maxBytes}
+}
+
+func (s *ImportService) MaxBytes() int64 {
+	return s.maxBytes
+}
+
+type rowReader interface {
+	Read() ([]string, error)
+}
+
+// Start reads a csv or xlsx file, validates every row and queues the valid
+// ones for the import worker. Rows that fail validation are reported in
+// the job straight away; a file that can't be read at all is rejected.
+func (s *ImportService) Start(ctx context.Context, userID, format string, file io.ReaderAt, size int64, dryRun bool, validate ImportRowValidator) (*models.ImportJob, error) {
+	var reader rowReader
+	switch format {
+	case "csv":
+		r := csv.NewReader(io.NewSectionReader(file, 0, size))
+		r.FieldsPerRecord = -1
+		r.TrimLeadingSpace = true
+		reader = r
+	case "xlsx":
+		r, err := xlsx.NewReader(file, size)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+		}
+		defer r.Close()
+		reader = r
+	default:
+		return nil, fmt.Errorf("%w: unsupported format %q", ErrInvalidImportFile, format)
+	}
+
+	rows, rejected, err := readImportRows(reader, validate)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.CreateJob(ctx, userID, format, dryRun, len(rows)+len(rejected), rows, rejected)
+}
+
+func readImportRows(reader rowReader, validate ImportRowValidator) ([]models.ProductImportRow, []models.ImportRowResult, error) {
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, fmt.Errorf("%w: file is empty", ErrInvalidImportFile)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+	}
+	columns, err := importHeader(header)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rows []models.ProductImportRow
+	var rejected []models.ImportRowResult
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: line %d: %v", ErrInvalidImportFile, line, err)
+		}
+		if blankRecord(record) {
+			continue
+		}
+		if len(rows)+len(rejected) == MaxImportRows {
+			return nil, nil, ErrTooManyImportRows
+		}
+
+		row, errs := parseImportRow(line, record, columns, validate)
+		if len(errs) > 0 {
+			rejected = append(rejected, models.ImportRowResult{Line: line, Status: models.ImportRowFailed, Errors: errs})
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows, rejected, nil
+}
+
+// importHeader maps column names to their position. name, price and
+// currency are required; id is optional and turns a row into an update.
+func importHeader(header []string) (map[string]int, error) {
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if name == "" || exportOnlyColumns[name] {
+			continue
+		}
+		known := false
+		for _, c := range importColumns {
+			known = known || c == name
+		}
+		if !known {
+			return nil, fmt.Errorf("%w: unknown column %q, expected %s", ErrInvalidImportFile, name, strings.Join(importColumns, ", "))
+		}
+		if _, dup := columns[name]; dup {
+			return nil, fmt.Errorf("%w: duplicate column %q", ErrInvalidImportFile, name)
+		}
+		columns[name] = i
+	}
+	for _, required := range importColumns[1:] {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: header is missing the %q column", ErrInvalidImportFile, required)
+		}
+	}
+	return columns, nil
+}
+
+func parseImportRow(line int, record []string, columns map[string]int, validate ImportRowValidator) (models.ProductImportRow, []models.ImportRowError) {
+	cell := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	row := models.ProductImportRow{Line: line, Name: cell("name")}
+	var errs []models.ImportRowError
+
+	if id := cell("id"); id != "" {
+		parsed, err := uuid.Parse(id)
+		if err != nil {
+			errs = append(errs, models.ImportRowError{Field: "id", Message: "invalid UUID format"})
+		} else {
+			row.ID = &parsed
+		}
+	}
+
+	price, err := models.ParseMoney(cell("price"), cell("currency"))
+	if err != nil {
+		errs = append(errs, models.ImportRowError{Field: "price", Message: err.Error()})
+	}
+	row.Price = price
+
+	for _, e := range validate(row.Name, row.Price) {
+		// a price that didn't parse has already been reported
+		if e.Field == "price" && err != nil {
+			continue
+		}
+		errs = append(errs, e)
+	}
+	return row, errs
+}
+
+func blankRecord(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *ImportService) Get(ctx context.Context, id, userID string) (*models.ImportJob, error) {
+	return s.repo.GetJob(ctx, id, userID)
+}
+
+// RunPending applies queued imports one after another until the queue is
+// empty and returns how many it finished. A job whose rows can't be
+// written is marked failed with nothing kept.
+func (s *ImportService) RunPending(ctx context.Context) (int, error) {
+	done := 0
+	for ctx.Err() == nil {
+		job, rows, err := s.repo.ClaimJob(ctx, time.Now().Add(-importStaleAfter))
+		if err != nil {
+			return done, err
+		}
+		if job == nil {
+			return done, nil
+		}
+
+		results, err := s.repo.ApplyRows(ctx, job.UserID.String(), rows, job.DryRun)
+		if err != nil {
+			if ctx.Err() != nil {
+				// shutting down: the job is picked up again once it goes stale
+				return done, ctx.Err()
+			}
+			log.Printf("[ERROR] import %s: %v", job.ID, err)
+			if err := s.repo.FinishJob(ctx, job.ID.String(), models.ImportFailed, job.Rows, "import failed; no rows were saved"); err != nil {
+				return done, err
+			}
+			continue
+		}
+
+		results = append(results, job.Rows...)
+		sort.Slice(results, func(i, j int) bool { return results[i].Line < results[j].Line })
+		if err := s.repo.FinishJob(ctx, job.ID.String(), models.ImportDone, results, ""); err != nil {
+			return done, err
+		}
+		done++
+	}
+	return done, ctx.Err()
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"e-commerce/internal/domain/models"
+	"errors"
+	"time"
+)
+
+var ErrInvalidMovement = errors.New("quantity sign does not match movement reason")
+
+const expiredReservationBatch = 500
+
+type inventoryRepo interface {
+	GetStock(ctx context.Context, productID, userID string) ([]models.StockLevel, error)
+	RecordMovement(ctx context.Context, productID string, variantID *string, userID string, quantity int, reason, note string) (*models.StockLevel, error)
+	ListMovements(ctx context.Context, productID, userID string, limit int, cursor string) (*models.StockMovementPage, error)
+	Reserve(ctx context.Context, productID string, variantID *string, buyerID string, quantity int, ttl time.Duration) (*models.Reservation, error)
+	CommitReservation(ctx context.Context, reservationID, buyerID string) (*models.Reservation, error)
+	ReleaseReservation(ctx context.Context, reservationID, buyerID string) (*models.Reservation, error)
+	ReleaseExpired(ctx context.Context, batch int) (int, error)
+}
+
+type InventoryService struct {
+	repo           inventoryRepo
+	reservationTTL time.Duration
+}
+
+func NewInventoryService(repo inventoryRepo, reservationTTL time.Duration) *InventoryService {
+	return &InventoryService{repo: repo, reservationTTL: reservationTTL}
+}
+
+func (s *InventoryService) GetStock(ctx context.Context, productID, userID string) ([]models.StockLevel, error) {
+	return s.repo.GetStock(ctx, productID, userID)
+}
+
+// RecordMovement checks that the quantity sign fits the reason: receipts
+// and returns add stock, sales remove it, adjustments may go either way.
+func (s *InventoryService) RecordMovement(ctx context.Context, productID string, variantID *string, userID string, quantity int, reason, note string) (*models.StockLevel, error) {
+	switch reason {
+	case models.MovementReceipt, models.MovementReturn:
+		if quantity <= 0 {
+			return nil, ErrInvalidMovement
+		}
+	case models.MovementSale:
+		if quantity >= 0 {
+			return nil, ErrInvalidMovement
+		}
+	case models.MovementAdjustment:
+		if quantity == 0 {
+			return nil, ErrInvalidMovement
+		}
+	default:
+		return nil, ErrInvalidMovement
+	}
+	return s.repo.RecordMovement(ctx, productID, variantID, userID, quantity, reason, note)
+}
+
+func (s *InventoryService) ListMovements(ctx context.Context, productID, userID string, limit int, cursor string) (*models.StockMovementPage, error) {
+	return s.repo.ListMovements(ctx, productID, userID, limit, cursor)
+}
+
+func (s *InventoryService) Reserve(ctx context.Context, productID string, variantID *string, buyerID string, quantity int) (*models.Reservation, error) {
+	return s.repo.Reserve(ctx, productID, variantID, buyerID, quantity, s.reservationTTL)
+}
+
+func (s *InventoryService) CommitReservation(ctx context.Context, reservationID, buyerID string) (*models.Reservation, error) {
+	return s.repo.CommitReservation(ctx, reservationID, buyerID)
+}
+
+func (s *InventoryService) ReleaseReservation(ctx context.Context, reservationID, buyerID string) (*models.Reservation, error) {
+	return s.repo.ReleaseReservation(ctx, reservationID, buyerID)
+}
+
+// ReleaseExpiredReservations drains all overdue reservations in batches.
+func (s *InventoryService) ReleaseExpiredReservations(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		n, err := s.repo.ReleaseExpired(ctx, expiredReservationBatch)
+		total += n
+		if err != nil || n < expiredReservationBatch {
+			return total, err
+		}
+	}
+}
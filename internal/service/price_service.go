@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"e-commerce/internal/domain/models"
+	"time"
+)
+
+// priceScheduleBatchSize bounds how many scheduled prices one scheduler
+// transaction starts or ends.
+const priceScheduleBatchSize = 100
+
+type priceRepo interface {
+	Timeline(ctx context.Context, productID, userID string) (*models.PriceTimeline, error)
+	Schedule(ctx context.Context, productID, userID string, price models.Money, effectiveFrom time.Time, effectiveTo *time.Time) (*models.ScheduledPrice, error)
+	Cancel(ctx context.Context, productID, scheduleID, userID string) error
+	StartDue(ctx context.Context, now time.Time, limit int) (int, error)
+	EndDue(ctx context.Context, now time.Time, limit int) (int, error)
+}
+
+type PriceService struct {
+	repo priceRepo
+}
+
+func NewPriceService(repo priceRepo) *PriceService {
+	return &PriceService{repo: repo}
+}
+
+func (s *PriceService) Timeline(ctx context.Context, productID, userID string) (*models.PriceTimeline, error) {
+	return s.repo.Timeline(ctx, productID, userID)
+}
+
+func (s *PriceService) Schedule(ctx context.Context, productID, userID string, price models.Money, effectiveFrom time.Time, effectiveTo *time.Time) (*models.ScheduledPrice, error) {
+	return s.repo.Schedule(ctx, productID, userID, price, effectiveFrom, effectiveTo)
+}
+
+func (s *PriceService) Cancel(ctx context.Context, productID, scheduleID, userID string) error {
+	return s.repo.Cancel(ctx, productID, scheduleID, userID)
+}
+
+// RunSchedule first ends the scheduled prices that have run out, then
+// starts those that are due, so that back-to-back schedules hand over
+// cleanly: the later one remembers the regular price, not the earlier
+// one's.
+func (s *PriceService) RunSchedule(ctx context.Context) (int, error) {
+	now := time.Now()
+	total := 0
+	for _, apply := range []func(context.Context, time.Time, int) (int, error){s.repo.EndDue, s.repo.StartDue} {
+		for {
+			n, err := apply(ctx, now, priceScheduleBatchSize)
+			if err != nil {
+				return total, err
+			}
+			total += n
+			if n < priceScheduleBatchSize {
+				break
+			}
+		}
+	}
+	return total, nil
+}
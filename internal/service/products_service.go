@@ -3,45 +3,190 @@ package service
 import (
 	"context"
 	"e-commerce/internal/domain/models"
+	"e-commerce/internal/storage"
+	"errors"
+	"time"
 )
 
+// purgeBatchSize bounds how many products one purge statement removes.
+const purgeBatchSize = 100
+
+// scheduleBatchSize bounds how many products one scheduler statement
+// publishes or unpublishes.
+const scheduleBatchSize = 100
+
+var ErrInvalidStatus = errors.New("unknown product status")
+
 type productRepo interface {
-    Create(ctx context.Context, name string, price float64, userID string) (*models.Product, error)
-    GetByID(ctx context.Context, id, userID string) (*models.Product, error)
-    GetAll(ctx context.Context, userID string) ([]models.Product, error)
-    Update(ctx context.Context, id, userID, name string, price float64) (*models.Product, error)
-    Patch(ctx context.Context, id, userID string, updates map[string]any) (*models.Product, error)
-    Delete(ctx context.Context, id, userID string) error
+	Create(ctx context.Context, name string, price models.Money, attrs map[string]any, tags []string, userID string) (*models.Product, error)
+	GetByID(ctx context.Context, id, userID string) (*models.Product, error)
+	GetAll(ctx context.Context, userID string, params models.ProductListParams) (*models.ProductPage, error)
+	Update(ctx context.Context, id, userID, name string, price models.Money, attrs map[string]any, tags []string, ifMatch []int) (*models.Product, error)
+	Patch(ctx context.Context, id, userID string, updates map[string]any, ifMatch []int) (*models.Product, error)
+	Delete(ctx context.Context, id, userID string, ifMatch []int) error
+	Restore(ctx context.Context, id, userID string) (*models.Product, error)
+	Purge(ctx context.Context, cutoff time.Time, limit int) (int, []string, []string, error)
+	Search(ctx context.Context, userID string, params models.ProductSearchParams) (*models.ProductSearchPage, error)
+	Export(ctx context.Context, userID string, params models.ProductListParams, fn func(*models.Product) error) error
+	Batch(ctx context.Context, userID string, ops []models.ProductOperation, continueOnError bool) ([]models.ProductOperationResult, bool, error)
+	SetStatus(ctx context.Context, id, userID string, from []string, status string, ifMatch []int) (*models.Product, error)
+	SetSchedule(ctx context.Context, id, userID string, publishAt, unpublishAt *time.Time, from []string, ifMatch []int) (*models.Product, error)
+	PublishDue(ctx context.Context, now time.Time, limit int) (int, error)
+	UnpublishDue(ctx context.Context, now time.Time, limit int) (int, error)
+	GetBySlug(ctx context.Context, slug, userID string) (*models.Product, error)
+	SetSlug(ctx context.Context, id, userID, slug string, ifMatch []int) (*models.Product, error)
+	SetType(ctx context.Context, id, userID, productType string, ifMatch []int) (*models.Product, error)
 }
 
 type ProductService struct {
-	repo productRepo
+	repo           productRepo
+	blobs          storage.BlobStore
+	files          storage.BlobStore
+	trashRetention time.Duration
+}
+
+func NewProductService(repo productRepo, blobs, files storage.BlobStore, trashRetention time.Duration) *ProductService {
+	return &ProductService{repo: repo, blobs: blobs, files: files, trashRetention: trashRetention}
+}
+
+func (s *ProductService) Create(ctx context.Context, name string, price models.Money, attrs map[string]any, tags []string, userID string) (*models.Product, error) {
+	return s.repo.Create(ctx, name, price, attrs, models.NormalizeTags(tags), userID)
+}
+
+// Delete, Update and Patch take the versions from If-Match; nil means the
+// write is unconditional.
+func (s *ProductService) Delete(ctx context.Context, productID string, userID string, ifMatch []int) error {
+	return s.repo.Delete(ctx, productID, userID, ifMatch)
+}
+
+// Trash lists the seller's deleted products, most recently deleted first.
+func (s *ProductService) Trash(ctx context.Context, userID string, limit int, cursor string) (*models.ProductPage, error) {
+	return s.repo.GetAll(ctx, userID, models.ProductListParams{
+		Limit:   limit,
+		Cursor:  cursor,
+		SortBy:  "deleted_at",
+		Order:   "desc",
+		Trashed: true,
+	})
+}
+
+func (s *ProductService) Restore(ctx context.Context, productID string, userID string) (*models.Product, error) {
+	return s.repo.Restore(ctx, productID, userID)
+}
+
+// PurgeTrash permanently deletes products that have been in the trash
+// longer than the retention period, together with their images and
+// digital files.
+func (s *ProductService) PurgeTrash(ctx context.Context) (int, error) {
+	cutoff := time.Now().Add(-s.trashRetention)
+	total := 0
+	for {
+		purged, imageKeys, fileKeys, err := s.repo.Purge(ctx, cutoff, purgeBatchSize)
+		if err != nil {
+			return total, err
+		}
+		deleteBlobs(ctx, s.blobs, imageKeys...)
+		deleteBlobs(ctx, s.files, fileKeys...)
+		total += purged
+		if purged < purgeBatchSize {
+			return total, nil
+		}
+	}
 }
 
-func NewProductService(repo productRepo) *ProductService {
-	return &ProductService{repo: repo}
+// SetStatus moves the product to status, provided the transition from its
+// current status is allowed.
+func (s *ProductService) SetStatus(ctx context.Context, productID, userID, status string, ifMatch []int) (*models.Product, error) {
+	if !models.ValidProductStatus(status) {
+		return nil, ErrInvalidStatus
+	}
+	return s.repo.SetStatus(ctx, productID, userID, models.StatusSources(status), status, ifMatch)
 }
 
-func (s *ProductService) Create(ctx context.Context, name string, price float64, userID string) (*models.Product, error) {
-	return s.repo.Create(ctx, name, price, userID)
+// SetSchedule replaces the product's publishing schedule. Only a draft can
+// be scheduled to go live, and only a product that isn't archived can be
+// scheduled to come down.
+func (s *ProductService) SetSchedule(ctx context.Context, productID, userID string, publishAt, unpublishAt *time.Time, ifMatch []int) (*models.Product, error) {
+	from := []string{models.ProductDraft, models.ProductPublished, models.ProductArchived}
+	switch {
+	case publishAt != nil:
+		from = []string{models.ProductDraft}
+	case unpublishAt != nil:
+		from = []string{models.ProductDraft, models.ProductPublished}
+	}
+	return s.repo.SetSchedule(ctx, productID, userID, publishAt, unpublishAt, from, ifMatch)
 }
 
-func (s *ProductService) Delete(ctx context.Context, productID string, userID string) error {
-	return s.repo.Delete(ctx, productID, userID)
+// RunSchedule publishes drafts whose publish_at has come, then takes down
+// published products whose unpublish_at has.
+func (s *ProductService) RunSchedule(ctx context.Context) (int, error) {
+	now := time.Now()
+	total := 0
+	for _, apply := range []func(context.Context, time.Time, int) (int, error){s.repo.PublishDue, s.repo.UnpublishDue} {
+		for {
+			n, err := apply(ctx, now, scheduleBatchSize)
+			if err != nil {
+				return total, err
+			}
+			total += n
+			if n < scheduleBatchSize {
+				break
+			}
+		}
+	}
+	return total, nil
 }
 
-func (s *ProductService) Update(ctx context.Context, productID string, userID string, name string, price float64) (*models.Product, error) {
-	return s.repo.Update(ctx, productID, userID, name, price)
+// Update keeps the product's attributes and tags when attrs or tags is nil.
+func (s *ProductService) Update(ctx context.Context, productID string, userID string, name string, price models.Money, attrs map[string]any, tags []string, ifMatch []int) (*models.Product, error) {
+	return s.repo.Update(ctx, productID, userID, name, price, attrs, models.NormalizeTags(tags), ifMatch)
 }
 
-func (s *ProductService) Patch(ctx context.Context, productID string, userID string, updates map[string]any) (*models.Product, error) {
-	return s.repo.Patch(ctx, productID, userID, updates)
+func (s *ProductService) Patch(ctx context.Context, productID string, userID string, updates map[string]any, ifMatch []int) (*models.Product, error) {
+	normalizeTagUpdate(updates)
+	return s.repo.Patch(ctx, productID, userID, updates, ifMatch)
 }
 
-func (s *ProductService) GetAll(ctx context.Context, userID string) ([]models.Product, error) {
-	return s.repo.GetAll(ctx, userID)
+func (s *ProductService) GetAll(ctx context.Context, userID string, params models.ProductListParams) (*models.ProductPage, error) {
+	return s.repo.GetAll(ctx, userID, params)
+}
+
+func (s *ProductService) Batch(ctx context.Context, userID string, ops []models.ProductOperation, continueOnError bool) ([]models.ProductOperationResult, bool, error) {
+	for i := range ops {
+		ops[i].Tags = models.NormalizeTags(ops[i].Tags)
+		normalizeTagUpdate(ops[i].Updates)
+	}
+	return s.repo.Batch(ctx, userID, ops, continueOnError)
+}
+
+func normalizeTagUpdate(updates map[string]any) {
+	if tags, ok := updates["tags"].([]string); ok {
+		updates["tags"] = models.NormalizeTags(tags)
+	}
+}
+
+func (s *ProductService) Export(ctx context.Context, userID string, params models.ProductListParams, fn func(*models.Product) error) error {
+	return s.repo.Export(ctx, userID, params, fn)
 }
 
 func (s *ProductService) GetByID(ctx context.Context, id string, userID string) (*models.Product, error) {
 	return s.repo.GetByID(ctx, id, userID)
 }
+
+// GetBySlug also finds products by a slug they had before; the returned
+// product's Slug is the current one.
+func (s *ProductService) GetBySlug(ctx context.Context, slug, userID string) (*models.Product, error) {
+	return s.repo.GetBySlug(ctx, slug, userID)
+}
+
+func (s *ProductService) SetSlug(ctx context.Context, productID, userID, slug string, ifMatch []int) (*models.Product, error) {
+	return s.repo.SetSlug(ctx, productID, userID, slug, ifMatch)
+}
+
+func (s *ProductService) SetType(ctx context.Context, productID, userID, productType string, ifMatch []int) (*models.Product, error) {
+	return s.repo.SetType(ctx, productID, userID, productType, ifMatch)
+}
+
+func (s *ProductService) Search(ctx context.Context, userID string, params models.ProductSearchParams) (*models.ProductSearchPage, error) {
+	return s.repo.Search(ctx, userID, params)
+}
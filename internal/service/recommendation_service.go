@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"e-commerce/internal/domain/models"
+)
+
+type recommendationRepo interface {
+	Related(ctx context.Context, productID string, limit int) ([]models.RelatedProduct, error)
+	RebuildAffinity(ctx context.Context) (int, error)
+}
+
+type RecommendationService struct {
+	repo recommendationRepo
+}
+
+func NewRecommendationService(repo recommendationRepo) *RecommendationService {
+	return &RecommendationService{repo: repo}
+}
+
+func (s *RecommendationService) Related(ctx context.Context, productID string, limit int) ([]models.RelatedProduct, error) {
+	if limit <= 0 {
+		limit = models.DefaultRelatedLimit
+	}
+	if limit > models.MaxRelatedLimit {
+		limit = models.MaxRelatedLimit
+	}
+	return s.repo.Related(ctx, productID, limit)
+}
+
+// RebuildAffinity is the periodic job behind the bought-together
+// recommendations.
+func (s *RecommendationService) RebuildAffinity(ctx context.Context) (int, error) {
+	return s.repo.RebuildAffinity(ctx)
+}
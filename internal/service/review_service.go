@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"e-commerce/internal/domain/models"
+)
+
+type reviewRepo interface {
+	ListPublished(ctx context.Context, productID string, params models.ReviewListParams) (*models.ReviewPage, error)
+	ListByProduct(ctx context.Context, productID, sellerID string, params models.ReviewListParams) (*models.ReviewPage, error)
+	ListByUser(ctx context.Context, userID string, params models.ReviewListParams) (*models.ReviewPage, error)
+	Create(ctx context.Context, productID, userID string, rating int, title, body string) (*models.Review, error)
+	Update(ctx context.Context, reviewID, userID string, rating int, title, body string) (*models.Review, error)
+	Delete(ctx context.Context, reviewID, userID string) error
+	SetStatus(ctx context.Context, reviewID, sellerID, status string) (*models.Review, error)
+	SetReply(ctx context.Context, reviewID, sellerID string, reply *string) (*models.Review, error)
+	Vote(ctx context.Context, reviewID, userID string, helpful bool) (*models.Review, error)
+}
+
+type ReviewService struct {
+	repo reviewRepo
+}
+
+func NewReviewService(repo reviewRepo) *ReviewService {
+	return &ReviewService{repo: repo}
+}
+
+// ListPublished lists the approved reviews shoppers see on a product page.
+func (s *ReviewService) ListPublished(ctx context.Context, productID string, params models.ReviewListParams) (*models.ReviewPage, error) {
+	return s.repo.ListPublished(ctx, productID, params)
+}
+
+// ListByProduct lists the reviews of the seller's product for moderation.
+func (s *ReviewService) ListByProduct(ctx context.Context, productID, sellerID string, params models.ReviewListParams) (*models.ReviewPage, error) {
+	return s.repo.ListByProduct(ctx, productID, sellerID, params)
+}
+
+func (s *ReviewService) ListByUser(ctx context.Context, userID string, params models.ReviewListParams) (*models.ReviewPage, error) {
+	return s.repo.ListByUser(ctx, userID, params)
+}
+
+func (s *ReviewService) Create(ctx context.Context, productID, userID string, rating int, title, body string) (*models.Review, error) {
+	return s.repo.Create(ctx, productID, userID, rating, title, body)
+}
+
+func (s *ReviewService) Update(ctx context.Context, reviewID, userID string, rating int, title, body string) (*models.Review, error) {
+	return s.repo.Update(ctx, reviewID, userID, rating, title, body)
+}
+
+func (s *ReviewService) Delete(ctx context.Context, reviewID, userID string) error {
+	return s.repo.Delete(ctx, reviewID, userID)
+}
+
+func (s *ReviewService) Moderate(ctx context.Context, reviewID, sellerID, status string) (*models.Review, error) {
+	return s.repo.SetStatus(ctx, reviewID, sellerID, status)
+}
+
+func (s *ReviewService) Reply(ctx context.Context, reviewID, sellerID string, reply *string) (*models.Review, error) {
+	return s.repo.SetReply(ctx, reviewID, sellerID, reply)
+}
+
+func (s *ReviewService) Vote(ctx context.Context, reviewID, userID string, helpful bool) (*models.Review, error) {
+	return s.repo.Vote(ctx, reviewID, userID, helpful)
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"e-commerce/internal/domain/models"
+	"reflect"
+	"sort"
+)
+
+type revisionRepo interface {
+	List(ctx context.Context, productID, userID string, limit int, cursor string) (*models.ProductRevisionPage, error)
+	Revert(ctx context.Context, productID, userID string, revision int) (*models.Product, error)
+}
+
+type RevisionService struct {
+	repo revisionRepo
+}
+
+func NewRevisionService(repo revisionRepo) *RevisionService {
+	return &RevisionService{repo: repo}
+}
+
+// History lists the product's revisions, newest first, each with the
+// fields it changed.
+func (s *RevisionService) History(ctx context.Context, productID, userID string, limit int, cursor string) (*models.ProductRevisionPage, error) {
+	page, err := s.repo.List(ctx, productID, userID, limit, cursor)
+	if err != nil {
+		return nil, err
+	}
+	for i := range page.Items {
+		rev := &page.Items[i]
+		rev.Changes = diffFields(rev.Before, rev.After)
+	}
+	return page, nil
+}
+
+func (s *RevisionService) Revert(ctx context.Context, productID, userID string, revision int) (*models.Product, error) {
+	return s.repo.Revert(ctx, productID, userID, revision)
+}
+
+// diffFields returns the fields whose values differ, sorted by name. A
+// field missing on one side shows up as null there.
+func diffFields(before, after map[string]any) []models.FieldChange {
+	fields := make(map[string]bool, len(after))
+	for field := range before {
+		fields[field] = true
+	}
+	for field := range after {
+		fields[field] = true
+	}
+	names := make([]string, 0, len(fields))
+	for field := range fields {
+		names = append(names, field)
+	}
+	sort.Strings(names)
+
+	changes := []models.FieldChange{}
+	for _, field := range names {
+		if !reflect.DeepEqual(before[field], after[field]) {
+			changes = append(changes, models.FieldChange{Field: field, Before: before[field], After: after[field]})
+		}
+	}
+	return changes
+}
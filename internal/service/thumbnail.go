@@ -0,0 +1,45 @@
+package service
+
+import (
+	"image"
+	"image/color"
+)
+
+const thumbnailSize = 320
+
+// thumbnail scales src down to fit in a size×size box, averaging every
+// source pixel that falls into a destination pixel. Images that already
+// fit are copied unchanged.
+func thumbnail(src image.Image, size int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	tw, th := w, h
+	if w > size || h > size {
+		if w >= h {
+			tw, th = size, max(1, h*size/w)
+		} else {
+			tw, th = max(1, w*size/h), size
+		}
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, tw, th))
+	for ty := 0; ty < th; ty++ {
+		y0, y1 := b.Min.Y+ty*h/th, b.Min.Y+(ty+1)*h/th
+		for tx := 0; tx < tw; tx++ {
+			x0, x1 := b.Min.X+tx*w/tw, b.Min.X+(tx+1)*w/tw
+
+			var r, g, bl, a, n uint64
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					pr, pg, pb, pa := src.At(x, y).RGBA()
+					r, g, bl, a = r+uint64(pr), g+uint64(pg), bl+uint64(pb), a+uint64(pa)
+					n++
+				}
+			}
+			// average in premultiplied space, then un-premultiply for NRGBA
+			c := color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(bl / n), A: uint16(a / n)}
+			dst.Set(tx, ty, color.NRGBAModel.Convert(c))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"e-commerce/internal/domain/models"
+	"errors"
+	"strings"
+)
+
+var ErrTooManyVariants = errors.New("option combinations exceed the variant limit")
+var ErrDuplicateOption = errors.New("option names must be unique")
+
+type variantRepo interface {
+	GetByProduct(ctx context.Context, productID, userID string) (*models.ProductVariants, error)
+	SetOptions(ctx context.Context, productID, userID string, options []models.ProductOption, combos []map[string]string) (*models.ProductVariants, error)
+	UpdateVariant(ctx context.Context, productID, variantID, userID, sku string, price *models.Money) (*models.ProductVariant, error)
+	DeleteVariant(ctx context.Context, productID, variantID, userID string) error
+}
+
+type VariantService struct {
+	repo variantRepo
+}
+
+func NewVariantService(repo variantRepo) *VariantService {
+	return &VariantService{repo: repo}
+}
+
+func (s *VariantService) GetByProduct(ctx context.Context, productID, userID string) (*models.ProductVariants, error) {
+	return s.repo.GetByProduct(ctx, productID, userID)
+}
+
+// SetOptions replaces the product's options and regenerates one variant
+// per combination of option values.
+func (s *VariantService) SetOptions(ctx context.Context, productID, userID string, options []models.ProductOption) (*models.ProductVariants, error) {
+	seen := make(map[string]bool, len(options))
+	total := 1
+	for _, option := range options {
+		key := strings.ToLower(option.Name)
+		if seen[key] {
+			return nil, ErrDuplicateOption
+		}
+		seen[key] = true
+		total *= len(option.Values)
+		if total > models.MaxProductVariants {
+			return nil, ErrTooManyVariants
+		}
+	}
+
+	return s.repo.SetOptions(ctx, productID, userID, options, optionCombos(options))
+}
+
+// optionCombos returns the cartesian product of option values, varying the
+// last option fastest.
+func optionCombos(options []models.ProductOption) []map[string]string {
+	if len(options) == 0 {
+		return nil
+	}
+	combos := []map[string]string{{}}
+	for _, option := range options {
+		next := make([]map[string]string, 0, len(combos)*len(option.Values))
+		for _, combo := range combos {
+			for _, value := range option.Values {
+				c := make(map[string]string, len(combo)+1)
+				for k, v := range combo {
+					c[k] = v
+				}
+				c[option.Name] = value
+				next = append(next, c)
+			}
+		}
+		combos = next
+	}
+	return combos
+}
+
+func (s *VariantService) UpdateVariant(ctx context.Context, productID, variantID, userID, sku string, price *models.Money) (*models.ProductVariant, error) {
+	return s.repo.UpdateVariant(ctx, productID, variantID, userID, sku, price)
+}
+
+func (s *VariantService) DeleteVariant(ctx context.Context, productID, variantID, userID string) error {
+	return s.repo.DeleteVariant(ctx, productID, variantID, userID)
+}
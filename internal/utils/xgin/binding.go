@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"reflect"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -28,14 +30,43 @@ var validationMessages = map[string]string{
 	"email":                "invalid email address",
 	"gt":                   "must be greater than 0",
 	"required_without_all": "at least one field is required",
+	"uuid":                 "invalid UUID format",
+	"unique":               "must not contain duplicates",
+	"money":                "must be an object with a positive decimal amount and a supported currency, with no more decimal places than the currency allows",
+	"currency":             "unsupported currency code",
+	"required_with":        "field is required",
+	"numeric":              "must be a number",
+	"attribute_key":        "must start with a lowercase letter and contain only lowercase letters, digits and underscores",
+	"attributes":           "must be an object of at most 50 attributes with lowercase keys and string, number or boolean values",
+	"slug":                 "must be at most 100 lowercase letters and digits in words separated by single hyphens",
+}
+
+func isNumeric(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
 }
 
 func valMessage(fe validator.FieldError) string {
 	switch fe.Tag() {
 	case "min":
+		if isNumeric(fe.Kind()) {
+			return fmt.Sprintf("must be at least %s", fe.Param())
+		}
 		return fmt.Sprintf("must be at least %s characters long", fe.Param())
 	case "max":
+		if isNumeric(fe.Kind()) {
+			return fmt.Sprintf("must be at most %s", fe.Param())
+		}
 		return fmt.Sprintf("must be at most %s characters long", fe.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", strings.Join(strings.Fields(fe.Param()), ", "))
+	case "gtefield":
+		return fmt.Sprintf("must be greater than or equal to %s", fe.Param())
 	default:
 		if msg, ok := validationMessages[fe.Tag()]; ok {
 			return msg
@@ -44,57 +75,66 @@ func valMessage(fe validator.FieldError) string {
 	}
 }
 
+// FieldErrors describes each failed binding tag in ve, for reporting
+// validation outside a request body, such as rows of an uploaded file.
+func FieldErrors(ve validator.ValidationErrors) []RfcFieldError {
+	fieldErrors := make([]RfcFieldError, len(ve))
+	for idx := range ve {
+		fieldErrors[idx] = RfcFieldError{
+			Field:   ve[idx].Field(),
+			Message: valMessage(ve[idx]),
+		}
+	}
+	return fieldErrors
+}
+
 func BindError(c *gin.Context, err error) {
+	problem := BindProblem(err)
+	c.JSON(problem.Status, problem)
+}
+
+// BindProblem is the problem BindError responds with, for errors reported
+// inside a larger response.
+func BindProblem(err error) *RfcValidationError {
 	var ve validator.ValidationErrors
 	var syntaxErr *json.SyntaxError
 	var unmarshalErr *json.UnmarshalTypeError
 
 	switch {
 	case errors.As(err, &ve):
-		jsonErrors := make([]RfcFieldError, len(ve))
-
-		for idx := range ve {
-			jsonErrors[idx] = RfcFieldError{
-				Field:   ve[idx].Field(),
-				Message: valMessage(ve[idx]),
-			}
-		}
-		jsonErr := &RfcValidationError{
+		return &RfcValidationError{
 			Type:   "https://example.com/errors/validation",
 			Title:  "Validation error",
 			Status: http.StatusUnprocessableEntity,
 			Detail: "One or more fields are invalid",
-			Errors: jsonErrors,
+			Errors: FieldErrors(ve),
 		}
-		c.JSON(http.StatusUnprocessableEntity, jsonErr)
 
 	case errors.As(err, &syntaxErr):
-		jsonErr := &RfcValidationError{
+		return &RfcValidationError{
 			Type:   "https://example.com/errors/bad-request",
 			Title:  "Syntax error",
 			Status: http.StatusBadRequest,
 			Detail: "Malformed JSON",
 			Errors: []RfcFieldError{},
 		}
-		c.JSON(http.StatusBadRequest, jsonErr)
 
 	case errors.As(err, &unmarshalErr):
-		jsonErr := &RfcValidationError{
+		return &RfcValidationError{
 			Type:   "https://example.com/errors/bad-request",
 			Title:  "Wrong field type",
 			Status: http.StatusBadRequest,
 			Detail: "Invalid request body",
 			Errors: []RfcFieldError{
 				{Field: unmarshalErr.Field, Message: "invalid type"}}}
-		c.JSON(http.StatusBadRequest, jsonErr)
 
 	default:
-		c.JSON(http.StatusBadRequest, &RfcValidationError{
+		return &RfcValidationError{
 			Type:   "https://example.com/errors/bad-request",
 			Title:  "Bad request",
 			Status: http.StatusBadRequest,
 			Detail: "Invalid request body",
 			Errors: []RfcFieldError{},
-		})
+		}
 	}
 }
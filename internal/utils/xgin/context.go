@@ -39,7 +39,11 @@ func AbortMissingUserID(c *gin.Context) {
 }
 
 func ParseUUID(c *gin.Context) (string, bool) {
-	ID := c.Param("id")
+	return ParseUUIDParam(c, "id")
+}
+
+func ParseUUIDParam(c *gin.Context, name string) (string, bool) {
+	ID := c.Param(name)
 	_, err := uuid.Parse(ID)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, RfcValidationError{
@@ -55,11 +59,16 @@ func ParseUUID(c *gin.Context) (string, bool) {
 }
 
 func ErrorResponse(c *gin.Context, status int, title string, detail string) {
-	c.JSON(status, &RfcValidationError{
+	c.JSON(status, Problem(status, title, detail))
+}
+
+// Problem builds the body ErrorResponse sends.
+func Problem(status int, title string, detail string) *RfcValidationError {
+	return &RfcValidationError{
 		Type:   "https://example.com/errors/" + strings.ReplaceAll(strings.ToLower(title), " ", "-"),
 		Title:  title,
 		Status: status,
 		Detail: detail,
 		Errors: []RfcFieldError{},
-	})
+	}
 }
@@ -0,0 +1,56 @@
+package xgin
+
+import (
+	"e-commerce/internal/domain/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// RegisterValidators adds the app's custom binding tags to gin's validator.
+func RegisterValidators() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	v.RegisterValidation("money", func(fl validator.FieldLevel) bool {
+		m, ok := fl.Field().Interface().(models.Money)
+		return ok && m.Validate() == nil
+	})
+	v.RegisterValidation("currency", func(fl validator.FieldLevel) bool {
+		_, ok := models.CurrencyExponent(fl.Field().String())
+		return ok
+	})
+	v.RegisterValidation("attribute_key", func(fl validator.FieldLevel) bool {
+		return models.ValidAttributeKey(fl.Field().String())
+	})
+	v.RegisterValidation("slug", func(fl validator.FieldLevel) bool {
+		return models.ValidSlug(fl.Field().String())
+	})
+	v.RegisterValidation("attributes", func(fl validator.FieldLevel) bool {
+		attrs, ok := fl.Field().Interface().(map[string]any)
+		if !ok || len(attrs) > models.MaxProductAttributes {
+			return false
+		}
+		for key, value := range attrs {
+			if !models.ValidAttributeKey(key) || !models.ValidAttributeValue(value) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// FieldError responds with a validation error for a single field, for
+// checks that can't be expressed as binding tags.
+func FieldError(c *gin.Context, field, message string) {
+	c.JSON(http.StatusUnprocessableEntity, &RfcValidationError{
+		Type:   "https://example.com/errors/validation",
+		Title:  "Validation error",
+		Status: http.StatusUnprocessableEntity,
+		Detail: "One or more fields are invalid",
+		Errors: []RfcFieldError{{Field: field, Message: message}},
+	})
+}
@@ -0,0 +1,260 @@
+// Package xlsx reads and writes the subset of Office Open XML spreadsheets
+// the product import and export need: a single sheet of plain cells.
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidWorkbook = errors.New("not a valid xlsx workbook")
+
+// maxSharedStrings bounds the shared string table, which has to be held in
+// memory while the sheet is read.
+const maxSharedStrings = 1_000_000
+
+// Reader streams the rows of the first worksheet in a workbook.
+type Reader struct {
+	strings []string
+	sheet   io.ReadCloser
+	dec     *xml.Decoder
+}
+
+// NewReader opens the workbook and positions the reader at the first row
+// of its first sheet. Close releases the sheet.
+func NewReader(r io.ReaderAt, size int64) (*Reader, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWorkbook, err)
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := firstSheet(files)
+	if err != nil {
+		return nil, err
+	}
+	sheetFile, ok := files[sheetPath]
+	if !ok {
+		return nil, fmt.Errorf("%w: missing %s", ErrInvalidWorkbook, sheetPath)
+	}
+
+	var shared []string
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if shared, err = readSharedStrings(f); err != nil {
+			return nil, err
+		}
+	}
+
+	sheet, err := sheetFile.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWorkbook, err)
+	}
+	return &Reader{strings: shared, sheet: sheet, dec: xml.NewDecoder(sheet)}, nil
+}
+
+func (r *Reader) Close() error {
+	return r.sheet.Close()
+}
+
+// Read returns the next row's cell values, with empty strings for skipped
+// cells, and io.EOF after the last row. Numbers come back in their
+// shortest decimal form.
+func (r *Reader) Read() ([]string, error) {
+	for {
+		tok, err := r.dec.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, io.EOF
+			}
+			return nil, fmt.Errorf("%w: %v", ErrInvalidWorkbook, err)
+		}
+		if start, ok := tok.(xml.StartElement); ok && start.Name.Local == "row" {
+			var row xmlRow
+			if err := r.dec.DecodeElement(&row, &start); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidWorkbook, err)
+			}
+			return r.values(row)
+		}
+	}
+}
+
+type xmlRow struct {
+	Cells []struct {
+		Ref    string `xml:"r,attr"`
+		Type   string `xml:"t,attr"`
+		Value  string `xml:"v"`
+		Inline struct {
+			Text string `xml:"t"`
+			Runs []struct {
+				Text string `xml:"t"`
+			} `xml:"r"`
+		} `xml:"is"`
+	} `xml:"c"`
+}
+
+func (r *Reader) values(row xmlRow) ([]string, error) {
+	var values []string
+	for _, c := range row.Cells {
+		col := len(values)
+		if c.Ref != "" {
+			var err error
+			if col, err = columnIndex(c.Ref); err != nil {
+				return nil, err
+			}
+		}
+		if col < len(values) {
+			return nil, fmt.Errorf("%w: cell %s out of order", ErrInvalidWorkbook, c.Ref)
+		}
+		for len(values) < col {
+			values = append(values, "")
+		}
+
+		var v string
+		switch c.Type {
+		case "s":
+			i, err := strconv.Atoi(c.Value)
+			if err != nil || i < 0 || i >= len(r.strings) {
+				return nil, fmt.Errorf("%w: bad shared string index in %s", ErrInvalidWorkbook, c.Ref)
+			}
+			v = r.strings[i]
+		case "inlineStr":
+			v = c.Inline.Text
+			for _, run := range c.Inline.Runs {
+				v += run.Text
+			}
+		case "", "n":
+			v = c.Value
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				v = strconv.FormatFloat(f, 'f', -1, 64)
+			}
+		default:
+			v = c.Value
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// columnIndex converts the letters of a cell reference like "AB12" to a
+// zero-based column index.
+func columnIndex(ref string) (int, error) {
+	col := 0
+	i := 0
+	for ; i < len(ref) && ref[i] >= 'A' && ref[i] <= 'Z'; i++ {
+		col = col*26 + int(ref[i]-'A'+1)
+		if col > 16384 {
+			break
+		}
+	}
+	if i == 0 || col > 16384 {
+		return 0, fmt.Errorf("%w: bad cell reference %q", ErrInvalidWorkbook, ref)
+	}
+	return col - 1, nil
+}
+
+// firstSheet resolves the part name of the workbook's first sheet through
+// the workbook relationships, falling back to the conventional name.
+func firstSheet(files map[string]*zip.File) (string, error) {
+	const fallback = "xl/worksheets/sheet1.xml"
+
+	var workbook struct {
+		Sheets []struct {
+			RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	var rels struct {
+		Rels []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	wb, ok := files["xl/workbook.xml"]
+	if !ok {
+		return "", fmt.Errorf("%w: missing xl/workbook.xml", ErrInvalidWorkbook)
+	}
+	if err := decodePart(wb, &workbook); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", fmt.Errorf("%w: workbook has no sheets", ErrInvalidWorkbook)
+	}
+	relsFile, ok := files["xl/_rels/workbook.xml.rels"]
+	if !ok {
+		return fallback, nil
+	}
+	if err := decodePart(relsFile, &rels); err != nil {
+		return "", err
+	}
+	for _, rel := range rels.Rels {
+		if rel.ID != workbook.Sheets[0].RelID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+	return fallback, nil
+}
+
+func readSharedStrings(f *zip.File) ([]string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWorkbook, err)
+	}
+	defer rc.Close()
+
+	var shared []string
+	dec := xml.NewDecoder(rc)
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return shared, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidWorkbook, err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "si" {
+			continue
+		}
+		var si struct {
+			Text string `xml:"t"`
+			Runs []struct {
+				Text string `xml:"t"`
+			} `xml:"r"`
+		}
+		if err := dec.DecodeElement(&si, &start); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidWorkbook, err)
+		}
+		s := si.Text
+		for _, run := range si.Runs {
+			s += run.Text
+		}
+		if len(shared) == maxSharedStrings {
+			return nil, fmt.Errorf("%w: too many shared strings", ErrInvalidWorkbook)
+		}
+		shared = append(shared, s)
+	}
+}
+
+func decodePart(f *zip.File, v any) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidWorkbook, err)
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidWorkbook, f.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strconv"
+)
+
+const ContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// Cell is a value to write. Numbers are stored as numeric cells so that
+// spreadsheets can sum them; everything else is an inline string.
+type Cell struct {
+	Value  string
+	Number bool
+}
+
+func String(s string) Cell { return Cell{Value: s} }
+
+// Number makes a numeric cell from a decimal string such as "19.99".
+func Number(s string) Cell { return Cell{Value: s, Number: true} }
+
+// Writer streams a single-sheet workbook. Rows go straight to the zip
+// entry for the sheet, so nothing but the current row is held in memory.
+type Writer struct {
+	zw    *zip.Writer
+	sheet *bufio.Writer
+	row   int
+	err   error
+}
+
+var staticParts = []struct{ name, body string }{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+// NewWriter starts a workbook whose only sheet is called sheetName.
+func NewWriter(w io.Writer, sheetName string) *Writer {
+	x := &Writer{zw: zip.NewWriter(w)}
+
+	for _, part := range staticParts {
+		x.writePart(part.name, part.body)
+	}
+	var name bytes.Buffer
+	xml.EscapeText(&name, []byte(sheetName))
+	x.writePart("xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">`+
+		`<sheets><sheet name="`+name.String()+`" sheetId="1" r:id="rId1"/></sheets></workbook>`)
+
+	if x.err == nil {
+		var sheet io.Writer
+		sheet, x.err = x.zw.Create("xl/worksheets/sheet1.xml")
+		if x.err == nil {
+			x.sheet = bufio.NewWriter(sheet)
+			_, x.err = x.sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+		}
+	}
+	return x
+}
+
+func (x *Writer) writePart(name, body string) {
+	if x.err != nil {
+		return
+	}
+	var part io.Writer
+	if part, x.err = x.zw.Create(name); x.err == nil {
+		_, x.err = io.WriteString(part, body)
+	}
+}
+
+// WriteRow appends a row of cells to the sheet.
+func (x *Writer) WriteRow(cells ...Cell) error {
+	if x.err != nil {
+		return x.err
+	}
+	x.row++
+	var buf bytes.Buffer
+	buf.WriteString(`<row r="` + strconv.Itoa(x.row) + `">`)
+	for _, c := range cells {
+		if c.Number {
+			buf.WriteString(`<c><v>`)
+			xml.EscapeText(&buf, []byte(c.Value))
+			buf.WriteString(`</v></c>`)
+			continue
+		}
+		buf.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+		xml.EscapeText(&buf, []byte(c.Value))
+		buf.WriteString(`</t></is></c>`)
+	}
+	buf.WriteString(`</row>`)
+	_, x.err = x.sheet.Write(buf.Bytes())
+	return x.err
+}
+
+// Flush pushes buffered rows to the underlying writer.
+func (x *Writer) Flush() error {
+	if x.err != nil {
+		return x.err
+	}
+	if x.err = x.sheet.Flush(); x.err == nil {
+		x.err = x.zw.Flush()
+	}
+	return x.err
+}
+
+// Close ends the sheet and writes the zip directory. It does not close
+// the underlying writer.
+func (x *Writer) Close() error {
+	if x.err != nil {
+		return x.err
+	}
+	if _, x.err = x.sheet.WriteString(`</sheetData></worksheet>`); x.err != nil {
+		return x.err
+	}
+	if x.err = x.sheet.Flush(); x.err != nil {
+		return x.err
+	}
+	return x.zw.Close()
+}
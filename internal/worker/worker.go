@@ -0,0 +1,33 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Job is a unit of periodic background work. It returns the number of
+// items it processed, which is only used for logging.
+type Job func(ctx context.Context) (int, error)
+
+// Run calls job every interval until ctx is cancelled. Errors are logged
+// and the job is retried on the next tick.
+func Run(ctx context.Context, name string, interval time.Duration, job Job) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := job(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("[ERROR] worker %s: %v", name, err)
+		} else if n > 0 {
+			log.Printf("worker %s: processed %d", name, n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_products_user_updated_id;
+DROP INDEX IF EXISTS idx_products_user_created_id;
+DROP INDEX IF EXISTS idx_products_user_price_id;
+DROP INDEX IF EXISTS idx_products_user_name_id;
+
+ALTER TABLE products
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN updated_at DROP NOT NULL;
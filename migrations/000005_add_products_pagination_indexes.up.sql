@@ -0,0 +1,11 @@
+UPDATE products SET created_at = NOW() WHERE created_at IS NULL;
+UPDATE products SET updated_at = created_at WHERE updated_at IS NULL;
+
+ALTER TABLE products
+    ALTER COLUMN created_at SET NOT NULL,
+    ALTER COLUMN updated_at SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_products_user_name_id ON products(user_id, name, id);
+CREATE INDEX IF NOT EXISTS idx_products_user_price_id ON products(user_id, price, id);
+CREATE INDEX IF NOT EXISTS idx_products_user_created_id ON products(user_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_products_user_updated_id ON products(user_id, updated_at, id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_products_updated_id;
+DROP INDEX IF EXISTS idx_products_created_id;
+DROP INDEX IF EXISTS idx_products_price_id;
+DROP INDEX IF EXISTS idx_products_name_id;
@@ -0,0 +1,4 @@
+CREATE INDEX IF NOT EXISTS idx_products_name_id ON products(name, id);
+CREATE INDEX IF NOT EXISTS idx_products_price_id ON products(price, id);
+CREATE INDEX IF NOT EXISTS idx_products_created_id ON products(created_at, id);
+CREATE INDEX IF NOT EXISTS idx_products_updated_id ON products(updated_at, id);
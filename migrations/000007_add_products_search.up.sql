@@ -0,0 +1,8 @@
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS search_language regconfig NOT NULL DEFAULT 'english';
+
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (to_tsvector(search_language, name)) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING GIN (search_vector);
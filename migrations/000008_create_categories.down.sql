@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS product_categories;
+
+DROP TRIGGER IF EXISTS update_categories_modtime ON categories;
+
+DROP TABLE IF EXISTS categories;
@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS categories (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    parent_id UUID REFERENCES categories(id),
+    name TEXT NOT NULL,
+    path TEXT NOT NULL,
+
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT categories_unique_sibling_name UNIQUE NULLS NOT DISTINCT (user_id, parent_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_categories_user_path ON categories(user_id, path text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories(parent_id);
+
+CREATE TRIGGER update_categories_modtime
+    BEFORE UPDATE ON categories
+    FOR EACH ROW
+    EXECUTE PROCEDURE update_modified_column();
+
+CREATE TABLE IF NOT EXISTS product_categories (
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    category_id UUID NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    PRIMARY KEY (product_id, category_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_categories_category_id ON product_categories(category_id);
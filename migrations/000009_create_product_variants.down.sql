@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS update_product_variants_modtime ON product_variants;
+
+DROP TABLE IF EXISTS product_variants;
+
+DROP TABLE IF EXISTS product_options;
@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS product_options (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    position INT NOT NULL,
+    option_values TEXT[] NOT NULL,
+
+    CONSTRAINT product_options_unique_name UNIQUE (product_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS product_variants (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    sku TEXT NOT NULL,
+    price NUMERIC(10,2) CHECK (price > 0),
+    options JSONB NOT NULL,
+
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT product_variants_unique_sku UNIQUE (user_id, sku),
+    CONSTRAINT product_variants_unique_options UNIQUE (product_id, options)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_variants_product_id ON product_variants(product_id);
+
+CREATE TRIGGER update_product_variants_modtime
+    BEFORE UPDATE ON product_variants
+    FOR EACH ROW
+    EXECUTE PROCEDURE update_modified_column();
@@ -0,0 +1,10 @@
+DROP TRIGGER IF EXISTS stock_movements_append_only ON stock_movements;
+DROP FUNCTION IF EXISTS forbid_stock_movement_update();
+
+DROP TABLE IF EXISTS stock_movements;
+
+DROP TABLE IF EXISTS stock_reservations;
+
+DROP TRIGGER IF EXISTS update_stock_levels_modtime ON stock_levels;
+
+DROP TABLE IF EXISTS stock_levels;
@@ -0,0 +1,62 @@
+CREATE TABLE IF NOT EXISTS stock_levels (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    variant_id UUID REFERENCES product_variants(id) ON DELETE CASCADE,
+    on_hand INT NOT NULL DEFAULT 0,
+    reserved INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT stock_levels_on_hand_non_negative CHECK (on_hand >= 0),
+    CONSTRAINT stock_levels_reserved_within_on_hand CHECK (reserved >= 0 AND reserved <= on_hand),
+    CONSTRAINT stock_levels_unique_item UNIQUE NULLS NOT DISTINCT (product_id, variant_id)
+);
+
+CREATE TRIGGER update_stock_levels_modtime
+    BEFORE UPDATE ON stock_levels
+    FOR EACH ROW
+    EXECUTE PROCEDURE update_modified_column();
+
+CREATE TABLE IF NOT EXISTS stock_reservations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    stock_id UUID NOT NULL REFERENCES stock_levels(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    status TEXT NOT NULL DEFAULT 'active'
+        CHECK (status IN ('active', 'committed', 'released', 'expired')),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_reservations_active_expiry
+    ON stock_reservations(expires_at) WHERE status = 'active';
+
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id BIGSERIAL PRIMARY KEY,
+    stock_id UUID NOT NULL REFERENCES stock_levels(id) ON DELETE CASCADE,
+    quantity INT NOT NULL CHECK (quantity <> 0),
+    reason TEXT NOT NULL CHECK (reason IN ('receipt', 'sale', 'adjustment', 'return')),
+    note TEXT NOT NULL DEFAULT '',
+    reservation_id UUID REFERENCES stock_reservations(id) ON DELETE SET NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_stock_id ON stock_movements(stock_id, id);
+
+-- ledger rows are immutable; only ON DELETE SET NULL from referenced
+-- reservations/users may touch them
+CREATE OR REPLACE FUNCTION forbid_stock_movement_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF (NEW.id, NEW.stock_id, NEW.quantity, NEW.reason, NEW.note, NEW.created_at)
+        IS DISTINCT FROM (OLD.id, OLD.stock_id, OLD.quantity, OLD.reason, OLD.note, OLD.created_at) THEN
+        RAISE EXCEPTION 'stock_movements is append-only';
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER stock_movements_append_only
+    BEFORE UPDATE ON stock_movements
+    FOR EACH ROW
+    EXECUTE PROCEDURE forbid_stock_movement_update();
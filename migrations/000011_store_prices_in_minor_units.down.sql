@@ -0,0 +1,13 @@
+ALTER TABLE product_variants
+    DROP CONSTRAINT IF EXISTS product_variants_price_currency,
+    DROP COLUMN IF EXISTS currency,
+    ALTER COLUMN price TYPE NUMERIC(10,2) USING price / 100.0;
+
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_unique_name_price;
+
+ALTER TABLE products
+    DROP CONSTRAINT IF EXISTS products_price_positive,
+    DROP COLUMN IF EXISTS currency,
+    ALTER COLUMN price TYPE NUMERIC(10,2) USING price / 100.0;
+
+ALTER TABLE products ADD CONSTRAINT products_unique_name_price UNIQUE (name, price);
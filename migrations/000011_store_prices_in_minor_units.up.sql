@@ -0,0 +1,22 @@
+ALTER TABLE products DROP CONSTRAINT products_unique_name_price;
+
+ALTER TABLE products
+    ALTER COLUMN price TYPE BIGINT USING round(price * 100)::BIGINT,
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD',
+    ADD CONSTRAINT products_price_positive CHECK (price > 0);
+
+ALTER TABLE products ALTER COLUMN currency DROP DEFAULT;
+
+ALTER TABLE products ADD CONSTRAINT products_unique_name_price UNIQUE (name, price, currency);
+
+ALTER TABLE product_variants
+    ALTER COLUMN price TYPE BIGINT USING round(price * 100)::BIGINT,
+    ADD COLUMN currency CHAR(3);
+
+UPDATE product_variants v
+SET currency = p.currency
+FROM products p
+WHERE p.id = v.product_id AND v.price IS NOT NULL;
+
+ALTER TABLE product_variants
+    ADD CONSTRAINT product_variants_price_currency CHECK ((price IS NULL) = (currency IS NULL));
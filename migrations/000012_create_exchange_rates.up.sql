@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    base_currency CHAR(3) NOT NULL,
+    quote_currency CHAR(3) NOT NULL,
+    rate NUMERIC NOT NULL CHECK (rate > 0),
+    as_of TIMESTAMP WITH TIME ZONE NOT NULL,
+    source TEXT NOT NULL,
+
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (base_currency, quote_currency),
+    CONSTRAINT exchange_rates_distinct_pair CHECK (base_currency <> quote_currency)
+);
+
+CREATE TRIGGER update_exchange_rates_modtime
+    BEFORE UPDATE ON exchange_rates
+    FOR EACH ROW
+    EXECUTE PROCEDURE update_modified_column();
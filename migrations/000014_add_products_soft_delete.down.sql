@@ -0,0 +1,8 @@
+DELETE FROM products WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_products_deleted_at;
+DROP INDEX IF EXISTS idx_products_user_deleted_id;
+DROP INDEX IF EXISTS products_unique_name_price;
+
+ALTER TABLE products ADD CONSTRAINT products_unique_name_price UNIQUE (name, price, currency);
+ALTER TABLE products DROP COLUMN IF EXISTS deleted_at;
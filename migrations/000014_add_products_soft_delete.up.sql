@@ -0,0 +1,8 @@
+ALTER TABLE products ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- trashed products must not block re-creating the same listing
+ALTER TABLE products DROP CONSTRAINT products_unique_name_price;
+CREATE UNIQUE INDEX IF NOT EXISTS products_unique_name_price ON products(name, price, currency) WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_products_user_deleted_id ON products(user_id, deleted_at, id) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_products_deleted_at ON products(deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS record_product_revision ON products;
+DROP FUNCTION IF EXISTS record_product_revision();
+DROP FUNCTION IF EXISTS product_revision_doc(products);
+DROP TABLE IF EXISTS product_revisions;
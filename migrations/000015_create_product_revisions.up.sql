@@ -0,0 +1,68 @@
+CREATE TABLE IF NOT EXISTS product_revisions (
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    revision INT NOT NULL,
+    action TEXT NOT NULL,
+    actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    before JSONB,
+    after JSONB NOT NULL,
+
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (product_id, revision)
+);
+
+-- product_revision_doc is the part of a product row worth keeping in
+-- history: derived and bookkeeping columns are left out so that touching
+-- only them doesn't create a revision.
+CREATE OR REPLACE FUNCTION product_revision_doc(p products) RETURNS JSONB AS $$
+    SELECT to_jsonb(p) - 'search_vector' - 'search_language' - 'created_at' - 'updated_at';
+$$ LANGUAGE sql STABLE;
+
+-- The application names the actor and action with
+--   SET LOCAL app.actor_id = '...'; SET LOCAL app.revision_action = '...';
+-- changes made without them (workers, manual SQL) are still recorded,
+-- with no actor and an inferred action.
+CREATE OR REPLACE FUNCTION record_product_revision() RETURNS TRIGGER AS $$
+DECLARE
+    before_doc JSONB;
+    after_doc JSONB := product_revision_doc(NEW);
+    act TEXT := NULLIF(current_setting('app.revision_action', true), '');
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        before_doc := product_revision_doc(OLD);
+        IF before_doc = after_doc THEN
+            RETURN NEW;
+        END IF;
+    END IF;
+
+    IF act IS NULL THEN
+        act := CASE
+            WHEN TG_OP = 'INSERT' THEN 'create'
+            WHEN OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN 'delete'
+            WHEN OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN 'restore'
+            ELSE 'update'
+        END;
+    END IF;
+
+    INSERT INTO product_revisions (product_id, revision, action, actor_id, before, after)
+    VALUES (
+        NEW.id,
+        COALESCE((SELECT max(revision) FROM product_revisions WHERE product_id = NEW.id), 0) + 1,
+        act,
+        NULLIF(current_setting('app.actor_id', true), '')::uuid,
+        before_doc,
+        after_doc
+    );
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER record_product_revision
+    AFTER INSERT OR UPDATE ON products
+    FOR EACH ROW
+    EXECUTE PROCEDURE record_product_revision();
+
+-- existing products start their history with a baseline revision
+INSERT INTO product_revisions (product_id, revision, action, before, after, created_at)
+SELECT p.id, 1, 'create', NULL, product_revision_doc(p), p.created_at
+FROM products p;
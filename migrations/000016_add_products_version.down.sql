@@ -0,0 +1,7 @@
+CREATE OR REPLACE FUNCTION product_revision_doc(p products) RETURNS JSONB AS $$
+    SELECT to_jsonb(p) - 'search_vector' - 'search_language' - 'created_at' - 'updated_at';
+$$ LANGUAGE sql STABLE;
+
+DROP TRIGGER IF EXISTS bump_product_version ON products;
+DROP FUNCTION IF EXISTS bump_product_version();
+ALTER TABLE products DROP COLUMN IF EXISTS version;
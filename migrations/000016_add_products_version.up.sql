@@ -0,0 +1,18 @@
+ALTER TABLE products ADD COLUMN version INT NOT NULL DEFAULT 1;
+
+CREATE OR REPLACE FUNCTION bump_product_version() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.version := OLD.version + 1;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER bump_product_version
+    BEFORE UPDATE ON products
+    FOR EACH ROW
+    EXECUTE PROCEDURE bump_product_version();
+
+-- version changes on every write, so it would make every revision differ
+CREATE OR REPLACE FUNCTION product_revision_doc(p products) RETURNS JSONB AS $$
+    SELECT to_jsonb(p) - 'search_vector' - 'search_language' - 'created_at' - 'updated_at' - 'version';
+$$ LANGUAGE sql STABLE;
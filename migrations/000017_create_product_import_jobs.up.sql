@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS product_import_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'done', 'failed')),
+    format TEXT NOT NULL,
+    dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+    total INT NOT NULL DEFAULT 0,
+    -- rows that passed validation, applied by the import worker
+    pending_rows JSONB NOT NULL DEFAULT '[]',
+    -- per-line outcome, including lines rejected at upload
+    results JSONB NOT NULL DEFAULT '[]',
+    error TEXT,
+
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMP WITH TIME ZONE,
+    finished_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_import_jobs_queue ON product_import_jobs(created_at) WHERE status IN ('pending', 'running');
@@ -0,0 +1,6 @@
+UPDATE product_revisions SET after = after - 'attributes', before = before - 'attributes';
+
+ALTER TABLE categories DROP COLUMN IF EXISTS attributes;
+
+DROP INDEX IF EXISTS idx_products_attributes;
+ALTER TABLE products DROP COLUMN IF EXISTS attributes;
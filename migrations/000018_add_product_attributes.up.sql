@@ -0,0 +1,15 @@
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}'
+    CONSTRAINT products_attributes_object CHECK (jsonb_typeof(attributes) = 'object');
+
+-- jsonb_path_ops serves the @> containment used by attribute filters
+CREATE INDEX IF NOT EXISTS idx_products_attributes ON products USING GIN (attributes jsonb_path_ops);
+
+ALTER TABLE categories
+    ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '[]'
+    CONSTRAINT categories_attributes_array CHECK (jsonb_typeof(attributes) = 'array');
+
+-- older revisions predate the column; give them an empty set so that a
+-- revert to them clears the attributes rather than failing
+UPDATE product_revisions SET after = after || '{"attributes": {}}' WHERE NOT after ? 'attributes';
+UPDATE product_revisions SET before = before || '{"attributes": {}}' WHERE before IS NOT NULL AND NOT before ? 'attributes';
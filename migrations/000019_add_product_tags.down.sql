@@ -0,0 +1,4 @@
+UPDATE product_revisions SET after = after - 'tags', before = before - 'tags';
+
+DROP INDEX IF EXISTS idx_products_tags;
+ALTER TABLE products DROP COLUMN IF EXISTS tags;
@@ -0,0 +1,8 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+-- serves both && (any of the tags) and @> (all of them)
+CREATE INDEX IF NOT EXISTS idx_products_tags ON products USING GIN (tags);
+
+-- older revisions predate the column; see 000018
+UPDATE product_revisions SET after = after || '{"tags": []}' WHERE NOT after ? 'tags';
+UPDATE product_revisions SET before = before || '{"tags": []}' WHERE before IS NOT NULL AND NOT before ? 'tags';
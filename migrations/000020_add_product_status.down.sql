@@ -0,0 +1,9 @@
+UPDATE product_revisions SET after = after - 'status' - 'publish_at' - 'unpublish_at',
+    before = before - 'status' - 'publish_at' - 'unpublish_at';
+
+DROP INDEX IF EXISTS idx_products_unpublish_at;
+DROP INDEX IF EXISTS idx_products_publish_at;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_schedule_order;
+ALTER TABLE products DROP COLUMN IF EXISTS unpublish_at;
+ALTER TABLE products DROP COLUMN IF EXISTS publish_at;
+ALTER TABLE products DROP COLUMN IF EXISTS status;
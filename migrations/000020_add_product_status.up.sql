@@ -0,0 +1,18 @@
+-- products that already exist were live, so they start out published;
+-- new ones are drafts until the seller publishes them
+ALTER TABLE products ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'published'
+    CHECK (status IN ('draft', 'published', 'archived'));
+ALTER TABLE products ALTER COLUMN status SET DEFAULT 'draft';
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS publish_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS unpublish_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE products ADD CONSTRAINT products_schedule_order
+    CHECK (publish_at IS NULL OR unpublish_at IS NULL OR unpublish_at > publish_at);
+
+-- the scheduler only ever looks for due drafts and due published products
+CREATE INDEX IF NOT EXISTS idx_products_publish_at ON products (publish_at) WHERE status = 'draft' AND publish_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_products_unpublish_at ON products (unpublish_at) WHERE status = 'published' AND unpublish_at IS NOT NULL;
+
+-- older revisions predate the columns, back when every product was live
+UPDATE product_revisions SET after = after || '{"status": "published", "publish_at": null, "unpublish_at": null}' WHERE NOT after ? 'status';
+UPDATE product_revisions SET before = before || '{"status": "published", "publish_at": null, "unpublish_at": null}' WHERE before IS NOT NULL AND NOT before ? 'status';
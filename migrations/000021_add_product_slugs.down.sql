@@ -0,0 +1,13 @@
+CREATE OR REPLACE FUNCTION product_revision_doc(p products) RETURNS JSONB AS $$
+    SELECT to_jsonb(p) - 'search_vector' - 'search_language' - 'created_at' - 'updated_at' - 'version';
+$$ LANGUAGE sql STABLE;
+
+DROP TRIGGER IF EXISTS record_product_slug ON products;
+DROP TRIGGER IF EXISTS set_product_slug ON products;
+DROP FUNCTION IF EXISTS record_product_slug();
+DROP FUNCTION IF EXISTS set_product_slug();
+DROP FUNCTION IF EXISTS free_product_slug(TEXT);
+
+DROP TABLE IF EXISTS product_slugs;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_slug_format;
+ALTER TABLE products DROP COLUMN IF EXISTS slug;
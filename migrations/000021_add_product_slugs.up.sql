@@ -0,0 +1,103 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS slug TEXT;
+
+-- every slug a product has ever had, current one included, so that old
+-- storefront links keep resolving; a slug is never reused by another
+-- product until its owner is purged
+CREATE TABLE IF NOT EXISTS product_slugs (
+    slug TEXT PRIMARY KEY,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_slugs_product_id ON product_slugs(product_id);
+
+-- free_product_slug returns base, or base-2, base-3, ... whichever no
+-- product has used yet
+CREATE OR REPLACE FUNCTION free_product_slug(base TEXT) RETURNS TEXT AS $$
+DECLARE
+    candidate TEXT := base;
+    n INT := 1;
+BEGIN
+    WHILE EXISTS (SELECT 1 FROM product_slugs WHERE slug = candidate) LOOP
+        n := n + 1;
+        candidate := base || '-' || n;
+    END LOOP;
+    RETURN candidate;
+END;
+$$ LANGUAGE plpgsql STABLE;
+
+-- mirrors models.Slugify closely enough to backfill existing products;
+-- new slugs are always computed by the application
+CREATE FUNCTION slugify_product_name(name TEXT) RETURNS TEXT AS $$
+    SELECT COALESCE(NULLIF(rtrim(left(trim(BOTH '-' FROM regexp_replace(
+        translate(
+            replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(lower(name), 'ж', 'zh'), 'ц', 'ts'), 'ч', 'ch'), 'щ', 'sch'), 'ш', 'sh'), 'ю', 'yu'), 'я', 'ya'), 'є', 'ye'), 'ї', 'yi'), 'ß', 'ss'), 'æ', 'ae'), 'œ', 'oe'), 'þ', 'th'),
+            'àáâãäåçèéêëìíîïñòóôõöùúûüýÿāăąćĉċčďēĕėęěĝğġģĥĩīĭįĵķĺļľńņňōŏőŕŗřśŝşšţťũūŭůűųŵŷźżžơưǎǐǒǔǖǘǚǜǟǡǧǩǫǭǰǵǹǻȁȃȅȇȉȋȍȏȑȓȕȗșțȟȧȩȫȭȯȱȳабвгдеёзийклмнопрстуфыэіґøłđðхъь',
+            'aaaaaaceeeeiiiinooooouuuuyyaaaccccdeeeeegggghiiiijklllnnnooorrrssssttuuuuuuwyzzzouaiouuuuuaagkoojgnaaaeeiioorruusthaeooooyabvgdeeziyklmnoprstufyeigolddh'),
+        '[^a-z0-9]+', '-', 'g')), 80), '-'), ''), 'product');
+$$ LANGUAGE sql IMMUTABLE;
+
+-- the backfill mustn't bump every version and updated_at
+ALTER TABLE products DISABLE TRIGGER USER;
+
+DO $$
+DECLARE
+    p RECORD;
+    claimed TEXT;
+BEGIN
+    FOR p IN SELECT id, name FROM products ORDER BY created_at, id LOOP
+        claimed := free_product_slug(slugify_product_name(p.name));
+        INSERT INTO product_slugs (slug, product_id) VALUES (claimed, p.id);
+        UPDATE products SET slug = claimed WHERE id = p.id;
+    END LOOP;
+END;
+$$;
+
+ALTER TABLE products ENABLE TRIGGER USER;
+DROP FUNCTION slugify_product_name(TEXT);
+
+ALTER TABLE products ALTER COLUMN slug SET NOT NULL;
+ALTER TABLE products ADD CONSTRAINT products_slug_format CHECK (slug ~ '^[a-z0-9]+(-[a-z0-9]+)*$' AND length(slug) <= 100);
+
+-- the application inserts a base slug; it is made unique here
+CREATE OR REPLACE FUNCTION set_product_slug() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.slug := free_product_slug(COALESCE(NULLIF(NEW.slug, ''), 'product'));
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER set_product_slug
+    BEFORE INSERT ON products
+    FOR EACH ROW
+    EXECUTE PROCEDURE set_product_slug();
+
+-- record_product_slug claims the product's slug in product_slugs, whose
+-- primary key is what keeps slugs unique. A new product that lost its
+-- slug to a concurrent insert moves on to the next free one; an explicit
+-- slug change that lost fails.
+CREATE OR REPLACE FUNCTION record_product_slug() RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO product_slugs (slug, product_id) VALUES (NEW.slug, NEW.id)
+    ON CONFLICT (slug) DO UPDATE SET created_at = NOW()
+    WHERE product_slugs.product_id = EXCLUDED.product_id;
+    IF FOUND THEN
+        RETURN NULL;
+    END IF;
+    IF TG_OP = 'UPDATE' THEN
+        RAISE unique_violation USING MESSAGE = 'slug ' || NEW.slug || ' is taken', CONSTRAINT = 'product_slugs_pkey';
+    END IF;
+    UPDATE products SET slug = free_product_slug(NEW.slug) WHERE id = NEW.id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER record_product_slug
+    AFTER INSERT OR UPDATE OF slug ON products
+    FOR EACH ROW
+    EXECUTE PROCEDURE record_product_slug();
+
+-- slug history lives in product_slugs, so changing it isn't a revision
+CREATE OR REPLACE FUNCTION product_revision_doc(p products) RETURNS JSONB AS $$
+    SELECT to_jsonb(p) - 'search_vector' - 'search_language' - 'created_at' - 'updated_at' - 'version' - 'slug';
+$$ LANGUAGE sql STABLE;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS product_price_schedules;
+
+DROP TRIGGER IF EXISTS record_product_price ON products;
+DROP FUNCTION IF EXISTS record_product_price();
+DROP TABLE IF EXISTS product_prices;
@@ -0,0 +1,84 @@
+-- product_prices is the price timeline: one row per period a price was in
+-- effect, the current one open-ended. It is kept by a trigger, so every
+-- write path is covered.
+CREATE TABLE IF NOT EXISTS product_prices (
+    id BIGSERIAL PRIMARY KEY,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    price BIGINT NOT NULL,
+    currency TEXT NOT NULL,
+    effective_from TIMESTAMP WITH TIME ZONE NOT NULL,
+    effective_to TIMESTAMP WITH TIME ZONE,
+
+    CHECK (effective_to IS NULL OR effective_to >= effective_from)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_prices_product_from ON product_prices(product_id, effective_from);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_prices_current ON product_prices(product_id) WHERE effective_to IS NULL;
+
+CREATE OR REPLACE FUNCTION record_product_price() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW.price = OLD.price AND NEW.currency = OLD.currency THEN
+        RETURN NULL;
+    END IF;
+    UPDATE product_prices SET effective_to = NOW() WHERE product_id = NEW.id AND effective_to IS NULL;
+    INSERT INTO product_prices (product_id, price, currency, effective_from)
+    VALUES (NEW.id, NEW.price, NEW.currency, NOW());
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER record_product_price
+    AFTER INSERT OR UPDATE OF price, currency ON products
+    FOR EACH ROW
+    EXECUTE PROCEDURE record_product_price();
+
+-- rebuild what history there is from the revisions: the price a product
+-- had before its first recorded revision dates from its creation, and
+-- every revision that changed the price starts a new period
+INSERT INTO product_prices (product_id, price, currency, effective_from, effective_to)
+SELECT product_id, price, currency, effective_from,
+       lead(effective_from) OVER (PARTITION BY product_id ORDER BY effective_from, seq)
+FROM (
+    SELECT p.id AS product_id,
+           COALESCE((first.before->>'price')::bigint, p.price) AS price,
+           COALESCE(first.before->>'currency', p.currency) AS currency,
+           p.created_at AS effective_from,
+           0 AS seq
+    FROM products p
+    LEFT JOIN LATERAL (
+        SELECT before FROM product_revisions r
+        WHERE r.product_id = p.id
+        ORDER BY revision
+        LIMIT 1
+    ) first ON true
+    WHERE first.before IS NOT NULL OR NOT EXISTS (SELECT 1 FROM product_revisions r WHERE r.product_id = p.id)
+    UNION ALL
+    SELECT r.product_id, (r.after->>'price')::bigint, r.after->>'currency', r.created_at, r.revision
+    FROM product_revisions r
+    WHERE r.before IS NULL
+       OR r.before->'price' IS DISTINCT FROM r.after->'price'
+       OR r.before->'currency' IS DISTINCT FROM r.after->'currency'
+) changes;
+
+-- a scheduled price replaces the product's price from effective_from and,
+-- if effective_to is set, gives the previous one back then
+CREATE TABLE IF NOT EXISTS product_price_schedules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    price BIGINT NOT NULL CHECK (price > 0),
+    currency TEXT NOT NULL,
+    effective_from TIMESTAMP WITH TIME ZONE NOT NULL,
+    effective_to TIMESTAMP WITH TIME ZONE,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'active', 'done', 'failed')),
+    previous_price BIGINT,
+    previous_currency TEXT,
+    error TEXT,
+
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CHECK (effective_to IS NULL OR effective_to > effective_from)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_price_schedules_product ON product_price_schedules(product_id, effective_from);
+CREATE INDEX IF NOT EXISTS idx_product_price_schedules_start ON product_price_schedules(effective_from) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_product_price_schedules_end ON product_price_schedules(effective_to) WHERE status = 'active';
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS product_review_votes;
+DROP FUNCTION IF EXISTS count_review_vote();
+
+DROP TABLE IF EXISTS product_reviews;
+DROP FUNCTION IF EXISTS count_review_rating();
+DROP TABLE IF EXISTS product_ratings;
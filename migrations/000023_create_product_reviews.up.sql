@@ -0,0 +1,82 @@
+CREATE TABLE IF NOT EXISTS product_reviews (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    title TEXT NOT NULL,
+    body TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    helpful_count INT NOT NULL DEFAULT 0 CHECK (helpful_count >= 0),
+    reply TEXT,
+    replied_at TIMESTAMP WITH TIME ZONE,
+
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    UNIQUE (product_id, user_id),
+    CHECK ((reply IS NULL) = (replied_at IS NULL))
+);
+
+-- the storefront lists approved reviews by recency or helpfulness
+CREATE INDEX IF NOT EXISTS idx_product_reviews_approved_recent
+    ON product_reviews(product_id, created_at DESC, id DESC) WHERE status = 'approved';
+CREATE INDEX IF NOT EXISTS idx_product_reviews_approved_helpful
+    ON product_reviews(product_id, helpful_count DESC, id DESC) WHERE status = 'approved';
+CREATE INDEX IF NOT EXISTS idx_product_reviews_user ON product_reviews(user_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS product_review_votes (
+    review_id UUID NOT NULL REFERENCES product_reviews(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (review_id, user_id)
+);
+
+-- product_ratings sums up a product's approved reviews. It lives apart
+-- from products so that a new review neither bumps the product's version
+-- nor writes a revision. Triggers keep it and helpful_count in step with
+-- every write, in the writer's transaction.
+CREATE TABLE IF NOT EXISTS product_ratings (
+    product_id UUID PRIMARY KEY REFERENCES products(id) ON DELETE CASCADE,
+    review_count INT NOT NULL DEFAULT 0 CHECK (review_count >= 0),
+    rating_sum INT NOT NULL DEFAULT 0 CHECK (rating_sum >= 0)
+);
+
+CREATE OR REPLACE FUNCTION count_review_rating() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') AND OLD.status = 'approved' THEN
+        UPDATE product_ratings
+        SET review_count = review_count - 1, rating_sum = rating_sum - OLD.rating
+        WHERE product_id = OLD.product_id;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') AND NEW.status = 'approved' THEN
+        INSERT INTO product_ratings (product_id, review_count, rating_sum)
+        VALUES (NEW.product_id, 1, NEW.rating)
+        ON CONFLICT (product_id) DO UPDATE
+        SET review_count = product_ratings.review_count + 1,
+            rating_sum = product_ratings.rating_sum + EXCLUDED.rating_sum;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER count_review_rating
+    AFTER INSERT OR UPDATE OF rating, status OR DELETE ON product_reviews
+    FOR EACH ROW
+    EXECUTE PROCEDURE count_review_rating();
+
+CREATE OR REPLACE FUNCTION count_review_vote() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        UPDATE product_reviews SET helpful_count = helpful_count + 1 WHERE id = NEW.review_id;
+    ELSE
+        UPDATE product_reviews SET helpful_count = helpful_count - 1 WHERE id = OLD.review_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER count_review_vote
+    AFTER INSERT OR DELETE ON product_review_votes
+    FOR EACH ROW
+    EXECUTE PROCEDURE count_review_vote();
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_stock_movements_sales;
+DROP TABLE IF EXISTS product_affinity;
@@ -0,0 +1,16 @@
+-- product_affinity holds how many buyers bought both products, as last
+-- computed by the affinity job. It is rebuilt wholesale on every run.
+CREATE TABLE IF NOT EXISTS product_affinity (
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    related_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    buyers INT NOT NULL CHECK (buyers > 0),
+    computed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (product_id, related_id),
+    CHECK (product_id <> related_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_affinity_product_buyers ON product_affinity(product_id, buyers DESC);
+
+-- the job scans recent sales
+CREATE INDEX IF NOT EXISTS idx_stock_movements_sales ON stock_movements(created_at) WHERE reason = 'sale';
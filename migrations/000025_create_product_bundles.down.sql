@@ -0,0 +1,9 @@
+DROP TRIGGER IF EXISTS guard_bundle_component ON products;
+DROP FUNCTION IF EXISTS guard_bundle_component();
+DROP TRIGGER IF EXISTS reprice_bundles ON products;
+DROP FUNCTION IF EXISTS reprice_bundles();
+DROP TRIGGER IF EXISTS price_discounted_bundle ON products;
+DROP FUNCTION IF EXISTS price_discounted_bundle();
+
+DROP TABLE IF EXISTS product_bundle_items;
+DROP TABLE IF EXISTS product_bundles;
@@ -0,0 +1,92 @@
+-- A bundle is a product sold as a set of the seller's other products. Its
+-- price is either fixed, in products.price as usual, or follows the
+-- components' total less discount_percent.
+CREATE TABLE IF NOT EXISTS product_bundles (
+    product_id UUID PRIMARY KEY REFERENCES products(id) ON DELETE CASCADE,
+    discount_percent INT CHECK (discount_percent BETWEEN 1 AND 99)
+);
+
+CREATE TABLE IF NOT EXISTS product_bundle_items (
+    bundle_id UUID NOT NULL REFERENCES product_bundles(product_id) ON DELETE CASCADE,
+    component_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    position INT NOT NULL,
+
+    PRIMARY KEY (bundle_id, component_id),
+    CHECK (bundle_id <> component_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_bundle_items_component ON product_bundle_items(component_id);
+
+-- a discounted bundle's price is recomputed on every write to it, so
+-- whatever sets its price, the components' total wins
+CREATE OR REPLACE FUNCTION price_discounted_bundle() RETURNS TRIGGER AS $$
+DECLARE
+    discount INT;
+    currencies INT;
+    component_currency TEXT;
+    total BIGINT;
+BEGIN
+    SELECT discount_percent INTO discount FROM product_bundles WHERE product_id = NEW.id;
+    IF discount IS NULL THEN
+        RETURN NEW;
+    END IF;
+    SELECT count(DISTINCT c.currency), min(c.currency), sum(c.price * i.quantity)
+    INTO currencies, component_currency, total
+    FROM product_bundle_items i
+    JOIN products c ON c.id = i.component_id
+    WHERE i.bundle_id = NEW.id;
+    -- components repriced into different currencies have no total; the
+    -- bundle keeps its last price until they agree again
+    IF currencies = 1 THEN
+        NEW.currency := component_currency;
+        NEW.price := GREATEST(1, round(total * (100 - discount) / 100.0));
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER price_discounted_bundle
+    BEFORE UPDATE OF price, currency ON products
+    FOR EACH ROW
+    EXECUTE PROCEDURE price_discounted_bundle();
+
+CREATE OR REPLACE FUNCTION reprice_bundles() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.price = OLD.price AND NEW.currency = OLD.currency THEN
+        RETURN NULL;
+    END IF;
+    UPDATE products SET price = price
+    WHERE id IN (
+        SELECT i.bundle_id FROM product_bundle_items i
+        JOIN product_bundles b ON b.product_id = i.bundle_id
+        WHERE i.component_id = NEW.id AND b.discount_percent IS NOT NULL
+    );
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER reprice_bundles
+    AFTER UPDATE OF price, currency ON products
+    FOR EACH ROW
+    EXECUTE PROCEDURE reprice_bundles();
+
+-- a component can't go to the trash while a live bundle contains it
+CREATE OR REPLACE FUNCTION guard_bundle_component() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.deleted_at IS NOT NULL AND OLD.deleted_at IS NULL AND EXISTS (
+        SELECT 1 FROM product_bundle_items i
+        JOIN products b ON b.id = i.bundle_id
+        WHERE i.component_id = NEW.id AND b.deleted_at IS NULL
+    ) THEN
+        RAISE EXCEPTION 'product % is a component of a bundle', NEW.id
+            USING ERRCODE = 'restrict_violation', CONSTRAINT = 'product_bundle_items_component_live';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER guard_bundle_component
+    BEFORE UPDATE OF deleted_at ON products
+    FOR EACH ROW
+    EXECUTE PROCEDURE guard_bundle_component();
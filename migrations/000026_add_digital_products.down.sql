@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_stock_reservations_committed_user;
+DROP TABLE IF EXISTS download_links;
+DROP TABLE IF EXISTS product_files;
+
+UPDATE product_revisions SET after = after - 'type', before = before - 'type';
+
+ALTER TABLE products DROP COLUMN IF EXISTS type;
@@ -0,0 +1,42 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS type TEXT NOT NULL DEFAULT 'physical'
+    CHECK (type IN ('physical', 'digital'));
+
+-- older revisions predate the column, back when every product was physical
+UPDATE product_revisions SET after = after || '{"type": "physical"}' WHERE NOT after ? 'type';
+UPDATE product_revisions SET before = before || '{"type": "physical"}' WHERE before IS NOT NULL AND NOT before ? 'type';
+
+-- the files a digital product delivers; their blobs are kept in private
+-- storage and only ever handed out through download links
+CREATE TABLE IF NOT EXISTS product_files (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    blob_key TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL CHECK (size_bytes > 0),
+
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_files_product_id ON product_files(product_id, created_at);
+
+-- a link to a file issued to a buyer. The token handed out carries the
+-- link's id and expiry under a signature; the download count lives here.
+CREATE TABLE IF NOT EXISTS download_links (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    file_id UUID NOT NULL REFERENCES product_files(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    max_downloads INT NOT NULL CHECK (max_downloads > 0),
+    downloads INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT download_links_within_limit CHECK (downloads >= 0 AND downloads <= max_downloads)
+);
+
+CREATE INDEX IF NOT EXISTS idx_download_links_expires_at ON download_links(expires_at);
+
+-- buyers are checked for a committed reservation of the product
+CREATE INDEX IF NOT EXISTS idx_stock_reservations_committed_user
+    ON stock_reservations(user_id, stock_id) WHERE status = 'committed';
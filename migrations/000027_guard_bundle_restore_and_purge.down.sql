@@ -0,0 +1,17 @@
+CREATE OR REPLACE FUNCTION guard_bundle_component() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.deleted_at IS NOT NULL AND OLD.deleted_at IS NULL AND EXISTS (
+        SELECT 1 FROM product_bundle_items i
+        JOIN products b ON b.id = i.bundle_id
+        WHERE i.component_id = NEW.id AND b.deleted_at IS NULL
+    ) THEN
+        RAISE EXCEPTION 'product % is a component of a bundle', NEW.id
+            USING ERRCODE = 'restrict_violation', CONSTRAINT = 'product_bundle_items_component_live';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE product_bundle_items DROP CONSTRAINT IF EXISTS product_bundle_items_component_id_fkey;
+ALTER TABLE product_bundle_items ADD CONSTRAINT product_bundle_items_component_id_fkey
+    FOREIGN KEY (component_id) REFERENCES products(id) ON DELETE CASCADE;
@@ -0,0 +1,29 @@
+-- purging a component must not silently drop it from a bundle; the purge
+-- leaves components alone until no bundle refers to them
+ALTER TABLE product_bundle_items DROP CONSTRAINT IF EXISTS product_bundle_items_component_id_fkey;
+ALTER TABLE product_bundle_items ADD CONSTRAINT product_bundle_items_component_id_fkey
+    FOREIGN KEY (component_id) REFERENCES products(id);
+
+-- a component can't go to the trash while a live bundle contains it, and
+-- a bundle can't come back from the trash while a component is still there
+CREATE OR REPLACE FUNCTION guard_bundle_component() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.deleted_at IS NOT NULL AND OLD.deleted_at IS NULL AND EXISTS (
+        SELECT 1 FROM product_bundle_items i
+        JOIN products b ON b.id = i.bundle_id
+        WHERE i.component_id = NEW.id AND b.deleted_at IS NULL
+    ) THEN
+        RAISE EXCEPTION 'product % is a component of a bundle', NEW.id
+            USING ERRCODE = 'restrict_violation', CONSTRAINT = 'product_bundle_items_component_live';
+    END IF;
+    IF NEW.deleted_at IS NULL AND OLD.deleted_at IS NOT NULL AND EXISTS (
+        SELECT 1 FROM product_bundle_items i
+        JOIN products c ON c.id = i.component_id
+        WHERE i.bundle_id = NEW.id AND c.deleted_at IS NOT NULL
+    ) THEN
+        RAISE EXCEPTION 'bundle % has components in the trash', NEW.id
+            USING ERRCODE = 'restrict_violation', CONSTRAINT = 'product_bundle_items_bundle_live';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
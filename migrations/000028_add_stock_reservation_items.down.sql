@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_stock_reservation_items_stock_id;
+DROP TABLE IF EXISTS stock_reservation_items;
@@ -0,0 +1,16 @@
+-- the units a reservation holds, per stock row: the product's own row, or
+-- for a bundle, each component's. The reservation's own stock_id still
+-- names what the buyer reserved.
+CREATE TABLE IF NOT EXISTS stock_reservation_items (
+    reservation_id UUID NOT NULL REFERENCES stock_reservations(id) ON DELETE CASCADE,
+    stock_id UUID NOT NULL REFERENCES stock_levels(id) ON DELETE CASCADE,
+    quantity INT NOT NULL CHECK (quantity > 0),
+
+    PRIMARY KEY (reservation_id, stock_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_reservation_items_stock_id ON stock_reservation_items(stock_id);
+
+INSERT INTO stock_reservation_items (reservation_id, stock_id, quantity)
+SELECT id, stock_id, quantity FROM stock_reservations
+ON CONFLICT DO NOTHING;